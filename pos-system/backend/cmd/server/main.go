@@ -12,10 +12,18 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/pos-system/backend/internal/handlers"
+	"github.com/pos-system/backend/internal/middleware"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/internal/services"
+	"github.com/pos-system/backend/pkg/auth"
 	"github.com/pos-system/backend/pkg/config"
 	"github.com/pos-system/backend/pkg/database"
 )
 
+// refreshTokenTTLDays is how long a refresh token, and so a session, lasts
+const refreshTokenTTLDays = 30
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
@@ -24,14 +32,45 @@ func main() {
 
 	// Initialize configuration
 	cfg := config.New()
+	if err := cfg.ValidateRateLimits(); err != nil {
+		log.Fatal("Invalid rate limit configuration:", err)
+	}
+	// Export download links need no login, so their key must be private
+	if cfg.IsProduction() && cfg.ExportSigningKey == "" {
+		log.Fatal("Set EXPORT_SIGNING_KEY in production")
+	}
 
 	// Initialize database connection
-	_, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer database.Disconnect()
 
+	// Wire repositories, services, middleware and handlers
+	jwtManager, err := auth.NewJWTManagerFromConfig(auth.KeyConfig{
+		Secret:               cfg.JWTSecret,
+		DefaultSecret:        cfg.JWTSecret == config.DefaultJWTSecret,
+		Production:           cfg.IsProduction(),
+		SigningKeyFile:       cfg.JWTSigningKeyFile,
+		VerificationKeyFiles: cfg.JWTVerificationKeyFiles,
+		AcceptHS256Until:     cfg.JWTAcceptHS256Until,
+		AccessTTLHours:       cfg.JWTExpirationHours,
+		RefreshTTLDays:       refreshTokenTTLDays,
+	}, time.Now())
+	if err != nil {
+		log.Fatal("Failed to load token signing keys:", err)
+	}
+	repos := repository.NewRepositories(db)
+	svc := services.NewServices(repos, jwtManager, cfg)
+	mw := middleware.NewMiddleware(svc, cfg)
+	h := handlers.NewHandlers(svc)
+
+	// Start background maintenance jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	svc.StartBackgroundJobs(jobsCtx)
+
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -39,9 +78,16 @@ func main() {
 
 	router := gin.New()
 
+	// Only believe X-Forwarded-For from known proxies, so clients cannot
+	// pick their own IP to dodge rate limits and login throttling
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// Middleware setup
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.ClientInfo())
 
 	// CORS configuration
 	corsConfig := cors.DefaultConfig()
@@ -68,31 +114,11 @@ func main() {
 		})
 	})
 
-	// Basic API structure (handlers will be added in next tasks)
-	api := router.Group("/api")
-	{
-		// Authentication routes (placeholder)
-		auth := api.Group("/auth")
-		{
-			auth.GET("/status", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Authentication endpoints will be implemented in Task 4",
-					"status":  "pending",
-				})
-			})
-		}
+	// Public keys for services that verify our tokens
+	router.GET("/.well-known/jwks.json", h.Auth.JWKS)
 
-		// Protected routes placeholder
-		protected := api.Group("/")
-		{
-			protected.GET("/protected", func(c *gin.Context) {
-				c.JSON(http.StatusOK, gin.H{
-					"message": "Protected endpoints will be implemented after authentication",
-					"status":  "pending",
-				})
-			})
-		}
-	}
+	api := router.Group("/api")
+	h.RegisterRoutes(api, mw)
 
 	// Setup HTTP server
 	srv := &http.Server{
@@ -120,6 +146,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("🛑 Shutting down server...")
+	stopJobs()
 
 	// The context is used to inform the server it has 30 seconds to finish
 	// the request it is currently handling
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// AnalyticsHandler exposes dashboard and reporting endpoints
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+	reportService    *services.ReportService
+}
+
+// defaultReportDays is the period a sales report covers when no start date
+// is given
+const defaultReportDays = 30
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService, reportService *services.ReportService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		reportService:    reportService,
+	}
+}
+
+// GetDashboard handles GET /analytics/dashboard
+func (h *AnalyticsHandler) GetDashboard(c *gin.Context) {
+	dashboard, err := h.analyticsService.GetDashboard(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, dashboard))
+}
+
+// GetSalesReport handles GET /analytics/sales-report. The range defaults to
+// the 30 days up to now and groupBy to day.
+func (h *AnalyticsHandler) GetSalesReport(c *gin.Context) {
+	startDate, ok := parseDateQuery(c, "startDate", false)
+	if !ok {
+		return
+	}
+	endDate, ok := parseDateQuery(c, "endDate", true)
+	if !ok {
+		return
+	}
+
+	end := time.Now()
+	if endDate != nil {
+		end = *endDate
+	}
+	start := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location()).AddDate(0, 0, -(defaultReportDays - 1))
+	if startDate != nil {
+		start = *startDate
+	}
+
+	groupBy := models.ReportGroupBy(c.DefaultQuery("groupBy", string(models.ReportGroupByDay)))
+	report, err := h.reportService.GetSalesReport(c.Request.Context(), start, end, groupBy)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, report))
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+// AuthHandler exposes authentication endpoints
+type AuthHandler struct {
+	authService *services.AuthService
+	userService *services.UserService
+}
+
+// NewAuthHandler creates a new authentication handler
+func NewAuthHandler(authService *services.AuthService, userService *services.UserService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		userService: userService,
+	}
+}
+
+// Register handles POST /auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.authService.Register(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse("Registration successful", resp))
+}
+
+// Login handles POST /auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.authService.Login(c.Request.Context(), &req)
+	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Login successful", resp))
+}
+
+// VerifyTwoFactor handles POST /auth/two-factor/verify
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req models.VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.authService.VerifyTwoFactor(c.Request.Context(), &req)
+	if err != nil {
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Login successful", resp))
+}
+
+// SetupTwoFactor handles POST /auth/two-factor/setup
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	var req models.SetupTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	enrollment, err := h.authService.SetupTwoFactor(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Add the secret to your authenticator app", enrollment))
+}
+
+// RefreshToken handles POST /auth/refresh
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resp, err := h.authService.RefreshToken(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Token refreshed", resp))
+}
+
+// Logout handles POST /auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	accessToken := auth.ExtractTokenFromBearerString(c.GetHeader("Authorization"))
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken, accessToken); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Logged out", nil))
+}
+
+// ResetPassword handles POST /auth/password/reset
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Same response whether or not the email exists
+	c.JSON(http.StatusOK, models.SuccessResponse("If the email is registered, reset instructions have been sent", nil))
+}
+
+// ConfirmResetPassword handles POST /auth/password/reset/confirm
+func (h *AuthHandler) ConfirmResetPassword(c *gin.Context) {
+	var req models.ConfirmResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.ConfirmResetPassword(c.Request.Context(), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Password has been reset", nil))
+}
+
+// JWKS handles GET /.well-known/jwks.json. The key set is served as a bare
+// JSON Web Key Set so standard JWT libraries can consume it.
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// VerifyEmail handles POST /auth/email/verify
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Email address verified", nil))
+}
+
+// ResendVerification handles POST /auth/email/verify/resend
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.ResendVerification(c.Request.Context(), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Same response whether or not the email exists or is already verified
+	c.JSON(http.StatusOK, models.SuccessResponse("If the email is awaiting verification, a new link has been sent", nil))
+}
+
+// ChangePassword handles POST /auth/password/change
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.authService.ChangePassword(c.Request.Context(), userID, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Password changed", nil))
+}
+
+// Me handles GET /auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserProfile(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, user))
+}
+
+// UpdateMe handles PUT /auth/me
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdateUserProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, user))
+}
+
+// MySessions handles GET /auth/me/sessions
+func (h *AuthHandler) MySessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.userService.GetUserSessions(c.Request.Context(), userID, userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, sessions))
+}
+
+// RevokeMySessions handles DELETE /auth/me/sessions
+func (h *AuthHandler) RevokeMySessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RevokeAllUserSessions(c.Request.Context(), userID, userID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("All sessions revoked", nil))
+}
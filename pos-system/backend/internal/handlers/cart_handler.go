@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// CartHandler exposes the cashier's draft cart endpoints
+type CartHandler struct {
+	cartService *services.CartService
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(cartService *services.CartService) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+	}
+}
+
+// ListCarts handles GET /carts
+func (h *CartHandler) ListCarts(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var status *models.CartStatus
+	if value := c.Query("status"); value != "" {
+		s := models.CartStatus(value)
+		if s != models.CartStatusActive && s != models.CartStatusHeld {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return
+		}
+		status = &s
+	}
+
+	carts, err := h.cartService.ListCarts(c.Request.Context(), cashierID, status)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, carts))
+}
+
+// CreateCart handles POST /carts
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		respondValidationError(c, err)
+		return
+	}
+
+	cart, err := h.cartService.CreateCart(c.Request.Context(), cashierID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, cart))
+}
+
+// GetCart handles GET /carts/:id
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.GetCart(c.Request.Context(), cashierID, cartID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, cart))
+}
+
+// UpdateCart handles PUT /carts/:id
+func (h *CartHandler) UpdateCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	cart, err := h.cartService.RenameCart(c.Request.Context(), cashierID, cartID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, cart))
+}
+
+// DeleteCart handles DELETE /carts/:id
+func (h *CartHandler) DeleteCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.DeleteCart(c.Request.Context(), cashierID, cartID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageDeletedSuccessfully, nil))
+}
+
+// AddItem handles POST /carts/:id/items
+func (h *CartHandler) AddItem(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	cart, err := h.cartService.AddItem(c.Request.Context(), cashierID, cartID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, cart))
+}
+
+// UpdateItem handles PUT /carts/:id/items/:productId
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	productID, ok := parseUUIDParam(c, "productId")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	cart, err := h.cartService.UpdateItem(c.Request.Context(), cashierID, cartID, productID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, cart))
+}
+
+// RemoveItem handles DELETE /carts/:id/items/:productId
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	productID, ok := parseUUIDParam(c, "productId")
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.RemoveItem(c.Request.Context(), cashierID, cartID, productID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, cart))
+}
+
+// HoldCart handles POST /carts/:id/hold
+func (h *CartHandler) HoldCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.HoldCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		respondValidationError(c, err)
+		return
+	}
+
+	cart, err := h.cartService.HoldCart(c.Request.Context(), cashierID, cartID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Cart held", cart))
+}
+
+// ResumeCart handles POST /carts/:id/resume
+func (h *CartHandler) ResumeCart(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.ResumeCart(c.Request.Context(), cashierID, cartID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Cart resumed", cart))
+}
+
+// Checkout handles POST /carts/:id/checkout
+func (h *CartHandler) Checkout(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	cartID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CheckoutCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	transaction, err := h.cartService.Checkout(c.Request.Context(), cashierID, cartID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, transaction))
+}
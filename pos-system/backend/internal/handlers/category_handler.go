@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// CategoryHandler exposes product category endpoints
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// ListCategories handles GET /categories
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	categories, total, err := h.categoryService.ListCategories(c.Request.Context(), pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, categories, pagination, total)
+}
+
+// GetTree handles GET /categories/tree
+func (h *CategoryHandler) GetTree(c *gin.Context) {
+	tree, err := h.categoryService.GetTree(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, tree))
+}
+
+// GetCategory handles GET /categories/:id
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.GetCategory(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, category))
+}
+
+// GetCategoryProducts handles GET /categories/:id/products
+func (h *CategoryHandler) GetCategoryProducts(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryWithProducts(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, category))
+}
+
+// CreateCategory handles POST /categories
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, category))
+}
+
+// UpdateCategory handles PUT /categories/:id
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, category))
+}
+
+// DeleteCategory handles DELETE /categories/:id
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageDeletedSuccessfully, nil))
+}
+
+// BulkCategories handles POST /categories/bulk
+func (h *CategoryHandler) BulkCategories(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkOperation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.categoryService.BulkCategories(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondBulkResult(c, result)
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// ExpenseHandler exposes expense endpoints
+type ExpenseHandler struct {
+	expenseService *services.ExpenseService
+}
+
+// NewExpenseHandler creates a new expense handler
+func NewExpenseHandler(expenseService *services.ExpenseService) *ExpenseHandler {
+	return &ExpenseHandler{
+		expenseService: expenseService,
+	}
+}
+
+// ListExpenses handles GET /expenses. Cashiers only see their own expenses.
+func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if status := c.Query("status"); status != "" {
+		switch models.ExpenseStatus(status) {
+		case models.ExpenseStatusPending, models.ExpenseStatusApproved, models.ExpenseStatusRejected:
+			filters["status"] = status
+		default:
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return
+		}
+	}
+	if category := c.Query("category"); category != "" {
+		if !models.ValidateExpenseCategory(category) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid category", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["category"] = category
+	}
+
+	startDate, ok := parseDateQuery(c, "startDate", false)
+	if !ok {
+		return
+	}
+	endDate, ok := parseDateQuery(c, "endDate", true)
+	if !ok {
+		return
+	}
+	if startDate != nil {
+		filters["start_date"] = *startDate
+	}
+	if endDate != nil {
+		filters["end_date"] = *endDate
+	}
+
+	if !isManager(c) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		filters["created_by"] = userID
+	}
+
+	expenses, total, err := h.expenseService.ListExpenses(c.Request.Context(), filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, expenses, pagination, total)
+}
+
+// GetExpense handles GET /expenses/:id
+func (h *ExpenseHandler) GetExpense(c *gin.Context) {
+	expense, ok := h.visibleExpense(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, expense))
+}
+
+// CreateExpense handles POST /expenses
+func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	expense, err := h.expenseService.CreateExpense(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, expense))
+}
+
+// UpdateExpense handles PUT /expenses/:id
+func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	expense, err := h.expenseService.UpdateExpense(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, expense))
+}
+
+// DeleteExpense handles DELETE /expenses/:id
+func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.expenseService.DeleteExpense(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageDeletedSuccessfully, nil))
+}
+
+// ApproveExpense handles POST /expenses/:id/approve
+func (h *ExpenseHandler) ApproveExpense(c *gin.Context) {
+	managerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	expense, err := h.expenseService.ApproveExpense(c.Request.Context(), managerID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Expense approved", expense))
+}
+
+// RejectExpense handles POST /expenses/:id/reject
+func (h *ExpenseHandler) RejectExpense(c *gin.Context) {
+	managerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RejectExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	expense, err := h.expenseService.RejectExpense(c.Request.Context(), managerID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Expense rejected", expense))
+}
+
+// UploadReceipt handles PUT /expenses/:id/receipt with the file in the
+// "receipt" multipart field
+func (h *ExpenseHandler) UploadReceipt(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("receipt")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("A receipt file is required", models.ErrorCodeValidation, nil))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	expense, err := h.expenseService.UploadReceipt(c.Request.Context(), userID, id, header.Size, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Receipt uploaded", expense))
+}
+
+// GetReceipt handles GET /expenses/:id/receipt, streaming the stored file
+func (h *ExpenseHandler) GetReceipt(c *gin.Context) {
+	expense, ok := h.visibleExpense(c)
+	if !ok {
+		return
+	}
+
+	file, contentType, err := h.expenseService.OpenReceipt(c.Request.Context(), expense.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, file)
+}
+
+// visibleExpense loads the expense named by the :id parameter, hiding other
+// users' expenses from cashiers. It writes the error response on failure.
+func (h *ExpenseHandler) visibleExpense(c *gin.Context) (*models.Expense, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	expense, err := h.expenseService.GetExpense(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return nil, false
+	}
+	if expense.CreatedBy != userID && !isManager(c) {
+		respondError(c, services.ErrExpenseNotFound)
+		return nil, false
+	}
+	return expense, true
+}
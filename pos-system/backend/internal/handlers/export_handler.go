@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// ExportHandler exposes data export endpoints
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// CreateExport handles POST /exports/:resource for transactions, products,
+// stock-movements, expenses and audit-logs. The export is generated in the
+// background; poll GET /exports/jobs/:id for its download link.
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	job, err := h.exportService.CreateExport(c.Request.Context(), userID, c.Param("resource"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/exports/jobs/"+job.ID.String())
+	c.JSON(http.StatusAccepted, models.SuccessResponse("Export started", job))
+}
+
+// GetExportJob handles GET /exports/jobs/:id
+func (h *ExportHandler) GetExportJob(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	jobID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := h.exportService.GetExportJob(c.Request.Context(), userID, jobID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, job))
+}
+
+// DownloadExport handles GET /exports/download/:file. The link's signature
+// stands in for authentication so it can be opened directly in a browser.
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	fileName := c.Param("file")
+	file, format, err := h.exportService.OpenExport(c.Request.Context(), fileName, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, file)
+}
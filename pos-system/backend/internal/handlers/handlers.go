@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/middleware"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// Handlers holds all HTTP handler instances
+type Handlers struct {
+	Auth           *AuthHandler
+	User           *UserHandler
+	Transaction    *TransactionHandler
+	Cart           *CartHandler
+	Product        *ProductHandler
+	Category       *CategoryHandler
+	Inventory      *InventoryHandler
+	Recommendation *RecommendationHandler
+	Expense        *ExpenseHandler
+	Analytics      *AnalyticsHandler
+	Export         *ExportHandler
+	TwoFactor      *TwoFactorHandler
+}
+
+// NewHandlers creates all handler instances
+func NewHandlers(services *services.Services) *Handlers {
+	return &Handlers{
+		Auth:           NewAuthHandler(services.Auth, services.User),
+		User:           NewUserHandler(services.User),
+		Transaction:    NewTransactionHandler(services.Transaction, services.Receipt),
+		Cart:           NewCartHandler(services.Cart),
+		Product:        NewProductHandler(services.Product),
+		Category:       NewCategoryHandler(services.Category),
+		Inventory:      NewInventoryHandler(services.Inventory),
+		Recommendation: NewRecommendationHandler(services.Recommendation),
+		Expense:        NewExpenseHandler(services.Expense),
+		Analytics:      NewAnalyticsHandler(services.Analytics, services.Report),
+		Export:         NewExportHandler(services.Export),
+		TwoFactor:      NewTwoFactorHandler(services.TwoFactor),
+	}
+}
+
+// RegisterRoutes mounts every API route on the given group
+func (h *Handlers) RegisterRoutes(api *gin.RouterGroup, mw *middleware.Middleware) {
+	auth := api.Group("/auth")
+	{
+		auth.POST("/refresh", mw.RateLimit.API(), h.Auth.RefreshToken)
+		auth.POST("/logout", mw.RateLimit.API(), h.Auth.Logout)
+
+		// Endpoints open to credential guessing and email flooding
+		strict := auth.Group("", mw.RateLimit.Auth())
+		{
+			strict.POST("/register", h.Auth.Register)
+			strict.POST("/login", h.Auth.Login)
+			strict.POST("/password/reset", h.Auth.ResetPassword)
+			strict.POST("/password/reset/confirm", h.Auth.ConfirmResetPassword)
+			strict.POST("/email/verify", h.Auth.VerifyEmail)
+			strict.POST("/email/verify/resend", h.Auth.ResendVerification)
+			strict.POST("/two-factor/verify", h.Auth.VerifyTwoFactor)
+			strict.POST("/two-factor/setup", h.Auth.SetupTwoFactor)
+		}
+
+		authenticated := auth.Group("", mw.Auth.RequireAuth(), mw.RateLimit.API())
+		{
+			authenticated.POST("/password/change", h.Auth.ChangePassword)
+			authenticated.GET("/me", h.Auth.Me)
+			authenticated.PUT("/me", h.Auth.UpdateMe)
+			authenticated.GET("/me/sessions", h.Auth.MySessions)
+			authenticated.DELETE("/me/sessions", h.Auth.RevokeMySessions)
+			authenticated.GET("/two-factor", h.TwoFactor.GetStatus)
+			authenticated.POST("/two-factor/enroll", h.TwoFactor.Enroll)
+
+			// Code checks get the stricter limit against guessing
+			authenticated.POST("/two-factor/enroll/confirm", mw.RateLimit.Auth(), h.TwoFactor.ConfirmEnrollment)
+			authenticated.POST("/two-factor/disable", mw.RateLimit.Auth(), h.TwoFactor.Disable)
+			authenticated.POST("/two-factor/recovery-codes", mw.RateLimit.Auth(), h.TwoFactor.RegenerateRecoveryCodes)
+		}
+	}
+
+	users := api.Group("/users", mw.Auth.RequireAuth(), mw.RateLimit.API(), mw.Auth.RequireAdmin())
+	{
+		users.GET("", h.User.ListUsers)
+		users.POST("", h.User.CreateUser)
+		users.POST("/bulk", h.User.BulkUsers)
+		users.GET("/statistics", h.User.GetUserStatistics)
+		users.GET("/two-factor/policies", h.TwoFactor.GetPolicies)
+		users.PUT("/two-factor/policies", h.TwoFactor.UpdatePolicy)
+		users.DELETE("/sessions/:sessionId", h.User.RevokeUserSession)
+		users.GET("/:id", h.User.GetUser)
+		users.PUT("/:id", h.User.UpdateUser)
+		users.DELETE("/:id", h.User.DeleteUser)
+		users.PUT("/:id/role", h.User.UpdateUserRole)
+		users.POST("/:id/activate", h.User.ActivateUser)
+		users.POST("/:id/deactivate", h.User.DeactivateUser)
+		users.POST("/:id/unlock", h.User.UnlockUser)
+		users.DELETE("/:id/two-factor", h.TwoFactor.ResetUser)
+		users.GET("/:id/sessions", h.User.GetUserSessions)
+		users.DELETE("/:id/sessions", h.User.RevokeAllUserSessions)
+		users.GET("/:id/accounts", h.User.GetUserAccounts)
+	}
+
+	transactions := api.Group("/transactions", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		transactions.POST("", h.Transaction.Checkout)
+		transactions.GET("", h.Transaction.ListTransactions)
+		transactions.GET("/receipt/:receiptId", h.Transaction.GetTransactionByReceipt)
+		transactions.GET("/:id", h.Transaction.GetTransaction)
+		transactions.GET("/:id/receipt", h.Transaction.GetReceipt)
+		transactions.POST("/:id/receipt/email", h.Transaction.EmailReceipt)
+		transactions.GET("/:id/receipt/emails", h.Transaction.ListReceiptEmails)
+		transactions.POST("/:id/refund", mw.Auth.RequireManager(), h.Transaction.RefundTransaction)
+	}
+
+	carts := api.Group("/carts", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		carts.GET("", h.Cart.ListCarts)
+		carts.POST("", h.Cart.CreateCart)
+		carts.GET("/:id", h.Cart.GetCart)
+		carts.PUT("/:id", h.Cart.UpdateCart)
+		carts.DELETE("/:id", h.Cart.DeleteCart)
+		carts.POST("/:id/items", h.Cart.AddItem)
+		carts.PUT("/:id/items/:productId", h.Cart.UpdateItem)
+		carts.DELETE("/:id/items/:productId", h.Cart.RemoveItem)
+		carts.POST("/:id/hold", h.Cart.HoldCart)
+		carts.POST("/:id/resume", h.Cart.ResumeCart)
+		carts.POST("/:id/checkout", h.Cart.Checkout)
+	}
+
+	products := api.Group("/products", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		products.GET("", h.Product.ListProducts)
+		products.GET("/low-stock", h.Product.GetLowStockProducts)
+		products.GET("/out-of-stock", h.Product.GetOutOfStockProducts)
+		products.GET("/sku/:sku", h.Product.GetProductBySKU)
+		products.GET("/barcode/:barcode", h.Product.GetProductByBarcode)
+		products.GET("/:id", h.Product.GetProduct)
+		products.POST("", mw.Auth.RequireManager(), h.Product.CreateProduct)
+		products.POST("/import", mw.Auth.RequireManager(), h.Product.ImportProducts)
+		products.POST("/bulk", mw.Auth.RequireManager(), h.Product.BulkProducts)
+		products.PUT("/:id", mw.Auth.RequireManager(), h.Product.UpdateProduct)
+		products.DELETE("/:id", mw.Auth.RequireManager(), h.Product.DeleteProduct)
+	}
+
+	categories := api.Group("/categories", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		categories.GET("", h.Category.ListCategories)
+		categories.GET("/tree", h.Category.GetTree)
+		categories.GET("/:id", h.Category.GetCategory)
+		categories.GET("/:id/products", h.Category.GetCategoryProducts)
+		categories.POST("", mw.Auth.RequireManager(), h.Category.CreateCategory)
+		categories.POST("/bulk", mw.Auth.RequireManager(), h.Category.BulkCategories)
+		categories.PUT("/:id", mw.Auth.RequireManager(), h.Category.UpdateCategory)
+		categories.DELETE("/:id", mw.Auth.RequireManager(), h.Category.DeleteCategory)
+	}
+
+	adjustments := api.Group("/inventory/adjustments", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		adjustments.GET("", h.Inventory.ListAdjustments)
+		adjustments.POST("", h.Inventory.SubmitAdjustment)
+		adjustments.GET("/:id", h.Inventory.GetAdjustment)
+		adjustments.POST("/:id/approve", mw.Auth.RequireManager(), h.Inventory.ApproveAdjustment)
+		adjustments.POST("/:id/reject", mw.Auth.RequireManager(), h.Inventory.RejectAdjustment)
+	}
+
+	recommendations := api.Group("/recommendations", mw.Auth.RequireAuth(), mw.RateLimit.API(), mw.Auth.RequireManager())
+	{
+		recommendations.GET("", h.Recommendation.ListRecommendations)
+		recommendations.GET("/pending", h.Recommendation.GetPendingRecommendations)
+		recommendations.POST("/generate", h.Recommendation.GenerateRecommendations)
+		recommendations.GET("/:id", h.Recommendation.GetRecommendation)
+		recommendations.POST("/:id/action", h.Recommendation.TakeAction)
+	}
+
+	expenses := api.Group("/expenses", mw.Auth.RequireAuth(), mw.RateLimit.API())
+	{
+		expenses.GET("", h.Expense.ListExpenses)
+		expenses.POST("", h.Expense.CreateExpense)
+		expenses.GET("/:id", h.Expense.GetExpense)
+		expenses.PUT("/:id", h.Expense.UpdateExpense)
+		expenses.DELETE("/:id", h.Expense.DeleteExpense)
+		expenses.GET("/:id/receipt", h.Expense.GetReceipt)
+		expenses.PUT("/:id/receipt", h.Expense.UploadReceipt)
+		expenses.POST("/:id/approve", mw.Auth.RequireManager(), h.Expense.ApproveExpense)
+		expenses.POST("/:id/reject", mw.Auth.RequireManager(), h.Expense.RejectExpense)
+	}
+
+	analytics := api.Group("/analytics", mw.Auth.RequireAuth(), mw.RateLimit.API(), mw.Auth.RequireManager())
+	{
+		analytics.GET("/dashboard", h.Analytics.GetDashboard)
+		analytics.GET("/sales-report", h.Analytics.GetSalesReport)
+	}
+
+	exports := api.Group("/exports", mw.RateLimit.API())
+	{
+		exports.GET("/download/:file", h.Export.DownloadExport)
+		exports.POST("/:resource", mw.Auth.RequireAuth(), mw.Auth.RequireManager(), h.Export.CreateExport)
+		exports.GET("/jobs/:id", mw.Auth.RequireAuth(), mw.Auth.RequireManager(), h.Export.GetExportJob)
+	}
+}
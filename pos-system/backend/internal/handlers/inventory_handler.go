@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// InventoryHandler exposes stock adjustment endpoints
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// ListAdjustments handles GET /inventory/adjustments. Cashiers only see
+// adjustments they submitted.
+func (h *InventoryHandler) ListAdjustments(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if status := c.Query("status"); status != "" {
+		switch models.StockAdjustmentStatus(status) {
+		case models.StockAdjustmentPending, models.StockAdjustmentApplied, models.StockAdjustmentRejected:
+			filters["status"] = status
+		default:
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return
+		}
+	}
+	if code := c.Query("reason_code"); code != "" {
+		if !models.StockAdjustmentReason(code).IsValid() {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid reason_code", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["reason_code"] = code
+	}
+	for _, name := range []string{"product_id", "requested_by"} {
+		if value := c.Query(name); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid "+name, models.ErrorCodeValidation, nil))
+				return
+			}
+			filters[name] = id
+		}
+	}
+
+	if !isManager(c) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		filters["requested_by"] = userID
+	}
+
+	adjustments, total, err := h.inventoryService.ListAdjustments(c.Request.Context(), filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, adjustments, pagination, total)
+}
+
+// GetAdjustment handles GET /inventory/adjustments/:id
+func (h *InventoryHandler) GetAdjustment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	adjustment, err := h.inventoryService.GetAdjustment(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if adjustment.RequestedBy != userID && !isManager(c) {
+		respondError(c, services.ErrAdjustmentNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, adjustment))
+}
+
+// SubmitAdjustment handles POST /inventory/adjustments. The response is 201
+// when the adjustment was applied and 202 when it awaits approval.
+func (h *InventoryHandler) SubmitAdjustment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	adjustment, err := h.inventoryService.SubmitAdjustment(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if adjustment.Status == models.StockAdjustmentPending {
+		c.JSON(http.StatusAccepted, models.SuccessResponse("Adjustment submitted for approval", adjustment))
+		return
+	}
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, adjustment))
+}
+
+// ApproveAdjustment handles POST /inventory/adjustments/:id/approve
+func (h *InventoryHandler) ApproveAdjustment(c *gin.Context) {
+	h.review(c, h.inventoryService.ApproveAdjustment, "Adjustment approved")
+}
+
+// RejectAdjustment handles POST /inventory/adjustments/:id/reject
+func (h *InventoryHandler) RejectAdjustment(c *gin.Context) {
+	h.review(c, h.inventoryService.RejectAdjustment, "Adjustment rejected")
+}
+
+// review runs an approve or reject call for the current manager; the notes
+// body is optional
+func (h *InventoryHandler) review(c *gin.Context, fn func(context.Context, uuid.UUID, uuid.UUID, *models.ReviewStockAdjustmentRequest) (*models.StockAdjustment, error), message string) {
+	managerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReviewStockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		respondValidationError(c, err)
+		return
+	}
+
+	adjustment, err := fn(c.Request.Context(), managerID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(message, adjustment))
+}
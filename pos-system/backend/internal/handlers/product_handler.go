@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// defaultLowStockThreshold is used when GET /products/low-stock has no threshold
+const defaultLowStockThreshold = 10
+
+// ProductHandler exposes product catalog endpoints
+type ProductHandler struct {
+	productService *services.ProductService
+}
+
+// NewProductHandler creates a new product handler
+func NewProductHandler(productService *services.ProductService) *ProductHandler {
+	return &ProductHandler{
+		productService: productService,
+	}
+}
+
+// ListProducts handles GET /products
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters, ok := bindProductFilters(c)
+	if !ok {
+		return
+	}
+
+	products, total, err := h.productService.ListProducts(c.Request.Context(), filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, products, pagination, total)
+}
+
+// GetProduct handles GET /products/:id
+func (h *ProductHandler) GetProduct(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, product))
+}
+
+// GetProductBySKU handles GET /products/sku/:sku
+func (h *ProductHandler) GetProductBySKU(c *gin.Context) {
+	product, err := h.productService.GetProductBySKU(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, product))
+}
+
+// GetProductByBarcode handles GET /products/barcode/:barcode
+func (h *ProductHandler) GetProductByBarcode(c *gin.Context) {
+	product, err := h.productService.GetProductByBarcode(c.Request.Context(), c.Param("barcode"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, product))
+}
+
+// GetLowStockProducts handles GET /products/low-stock
+func (h *ProductHandler) GetLowStockProducts(c *gin.Context) {
+	threshold := defaultLowStockThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid threshold", models.ErrorCodeValidation, nil))
+			return
+		}
+		threshold = parsed
+	}
+
+	products, err := h.productService.GetLowStockProducts(c.Request.Context(), threshold)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, products))
+}
+
+// GetOutOfStockProducts handles GET /products/out-of-stock
+func (h *ProductHandler) GetOutOfStockProducts(c *gin.Context) {
+	products, err := h.productService.GetOutOfStockProducts(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, products))
+}
+
+// CreateProduct handles POST /products
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	product, err := h.productService.CreateProduct(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, product))
+}
+
+// UpdateProduct handles PUT /products/:id
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	product, err := h.productService.UpdateProduct(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, product))
+}
+
+// DeleteProduct handles DELETE /products/:id
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.productService.DeleteProduct(c.Request.Context(), userID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageDeletedSuccessfully, nil))
+}
+
+// ImportProducts handles POST /products/import with a CSV or XLSX file in
+// the "file" multipart field. With ?dry_run=true nothing is saved and the
+// response previews the import.
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	dryRun, ok := parseBoolQuery(c, "dry_run")
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("An import file is required", models.ErrorCodeValidation, nil))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.productService.ImportProducts(c.Request.Context(), userID, header.Filename, header.Size, file, dryRun != nil && *dryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	message := "Products imported"
+	if result.DryRun {
+		message = "Import preview"
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse(message, result))
+}
+
+// BulkProducts handles POST /products/bulk
+func (h *ProductHandler) BulkProducts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkOperation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.productService.BulkProducts(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondBulkResult(c, result)
+}
+
+// bindProductFilters reads product list filters from the query string,
+// writing a 400 on failure
+func bindProductFilters(c *gin.Context) (*models.ProductFilters, bool) {
+	filters := &models.ProductFilters{
+		SearchTerm: c.Query("search_term"),
+		Supplier:   c.Query("supplier"),
+	}
+
+	if value := c.Query("category_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid category_id", models.ErrorCodeValidation, nil))
+			return nil, false
+		}
+		filters.CategoryID = &id
+	}
+	if value := c.Query("status"); value != "" {
+		status := models.ProductStatus(value)
+		switch status {
+		case models.ProductStatusActive, models.ProductStatusInactive, models.ProductStatusDiscontinued:
+			filters.Status = &status
+		default:
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return nil, false
+		}
+	}
+
+	var ok bool
+	if filters.IsActive, ok = parseBoolQuery(c, "is_active"); !ok {
+		return nil, false
+	}
+	if filters.LowStock, ok = parseBoolQuery(c, "low_stock"); !ok {
+		return nil, false
+	}
+	if filters.MinPrice, ok = parseFloatQuery(c, "min_price"); !ok {
+		return nil, false
+	}
+	if filters.MaxPrice, ok = parseFloatQuery(c, "max_price"); !ok {
+		return nil, false
+	}
+
+	return filters, true
+}
+
+// parseBoolQuery reads an optional boolean query parameter, writing a 400
+// on failure
+func parseBoolQuery(c *gin.Context, name string) (*bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid "+name, models.ErrorCodeValidation, nil))
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// parseFloatQuery reads an optional non-negative number query parameter,
+// writing a 400 on failure
+func parseFloatQuery(c *gin.Context, name string) (*float64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid "+name, models.ErrorCodeValidation, nil))
+		return nil, false
+	}
+	return &parsed, true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// RecommendationHandler exposes stock recommendation endpoints
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+}
+
+// NewRecommendationHandler creates a new recommendation handler
+func NewRecommendationHandler(recommendationService *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// ListRecommendations handles GET /recommendations
+func (h *RecommendationHandler) ListRecommendations(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if status := c.Query("status"); status != "" {
+		if !models.ValidateRecommendationStatus(status) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["status"] = status
+	}
+	if priority := c.Query("priority"); priority != "" {
+		if !models.ValidateRecommendationPriority(priority) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid priority", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["priority"] = priority
+	}
+	if productID := c.Query("productId"); productID != "" {
+		id, err := uuid.Parse(productID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid productId", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["product_id"] = id
+	}
+
+	recommendations, total, err := h.recommendationService.ListRecommendations(c.Request.Context(), filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, recommendations, pagination, total)
+}
+
+// GetPendingRecommendations handles GET /recommendations/pending
+func (h *RecommendationHandler) GetPendingRecommendations(c *gin.Context) {
+	recommendations, err := h.recommendationService.GetPendingRecommendations(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, recommendations))
+}
+
+// GetRecommendation handles GET /recommendations/:id
+func (h *RecommendationHandler) GetRecommendation(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	recommendation, err := h.recommendationService.GetRecommendation(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, recommendation))
+}
+
+// TakeAction handles POST /recommendations/:id/action
+func (h *RecommendationHandler) TakeAction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ActionRecommendationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	recommendation, err := h.recommendationService.TakeAction(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, recommendation))
+}
+
+// GenerateRecommendations handles POST /recommendations/generate, running
+// the generator immediately instead of waiting for the background job
+func (h *RecommendationHandler) GenerateRecommendations(c *gin.Context) {
+	result, err := h.recommendationService.GenerateRecommendations(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Recommendations generated", result))
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/middleware"
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// errorMapping maps a service error to an HTTP status and API error code
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// serviceErrors lists the known service errors in match order
+var serviceErrors = []errorMapping{
+	{services.ErrInvalidCredentials, http.StatusUnauthorized, models.ErrorCodeUnauthorized},
+	{services.ErrTooManyLoginAttempts, http.StatusTooManyRequests, models.ErrorCodeTooManyRequests},
+	{services.ErrUserNotActive, http.StatusForbidden, models.ErrorCodeForbidden},
+	{services.ErrEmailAlreadyExists, http.StatusConflict, models.ErrorCodeEmailExists},
+	{services.ErrInvalidToken, http.StatusUnauthorized, models.ErrorCodeInvalidToken},
+	{services.ErrTokenExpired, http.StatusUnauthorized, models.ErrorCodeExpiredToken},
+	{services.ErrRefreshTokenReused, http.StatusUnauthorized, models.ErrorCodeInvalidToken},
+	{services.ErrInvalidResetToken, http.StatusBadRequest, models.ErrorCodeInvalidToken},
+	{services.ErrWeakPassword, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidVerificationToken, http.StatusBadRequest, models.ErrorCodeInvalidToken},
+	{services.ErrEmailNotVerified, http.StatusForbidden, models.ErrorCodeEmailNotVerified},
+	{services.ErrInvalidTwoFactorCode, http.StatusUnauthorized, models.ErrorCodeUnauthorized},
+	{services.ErrInvalidTwoFactorChallenge, http.StatusUnauthorized, models.ErrorCodeInvalidToken},
+	{services.ErrTwoFactorNotEnabled, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrTwoFactorAlreadyEnabled, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrTwoFactorNotEnrolling, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrTwoFactorRequired, http.StatusForbidden, models.ErrorCodeTwoFactorRequired},
+	{services.ErrInsufficientRole, http.StatusForbidden, models.ErrorCodeForbidden},
+	{services.ErrUserNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrUserProfileNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrCannotUpdateOwnRole, http.StatusBadRequest, models.ErrorCodeBadRequest},
+	{services.ErrCannotDeactivateAdmin, http.StatusBadRequest, models.ErrorCodeBadRequest},
+	{services.ErrCannotDeleteOwnAccount, http.StatusBadRequest, models.ErrorCodeBadRequest},
+	{services.ErrTransactionNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrProductNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrProductUnavailable, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInsufficientStock, http.StatusConflict, models.ErrorCodeInsufficientStock},
+	{services.ErrInsufficientPayment, http.StatusBadRequest, models.ErrorCodePaymentFailed},
+	{services.ErrInvalidPayment, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrOverpayment, http.StatusBadRequest, models.ErrorCodePaymentFailed},
+	{services.ErrTransactionNotRefundable, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrRefundItemsRequired, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrRefundItemNotFound, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrRefundQuantityExceeded, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidReceiptFormat, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidPaperWidth, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrNoRefundReceipt, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrNoCustomerEmail, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrCartNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrCartItemNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrCartEmpty, http.StatusBadRequest, models.ErrorCodeBadRequest},
+	{services.ErrCartNotActive, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrCartHasIssues, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInvalidDiscount, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrProductExists, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInvalidStockLevels, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidStatus, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrUnsupportedImportFile, http.StatusUnsupportedMediaType, models.ErrorCodeValidation},
+	{services.ErrImportMissingSKU, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrImportEmpty, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrImportTooManyRows, http.StatusRequestEntityTooLarge, models.ErrorCodeValidation},
+	{services.ErrCategoryNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrCategoryNameExists, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrCategoryInUse, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrCategoryHasChildren, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInvalidParent, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrAdjustmentNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrAdjustmentNotPending, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInvalidReasonCode, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrRecommendationNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrInvalidRecommendationAction, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrExpenseNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrExpenseImmutable, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrExpenseNotPending, http.StatusConflict, models.ErrorCodeConflict},
+	{services.ErrInvalidExpenseCategory, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrReceiptNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrFileTooLarge, http.StatusRequestEntityTooLarge, models.ErrorCodeValidation},
+	{services.ErrFileTypeNotAllowed, http.StatusUnsupportedMediaType, models.ErrorCodeValidation},
+	{services.ErrInvalidDateRange, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidGroupBy, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrUnknownExportResource, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrInvalidExportFormat, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidExportField, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrInvalidExportFilter, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrExportNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+	{services.ErrExportLinkInvalid, http.StatusForbidden, models.ErrorCodeForbidden},
+	{services.ErrExportLinkExpired, http.StatusGone, models.ErrorCodeForbidden},
+	{services.ErrInvalidBulkOperation, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrBulkEmpty, http.StatusBadRequest, models.ErrorCodeValidation},
+	{services.ErrBulkTooLarge, http.StatusRequestEntityTooLarge, models.ErrorCodeValidation},
+	{gorm.ErrRecordNotFound, http.StatusNotFound, models.ErrorCodeNotFound},
+}
+
+// detailedError is implemented by service errors that carry structured
+// details for the client
+type detailedError interface {
+	Details() map[string]interface{}
+}
+
+// respondError writes the error envelope for a service error. Unknown errors
+// are logged and reported as a generic internal error.
+func respondError(c *gin.Context, err error) {
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			var details map[string]interface{}
+			var detailed detailedError
+			if errors.As(err, &detailed) {
+				details = detailed.Details()
+			}
+			c.JSON(mapping.status, models.ErrorResponse(mapping.err.Error(), mapping.code, details))
+			return
+		}
+	}
+
+	log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse("Internal server error", models.ErrorCodeInternalError, nil))
+}
+
+// respondBulkResult writes the result of a bulk operation. The status is
+// 200 even when items failed; rolled back operations get a 422 since
+// nothing was applied.
+func respondBulkResult(c *gin.Context, result *models.BulkOperationResult) {
+	switch {
+	case result.RolledBack:
+		response := models.ErrorResponse("Bulk operation rolled back", models.ErrorCodeValidation, nil)
+		response.Data = result
+		c.JSON(http.StatusUnprocessableEntity, response)
+	case result.Failed > 0:
+		c.JSON(http.StatusOK, models.SuccessResponse("Bulk operation completed with errors", result))
+	default:
+		c.JSON(http.StatusOK, models.SuccessResponse("Bulk operation completed", result))
+	}
+}
+
+// respondValidationError writes a 400 response for a request binding failure
+func respondValidationError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid request", models.ErrorCodeValidation, map[string]interface{}{
+		"reason": err.Error(),
+	}))
+}
+
+// respondPaginated writes a paginated success envelope
+func respondPaginated(c *gin.Context, message string, data interface{}, pagination *models.PaginationQuery, total int64) {
+	c.JSON(http.StatusOK, models.PaginatedSuccessResponse(message, data,
+		models.CalculatePagination(pagination.GetPage(), pagination.GetLimit(), total)))
+}
+
+// bindPagination reads pagination query parameters, writing a 400 on failure
+func bindPagination(c *gin.Context) (*models.PaginationQuery, bool) {
+	var pagination models.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		respondValidationError(c, err)
+		return nil, false
+	}
+	return &pagination, true
+}
+
+// parseUUIDParam reads a UUID path parameter, writing a 400 on failure
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid "+name, models.ErrorCodeBadRequest, nil))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// currentUserID returns the authenticated user's ID, writing a 401 if missing
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse("Authentication required", models.ErrorCodeUnauthorized, nil))
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// isManager reports whether the authenticated user is a manager or admin
+func isManager(c *gin.Context) bool {
+	user, ok := middleware.GetUserFromContext(c)
+	return ok && user.IsManager()
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/middleware"
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+	"github.com/pos-system/backend/pkg/receipt"
+)
+
+// TransactionHandler exposes checkout and sales transaction endpoints
+type TransactionHandler struct {
+	transactionService *services.TransactionService
+	receiptService     *services.ReceiptService
+}
+
+// NewTransactionHandler creates a new transaction handler
+func NewTransactionHandler(transactionService *services.TransactionService, receiptService *services.ReceiptService) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		receiptService:     receiptService,
+	}
+}
+
+// Checkout handles POST /transactions
+func (h *TransactionHandler) Checkout(c *gin.Context) {
+	cashierID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	transaction, err := h.transactionService.Checkout(c.Request.Context(), cashierID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, transaction))
+}
+
+// ListTransactions handles GET /transactions. Cashiers only see their own
+// transactions.
+func (h *TransactionHandler) ListTransactions(c *gin.Context) {
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters, ok := bindTransactionFilters(c)
+	if !ok {
+		return
+	}
+
+	if !isManager(c) {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		filters.CashierID = &userID
+	}
+
+	transactions, total, err := h.transactionService.ListTransactions(c.Request.Context(), filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, transactions, pagination, total)
+}
+
+// GetTransaction handles GET /transactions/:id
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransaction(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !canViewTransaction(c, transaction) {
+		respondError(c, services.ErrTransactionNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, transaction))
+}
+
+// GetTransactionByReceipt handles GET /transactions/receipt/:receiptId
+func (h *TransactionHandler) GetTransactionByReceipt(c *gin.Context) {
+	transaction, err := h.transactionService.GetTransactionByReceiptID(c.Request.Context(), c.Param("receiptId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !canViewTransaction(c, transaction) {
+		respondError(c, services.ErrTransactionNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, transaction))
+}
+
+// RefundTransaction handles POST /transactions/:id/refund
+func (h *TransactionHandler) RefundTransaction(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.RefundTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.transactionService.Refund(c.Request.Context(), id, userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Refund processed", result))
+}
+
+// GetReceipt handles GET /transactions/:id/receipt. format is json (the
+// default), text, html, escpos or pdf; paper is 58 or 80 (the default) mm; type
+// refund prints what has been refunded instead of the sale.
+func (h *TransactionHandler) GetReceipt(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	result, err := h.receiptService.GetReceipt(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !canViewTransaction(c, &result.Transaction) {
+		respondError(c, services.ErrTransactionNotFound)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format == "json" {
+		c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, result))
+		return
+	}
+
+	paper, err := strconv.Atoi(c.DefaultQuery("paper", "80"))
+	if err != nil {
+		respondError(c, services.ErrInvalidPaperWidth)
+		return
+	}
+	var refund bool
+	switch c.DefaultQuery("type", "sale") {
+	case "sale":
+	case "refund":
+		refund = true
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid receipt type", models.ErrorCodeValidation, nil))
+		return
+	}
+
+	output, err := h.receiptService.RenderReceipt(result, receipt.Format(format), receipt.Paper(paper), refund)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if format == string(receipt.FormatPDF) {
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", result.Transaction.ReceiptID+".pdf"))
+	}
+	c.Data(http.StatusOK, receipt.Format(format).ContentType(), output)
+}
+
+// EmailReceipt handles POST /transactions/:id/receipt/email. The receipt
+// goes to the email in the body, or the customer email given at checkout.
+func (h *TransactionHandler) EmailReceipt(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.EmailReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.receiptService.GetReceipt(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !canViewTransaction(c, &result.Transaction) {
+		respondError(c, services.ErrTransactionNotFound)
+		return
+	}
+
+	delivery, err := h.receiptService.EmailReceipt(c.Request.Context(), userID, result, req.Email)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// The delivery record carries the error when the first attempt fails
+	switch delivery.Status {
+	case models.EmailStatusSent:
+		c.JSON(http.StatusOK, models.SuccessResponse("Receipt emailed", delivery))
+	case models.EmailStatusPending:
+		c.JSON(http.StatusAccepted, models.SuccessResponse("Receipt email queued for retry", delivery))
+	default:
+		c.JSON(http.StatusBadGateway, models.ErrorResponse("Receipt email was rejected", models.ErrorCodeInternalError,
+			map[string]interface{}{"delivery": delivery}))
+	}
+}
+
+// ListReceiptEmails handles GET /transactions/:id/receipt/emails
+func (h *TransactionHandler) ListReceiptEmails(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransaction(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if !canViewTransaction(c, transaction) {
+		respondError(c, services.ErrTransactionNotFound)
+		return
+	}
+
+	deliveries, err := h.receiptService.ListReceiptEmails(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, deliveries))
+}
+
+// canViewTransaction reports whether the current user may see the
+// transaction; cashiers are limited to their own sales
+func canViewTransaction(c *gin.Context, transaction *models.Transaction) bool {
+	if isManager(c) {
+		return true
+	}
+	userID, _ := middleware.GetUserIDFromContext(c)
+	return transaction.CashierID == userID
+}
+
+// bindTransactionFilters reads transaction list filters from the query
+// string, writing a 400 on failure
+func bindTransactionFilters(c *gin.Context) (*models.TransactionFilters, bool) {
+	filters := &models.TransactionFilters{}
+
+	if status := c.Query("status"); status != "" {
+		if !models.ValidateTransactionStatus(status) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid status", models.ErrorCodeValidation, nil))
+			return nil, false
+		}
+		value := models.TransactionStatus(status)
+		filters.Status = &value
+	}
+	if method := c.Query("paymentMethod"); method != "" {
+		if !models.ValidatePaymentMethod(method) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid paymentMethod", models.ErrorCodeValidation, nil))
+			return nil, false
+		}
+		value := models.PaymentMethod(method)
+		filters.PaymentMethod = &value
+	}
+	if cashierID := c.Query("cashierId"); cashierID != "" {
+		id, err := uuid.Parse(cashierID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid cashierId", models.ErrorCodeValidation, nil))
+			return nil, false
+		}
+		filters.CashierID = &id
+	}
+	if receiptID := c.Query("receiptId"); receiptID != "" {
+		filters.ReceiptID = &receiptID
+	}
+	if email := c.Query("customerEmail"); email != "" {
+		filters.CustomerEmail = &email
+	}
+
+	var ok bool
+	if filters.StartDate, ok = parseDateQuery(c, "startDate", false); !ok {
+		return nil, false
+	}
+	if filters.EndDate, ok = parseDateQuery(c, "endDate", true); !ok {
+		return nil, false
+	}
+
+	return filters, true
+}
+
+// parseDateQuery reads an optional RFC 3339 or YYYY-MM-DD query parameter,
+// writing a 400 on failure. With endOfDay a bare date covers the whole day.
+func parseDateQuery(c *gin.Context, name string, endOfDay bool) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return &t, true
+	}
+
+	c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid "+name, models.ErrorCodeValidation, nil))
+	return nil, false
+}
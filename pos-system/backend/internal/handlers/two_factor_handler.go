@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// TwoFactorHandler exposes two-factor authentication management endpoints.
+// The login steps themselves are on AuthHandler.
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// GetStatus handles GET /auth/two-factor
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactorService.Status(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, status))
+}
+
+// Enroll handles POST /auth/two-factor/enroll
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Add the secret to your authenticator app", enrollment))
+}
+
+// ConfirmEnrollment handles POST /auth/two-factor/enroll/confirm
+func (h *TwoFactorHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Two-factor authentication enabled", models.RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+// Disable handles POST /auth/two-factor/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Two-factor authentication disabled", nil))
+}
+
+// RegenerateRecoveryCodes handles POST /auth/two-factor/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Recovery codes replaced", models.RecoveryCodesResponse{RecoveryCodes: codes}))
+}
+
+// GetPolicies handles GET /users/two-factor/policies
+func (h *TwoFactorHandler) GetPolicies(c *gin.Context) {
+	policies, err := h.twoFactorService.Policies(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, policies))
+}
+
+// UpdatePolicy handles PUT /users/two-factor/policies
+func (h *TwoFactorHandler) UpdatePolicy(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTwoFactorPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if !models.ValidateRole(string(req.Role)) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid role", models.ErrorCodeValidation, nil))
+		return
+	}
+
+	policy, err := h.twoFactorService.SetPolicy(c.Request.Context(), requestorID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, policy))
+}
+
+// ResetUser handles DELETE /users/:id/two-factor
+func (h *TwoFactorHandler) ResetUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Reset(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Two-factor authentication reset", nil))
+}
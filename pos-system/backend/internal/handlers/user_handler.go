@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/services"
+)
+
+// UserHandler exposes admin user management endpoints
+type UserHandler struct {
+	userService *services.UserService
+}
+
+// NewUserHandler creates a new user management handler
+func NewUserHandler(userService *services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// ListUsers handles GET /users
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	pagination, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if role := c.Query("role"); role != "" {
+		if !models.ValidateRole(role) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid role", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["role"] = role
+	}
+	if isActive := c.Query("isActive"); isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid isActive", models.ErrorCodeValidation, nil))
+			return
+		}
+		filters["is_active"] = active
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), requestorID, filters, pagination)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondPaginated(c, models.MessageRetrievedSuccessfully, users, pagination, total)
+}
+
+// CreateUser handles POST /users
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.Role != nil && !models.ValidateRole(string(*req.Role)) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid role", models.ErrorCodeValidation, nil))
+		return
+	}
+
+	user, err := h.userService.CreateUser(c.Request.Context(), requestorID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse(models.MessageCreatedSuccessfully, user))
+}
+
+// BulkUsers handles POST /users/bulk
+func (h *UserHandler) BulkUsers(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkOperation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result, err := h.userService.BulkUsers(c.Request.Context(), requestorID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondBulkResult(c, result)
+}
+
+// GetUser handles GET /users/:id
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserProfile(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, user))
+}
+
+// UpdateUser handles PUT /users/:id
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, err := h.userService.UpdateUser(c.Request.Context(), requestorID, targetID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, user))
+}
+
+// UpdateUserRole handles PUT /users/:id/role
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if !models.ValidateRole(string(req.Role)) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse("Invalid role", models.ErrorCodeValidation, nil))
+		return
+	}
+
+	if err := h.userService.UpdateUserRole(c.Request.Context(), requestorID, targetID, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageUpdatedSuccessfully, nil))
+}
+
+// ActivateUser handles POST /users/:id/activate
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ActivateUser(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("User activated", nil))
+}
+
+// DeactivateUser handles POST /users/:id/deactivate
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeactivateUser(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("User deactivated", nil))
+}
+
+// UnlockUser handles POST /users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.UnlockUser(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("User unlocked", nil))
+}
+
+// DeleteUser handles DELETE /users/:id
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageDeletedSuccessfully, nil))
+}
+
+// GetUserSessions handles GET /users/:id/sessions
+func (h *UserHandler) GetUserSessions(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sessions, err := h.userService.GetUserSessions(c.Request.Context(), requestorID, targetID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, sessions))
+}
+
+// RevokeAllUserSessions handles DELETE /users/:id/sessions
+func (h *UserHandler) RevokeAllUserSessions(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RevokeAllUserSessions(c.Request.Context(), requestorID, targetID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("All sessions revoked", nil))
+}
+
+// RevokeUserSession handles DELETE /users/sessions/:sessionId
+func (h *UserHandler) RevokeUserSession(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, ok := parseUUIDParam(c, "sessionId")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RevokeUserSession(c.Request.Context(), requestorID, sessionID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse("Session revoked", nil))
+}
+
+// GetUserAccounts handles GET /users/:id/accounts
+func (h *UserHandler) GetUserAccounts(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	accounts, err := h.userService.GetUserAccounts(c.Request.Context(), requestorID, targetID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, accounts))
+}
+
+// GetUserStatistics handles GET /users/statistics
+func (h *UserHandler) GetUserStatistics(c *gin.Context) {
+	requestorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.userService.GetUserStatistics(c.Request.Context(), requestorID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(models.MessageRetrievedSuccessfully, stats))
+}
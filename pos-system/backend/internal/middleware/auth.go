@@ -297,12 +297,15 @@ func SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// RateLimiter middleware for basic rate limiting
-// Note: In production, use a proper rate limiter like go-redis/redis_rate
-func RateLimiter() gin.HandlerFunc {
+// ClientInfo middleware makes the client IP and user agent available to
+// services through the request context
+func ClientInfo() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// This is a placeholder for rate limiting
-		// In a real implementation, you'd use a proper rate limiter
+		ctx := services.ContextWithClientInfo(c.Request.Context(), services.ClientInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
@@ -1,17 +1,38 @@
 package middleware
 
 import (
+	"time"
+
 	"github.com/pos-system/backend/internal/services"
+	"github.com/pos-system/backend/pkg/config"
+	"github.com/pos-system/backend/pkg/ratelimit"
 )
 
 // Middleware holds all middleware instances
 type Middleware struct {
-	Auth *AuthMiddleware
+	Auth      *AuthMiddleware
+	RateLimit *RateLimitMiddleware
 }
 
-// NewMiddleware creates all middleware instances
-func NewMiddleware(services *services.Services) *Middleware {
+// NewMiddleware creates all middleware instances. Rate limits are kept in
+// memory, so with several instances each enforces them separately.
+func NewMiddleware(services *services.Services, cfg *config.Config) *Middleware {
 	return &Middleware{
-		Auth: NewAuthMiddleware(services.Auth),
+		Auth:      NewAuthMiddleware(services.Auth),
+		RateLimit: newRateLimitMiddleware(cfg),
 	}
 }
+
+// newRateLimitMiddleware creates a rate limiter with the configured limits,
+// keeping its buckets in memory
+func newRateLimitMiddleware(cfg *config.Config) *RateLimitMiddleware {
+	var store ratelimit.Store
+	if cfg.RateLimitEnabled {
+		store = ratelimit.NewMemoryStore()
+	}
+
+	return NewRateLimitMiddleware(store,
+		ratelimit.Limit{Requests: cfg.RateLimitRequests, Window: time.Duration(cfg.RateLimitWindow) * time.Second},
+		ratelimit.Limit{Requests: cfg.RateLimitAuthRequests, Window: time.Duration(cfg.RateLimitAuthWindow) * time.Second},
+	)
+}
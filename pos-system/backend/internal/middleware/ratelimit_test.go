@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/pkg/ratelimit"
+)
+
+func TestRateLimitPerIPAndUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	alice, bob := uuid.New(), uuid.New()
+
+	type request struct {
+		ip   string
+		user uuid.UUID // uuid.Nil for an anonymous request
+	}
+	tests := []struct {
+		name     string
+		requests []request
+		want     []int
+	}{
+		{
+			name:     "anonymous requests count per IP",
+			requests: []request{{ip: "198.51.100.1"}, {ip: "198.51.100.1"}, {ip: "198.51.100.1"}, {ip: "198.51.100.2"}},
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "a user moving between addresses",
+			requests: []request{
+				{ip: "198.51.100.1", user: alice},
+				{ip: "198.51.100.2", user: alice},
+				{ip: "198.51.100.3", user: alice},
+				{ip: "198.51.100.3", user: bob},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "one address spreading over users",
+			requests: []request{
+				{ip: "198.51.100.1", user: alice},
+				{ip: "198.51.100.1", user: bob},
+				{ip: "198.51.100.1", user: uuid.New()},
+				{ip: "198.51.100.2", user: alice},
+			},
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimitMiddleware(ratelimit.NewMemoryStore(),
+				ratelimit.Limit{Requests: 2, Window: time.Hour},
+				ratelimit.Limit{Requests: 1, Window: time.Hour},
+			)
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if id, err := uuid.Parse(c.GetHeader("X-Test-User")); err == nil {
+					c.Set("user_id", id)
+				}
+			}, limiter.API(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = r.ip + ":1234"
+				if r.user != uuid.Nil {
+					req.Header.Set("X-Test-User", r.user.String())
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Code != tt.want[i] {
+					t.Errorf("Expected request %d to get %d, got %d", i+1, tt.want[i], w.Code)
+				}
+			}
+		})
+	}
+}
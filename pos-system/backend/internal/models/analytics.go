@@ -20,24 +20,38 @@ const (
 	ExpenseCategoryOther       ExpenseCategory = "OTHER"
 )
 
+// ExpenseStatus represents where an expense is in the approval flow
+type ExpenseStatus string
+
+const (
+	ExpenseStatusPending  ExpenseStatus = "PENDING"
+	ExpenseStatusApproved ExpenseStatus = "APPROVED"
+	ExpenseStatusRejected ExpenseStatus = "REJECTED"
+)
+
 // Expense represents a business expense
 type Expense struct {
-	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	Title       string          `json:"title" gorm:"not null"`
-	Description *string         `json:"description,omitempty" gorm:"type:text"`
-	Amount      float64         `json:"amount" gorm:"not null;check:amount > 0"`
-	Category    ExpenseCategory `json:"category" gorm:"type:expense_category;not null"`
-	Date        time.Time       `json:"date" gorm:"not null;index"`
-	Receipt     *string         `json:"receipt,omitempty"` // File URL
-	CreatedBy   uuid.UUID       `json:"createdBy" gorm:"type:uuid;not null;index"`
-	ApprovedBy  *uuid.UUID      `json:"approvedBy,omitempty" gorm:"type:uuid"`
-	ApprovedAt  *time.Time      `json:"approvedAt,omitempty"`
-	CreatedAt   time.Time       `json:"createdAt" gorm:"not null;default:now()"`
-	UpdatedAt   time.Time       `json:"updatedAt" gorm:"not null;default:now()"`
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Title           string          `json:"title" gorm:"not null"`
+	Description     *string         `json:"description,omitempty" gorm:"type:text"`
+	Amount          float64         `json:"amount" gorm:"not null;check:amount > 0"`
+	Category        ExpenseCategory `json:"category" gorm:"type:expense_category;not null"`
+	Date            time.Time       `json:"date" gorm:"not null;index"`
+	Receipt         *string         `json:"receipt,omitempty"` // Storage key of the uploaded receipt
+	Status          ExpenseStatus   `json:"status" gorm:"type:varchar(20);not null;default:'PENDING';index"`
+	CreatedBy       uuid.UUID       `json:"createdBy" gorm:"type:uuid;not null;index"`
+	ApprovedBy      *uuid.UUID      `json:"approvedBy,omitempty" gorm:"type:uuid"`
+	ApprovedAt      *time.Time      `json:"approvedAt,omitempty"`
+	RejectedBy      *uuid.UUID      `json:"rejectedBy,omitempty" gorm:"type:uuid"`
+	RejectedAt      *time.Time      `json:"rejectedAt,omitempty"`
+	RejectionReason *string         `json:"rejectionReason,omitempty" gorm:"type:text"`
+	CreatedAt       time.Time       `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt       time.Time       `json:"updatedAt" gorm:"not null;default:now()"`
 
 	// Relationships
 	CreatedByUser  User  `json:"createdByUser,omitempty" gorm:"foreignKey:CreatedBy"`
 	ApprovedByUser *User `json:"approvedByUser,omitempty" gorm:"foreignKey:ApprovedBy"`
+	RejectedByUser *User `json:"rejectedByUser,omitempty" gorm:"foreignKey:RejectedBy"`
 }
 
 // TableName specifies the table name for GORM
@@ -53,6 +67,7 @@ const (
 	RecommendationStatusAccepted  StockRecommendationStatus = "ACCEPTED"
 	RecommendationStatusRejected  StockRecommendationStatus = "REJECTED"
 	RecommendationStatusProcessed StockRecommendationStatus = "PROCESSED"
+	RecommendationStatusExpired   StockRecommendationStatus = "EXPIRED" // closed unactioned once no longer needed
 )
 
 // StockRecommendationPriority represents the priority of a recommendation
@@ -107,6 +122,9 @@ const (
 	AuditActionCreateProduct     AuditLogAction = "CREATE_PRODUCT"
 	AuditActionUpdateProduct     AuditLogAction = "UPDATE_PRODUCT"
 	AuditActionDeleteProduct     AuditLogAction = "DELETE_PRODUCT"
+	AuditActionCreateCategory    AuditLogAction = "CREATE_CATEGORY"
+	AuditActionUpdateCategory    AuditLogAction = "UPDATE_CATEGORY"
+	AuditActionDeleteCategory    AuditLogAction = "DELETE_CATEGORY"
 	AuditActionUpdateStock       AuditLogAction = "UPDATE_STOCK"
 	AuditActionCreateTransaction AuditLogAction = "CREATE_TRANSACTION"
 	AuditActionRefundTransaction AuditLogAction = "REFUND_TRANSACTION"
@@ -114,6 +132,9 @@ const (
 	AuditActionUpdateExpense     AuditLogAction = "UPDATE_EXPENSE"
 	AuditActionDeleteExpense     AuditLogAction = "DELETE_EXPENSE"
 	AuditActionSystemConfig      AuditLogAction = "SYSTEM_CONFIG"
+	AuditActionTokenReuse        AuditLogAction = "TOKEN_REUSE"
+	AuditActionTwoFactorEnroll   AuditLogAction = "TWO_FACTOR_ENROLL"
+	AuditActionTwoFactorDisable  AuditLogAction = "TWO_FACTOR_DISABLE"
 )
 
 // AuditLog represents an audit log entry
@@ -125,8 +146,8 @@ type AuditLog struct {
 	Action     AuditLogAction         `json:"action" gorm:"type:audit_action;not null;index"`
 	Resource   string                 `json:"resource" gorm:"not null;index"` // "user", "product", "transaction", etc.
 	ResourceID *string                `json:"resourceId,omitempty"`
-	OldValues  map[string]interface{} `json:"oldValues,omitempty" gorm:"type:jsonb"`
-	NewValues  map[string]interface{} `json:"newValues,omitempty" gorm:"type:jsonb"`
+	OldValues  map[string]interface{} `json:"oldValues,omitempty" gorm:"type:jsonb;serializer:json"`
+	NewValues  map[string]interface{} `json:"newValues,omitempty" gorm:"type:jsonb;serializer:json"`
 	IPAddress  string                 `json:"ipAddress" gorm:"type:inet;not null"`
 	UserAgent  string                 `json:"userAgent" gorm:"type:text;not null"`
 	Timestamp  time.Time              `json:"timestamp" gorm:"not null;default:now();index"`
@@ -176,7 +197,6 @@ type CreateExpenseRequest struct {
 	Amount      float64         `json:"amount" binding:"required,gt=0"`
 	Category    ExpenseCategory `json:"category" binding:"required"`
 	Date        time.Time       `json:"date" binding:"required"`
-	Receipt     *string         `json:"receipt,omitempty"`
 }
 
 // UpdateExpenseRequest represents the request to update an expense
@@ -186,7 +206,11 @@ type UpdateExpenseRequest struct {
 	Amount      *float64         `json:"amount,omitempty" binding:"omitempty,gt=0"`
 	Category    *ExpenseCategory `json:"category,omitempty"`
 	Date        *time.Time       `json:"date,omitempty"`
-	Receipt     *string          `json:"receipt,omitempty"`
+}
+
+// RejectExpenseRequest represents a manager's rejection of an expense
+type RejectExpenseRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=500"`
 }
 
 // ActionRecommendationRequest represents the request to take action on a recommendation
@@ -219,11 +243,24 @@ type Dashboard struct {
 	WeeklySales        DashboardSales        `json:"weeklySales"`
 	MonthlySales       DashboardSales        `json:"monthlySales"`
 	TopProducts        []ProductSales        `json:"topProducts"`
-	RecentTransactions []TransactionSummary  `json:"recentTransactions"`
+	RecentTransactions []RecentTransaction   `json:"recentTransactions"`
 	SalesChart         []ChartData           `json:"salesChart"`
 	CategoryChart      []ChartData           `json:"categoryChart"`
 	PaymentMethodChart []ChartData           `json:"paymentMethodChart"`
 	Recommendations    []StockRecommendation `json:"recommendations"`
+	GeneratedAt        time.Time             `json:"generatedAt"`
+}
+
+// RecentTransaction is a compact transaction row for the dashboard feed
+type RecentTransaction struct {
+	ID            uuid.UUID         `json:"id"`
+	ReceiptID     string            `json:"receiptId"`
+	CashierName   string            `json:"cashierName"`
+	ItemCount     int               `json:"itemCount"`
+	Total         float64           `json:"total"`
+	PaymentMethod PaymentMethod     `json:"paymentMethod"`
+	Status        TransactionStatus `json:"status"`
+	CreatedAt     time.Time         `json:"createdAt"`
 }
 
 // DashboardSales represents sales summary for dashboard
@@ -247,13 +284,23 @@ type SalesReport struct {
 	Period             string               `json:"period"`
 	StartDate          time.Time            `json:"startDate"`
 	EndDate            time.Time            `json:"endDate"`
-	TotalSales         float64              `json:"totalSales"`
+	GroupBy            ReportGroupBy        `json:"groupBy"`
+	GrossSales         float64              `json:"grossSales"`
+	TotalRefunds       float64              `json:"totalRefunds"`
+	TotalSales         float64              `json:"totalSales"` // Gross sales less refunds
+	TotalTax           float64              `json:"totalTax"`
+	TotalDiscount      float64              `json:"totalDiscount"`
+	NetRevenue         float64              `json:"netRevenue"` // Total sales excluding tax
+	TotalCost          float64              `json:"totalCost"`
+	GrossProfit        float64              `json:"grossProfit"`
+	GrossMargin        float64              `json:"grossMargin"` // Percentage of net revenue
 	TotalTransactions  int                  `json:"totalTransactions"`
 	TotalItems         int                  `json:"totalItems"`
 	AverageOrder       float64              `json:"averageOrder"`
 	TopProducts        []ProductSales       `json:"topProducts"`
 	CategoryBreakdown  []ChartData          `json:"categoryBreakdown"`
-	DailySales         []DailySales         `json:"dailySales"`
+	Series             []SalesPeriod        `json:"series"`
+	DailySales         []DailySales         `json:"dailySales"` // Series in its original shape; only set when grouped by day
 	CashierPerformance []CashierPerformance `json:"cashierPerformance"`
 	PaymentMethods     map[string]float64   `json:"paymentMethods"`
 }
@@ -269,6 +316,11 @@ type InventoryReport struct {
 
 // Helper methods
 
+// IsApproved checks if the expense has been approved and can no longer change
+func (e *Expense) IsApproved() bool {
+	return e.Status == ExpenseStatusApproved
+}
+
 // ValidateExpenseCategory checks if an expense category is valid
 func ValidateExpenseCategory(category string) bool {
 	switch ExpenseCategory(category) {
@@ -285,7 +337,8 @@ func ValidateExpenseCategory(category string) bool {
 func ValidateRecommendationStatus(status string) bool {
 	switch StockRecommendationStatus(status) {
 	case RecommendationStatusPending, RecommendationStatusAccepted,
-		RecommendationStatusRejected, RecommendationStatusProcessed:
+		RecommendationStatusRejected, RecommendationStatusProcessed,
+		RecommendationStatusExpired:
 		return true
 	default:
 		return false
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -244,19 +245,32 @@ type FileUploadResponse struct {
 	MimeType string `json:"mimeType"`
 }
 
-// BulkOperation represents a bulk operation request
+// Bulk operations
+const (
+	BulkOperationCreate = "create"
+	BulkOperationUpdate = "update"
+	BulkOperationDelete = "delete"
+)
+
+// BulkOperation represents a bulk operation request. Creates take an array
+// of payloads in Data. Updates take either an array of payloads that each
+// carry an "id", or IDs with a single payload applied to every record.
+// Deletes take IDs. Atomic operations apply every item or none of them.
 type BulkOperation struct {
-	Operation string      `json:"operation" binding:"required,oneof=create update delete"`
-	IDs       []uuid.UUID `json:"ids,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
+	Operation string          `json:"operation" binding:"required,oneof=create update delete"`
+	IDs       []uuid.UUID     `json:"ids,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Atomic    bool            `json:"atomic"`
 }
 
-// BulkOperationResult represents the result of a bulk operation
+// BulkOperationResult represents the result of a bulk operation. When an
+// atomic operation is rolled back nothing was applied and Successful is 0.
 type BulkOperationResult struct {
 	Operation      string               `json:"operation"`
 	TotalRequested int                  `json:"totalRequested"`
 	Successful     int                  `json:"successful"`
 	Failed         int                  `json:"failed"`
+	RolledBack     bool                 `json:"rolledBack,omitempty"`
 	Errors         []BulkOperationError `json:"errors,omitempty"`
 }
 
@@ -303,6 +317,30 @@ type ExportResponse struct {
 	ExpiresAt   time.Time `json:"expiresAt"`
 }
 
+// ExportStatus is the state of an export being generated in the background
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "PENDING"
+	ExportStatusRunning   ExportStatus = "RUNNING"
+	ExportStatusCompleted ExportStatus = "COMPLETED"
+	ExportStatusFailed    ExportStatus = "FAILED"
+)
+
+// ExportJob represents an export being generated. Result holds the download
+// link once the export has completed.
+type ExportJob struct {
+	ID          uuid.UUID       `json:"id"`
+	Resource    string          `json:"resource"`
+	Format      string          `json:"format"`
+	Status      ExportStatus    `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	Result      *ExportResponse `json:"result,omitempty"`
+	RequestedBy uuid.UUID       `json:"requestedBy"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+}
+
 // EmailRequest represents email sending request
 type EmailRequest struct {
 	To          []string `json:"to" binding:"required,min=1"`
@@ -314,6 +352,50 @@ type EmailRequest struct {
 	Attachments []string `json:"attachments,omitempty"`
 }
 
+// EmailKind identifies what an email delivery carries
+type EmailKind string
+
+const (
+	EmailKindReceipt EmailKind = "RECEIPT"
+)
+
+// EmailStatus tracks an email delivery through its attempts
+type EmailStatus string
+
+const (
+	EmailStatusPending EmailStatus = "PENDING" // waiting for its next attempt
+	EmailStatusSent    EmailStatus = "SENT"
+	EmailStatusFailed  EmailStatus = "FAILED" // gave up after a permanent error or too many attempts
+)
+
+// EmailDelivery records an email queued for sending and the outcome of each
+// attempt. The message itself is rendered when it is sent.
+type EmailDelivery struct {
+	ID            uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Kind          EmailKind   `json:"kind" gorm:"type:varchar(30);not null"`
+	TransactionID *uuid.UUID  `json:"transactionId,omitempty" gorm:"type:uuid;index"`
+	Recipient     string      `json:"recipient" gorm:"not null"`
+	Status        EmailStatus `json:"status" gorm:"type:varchar(20);not null;default:'PENDING';index:idx_email_deliveries_due,priority:1"`
+	Attempts      int         `json:"attempts" gorm:"not null;default:0"`
+	LastError     *string     `json:"lastError,omitempty" gorm:"type:text"`
+	NextAttemptAt *time.Time  `json:"nextAttemptAt,omitempty" gorm:"index:idx_email_deliveries_due,priority:2"`
+	SentAt        *time.Time  `json:"sentAt,omitempty"`
+	RequestedBy   *uuid.UUID  `json:"requestedBy,omitempty" gorm:"type:uuid"`
+	CreatedAt     time.Time   `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt     time.Time   `json:"updatedAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailDelivery) TableName() string {
+	return "email_deliveries"
+}
+
+// EmailReceiptRequest represents a request to email a receipt. Without an
+// email the receipt goes to the transaction's customer email.
+type EmailReceiptRequest struct {
+	Email *string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
 // NotificationRequest represents notification request
 type NotificationRequest struct {
 	UserIDs []uuid.UUID            `json:"userIds" binding:"required,min=1"`
@@ -337,6 +419,9 @@ const (
 	ErrorCodeEmailExists       = "EMAIL_EXISTS"
 	ErrorCodeInvalidToken      = "INVALID_TOKEN"
 	ErrorCodeExpiredToken      = "EXPIRED_TOKEN"
+	ErrorCodeEmailNotVerified  = "EMAIL_NOT_VERIFIED"
+	ErrorCodeTooManyRequests   = "TOO_MANY_REQUESTS"
+	ErrorCodeTwoFactorRequired = "TWO_FACTOR_REQUIRED"
 )
 
 // Constants for success messages
@@ -25,13 +25,34 @@ const (
 	StockMovementAdjust StockMovementType = "adjust"
 )
 
+// StockAdjustmentReason is the structured reason code for a manual stock adjustment
+type StockAdjustmentReason string
+
+const (
+	StockAdjustmentDamage          StockAdjustmentReason = "damage"
+	StockAdjustmentTheft           StockAdjustmentReason = "theft"
+	StockAdjustmentCountCorrection StockAdjustmentReason = "count_correction"
+	StockAdjustmentExpired         StockAdjustmentReason = "expired"
+	StockAdjustmentReturn          StockAdjustmentReason = "return"
+	StockAdjustmentOther           StockAdjustmentReason = "other"
+)
+
+// StockAdjustmentStatus represents where an adjustment is in the approval workflow
+type StockAdjustmentStatus string
+
+const (
+	StockAdjustmentPending  StockAdjustmentStatus = "pending"
+	StockAdjustmentApplied  StockAdjustmentStatus = "applied"
+	StockAdjustmentRejected StockAdjustmentStatus = "rejected"
+)
+
 // Product represents a product in the system
 type Product struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Name        string         `gorm:"type:varchar(255);not null;index" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	SKU         string         `gorm:"type:varchar(100);unique;not null;index" json:"sku"`
-	Barcode     string         `gorm:"type:varchar(255);unique;index" json:"barcode"`
+	Barcode     *string        `gorm:"type:varchar(255);unique;index" json:"barcode"` // nil (null in JSON) when there is none; see migration 002
 	CategoryID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"category_id"`
 	Category    Category       `gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT" json:"category"`
 	Price       float64        `gorm:"type:decimal(10,2);not null;check:price >= 0" json:"price"`
@@ -86,6 +107,52 @@ type StockMovement struct {
 	CreatedAt   time.Time         `gorm:"autoCreateTime;index" json:"created_at"`
 }
 
+// StockAdjustment is a manual stock change submitted by staff. Adjustments
+// above the approval threshold stay pending until a manager reviews them.
+type StockAdjustment struct {
+	ID          uuid.UUID             `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProductID   uuid.UUID             `gorm:"type:uuid;not null;index" json:"product_id"`
+	Product     Product               `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product"`
+	Quantity    int                   `gorm:"not null;check:quantity <> 0" json:"quantity"` // signed stock delta
+	ReasonCode  StockAdjustmentReason `gorm:"type:varchar(30);not null;index" json:"reason_code"`
+	Reason      string                `gorm:"type:varchar(500)" json:"reason"`
+	Reference   string                `gorm:"type:varchar(255)" json:"reference"`
+	Notes       string                `gorm:"type:text" json:"notes"`
+	Value       float64               `gorm:"type:decimal(12,2);not null;default:0" json:"value"` // |quantity| x unit cost at submission
+	Status      StockAdjustmentStatus `gorm:"type:varchar(20);not null;default:'pending';index;check:status IN ('pending','applied','rejected')" json:"status"`
+	RequestedBy uuid.UUID             `gorm:"type:uuid;not null;index" json:"requested_by"`
+	ReviewedBy  *uuid.UUID            `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time            `json:"reviewed_at,omitempty"`
+	ReviewNotes string                `gorm:"type:text" json:"review_notes"`
+	StockBefore *int                  `json:"stock_before,omitempty"` // set once applied
+	StockAfter  *int                  `json:"stock_after,omitempty"`
+	CreatedAt   time.Time             `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt   time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// IsValid reports whether r is a known adjustment reason code
+func (r StockAdjustmentReason) IsValid() bool {
+	switch r {
+	case StockAdjustmentDamage, StockAdjustmentTheft, StockAdjustmentCountCorrection,
+		StockAdjustmentExpired, StockAdjustmentReturn, StockAdjustmentOther:
+		return true
+	}
+	return false
+}
+
+// TableName returns the table name for StockAdjustment model
+func (StockAdjustment) TableName() string {
+	return "stock_adjustments"
+}
+
+// BeforeCreate hook for StockAdjustment
+func (sa *StockAdjustment) BeforeCreate(tx *gorm.DB) error {
+	if sa.ID == uuid.Nil {
+		sa.ID = uuid.New()
+	}
+	return nil
+}
+
 // TableName returns the table name for Product model
 func (Product) TableName() string {
 	return "products"
@@ -175,6 +242,32 @@ type UpdateProductRequest struct {
 	IsActive    *bool          `json:"is_active,omitempty"`
 }
 
+// Product import row actions
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionFailed = "failed"
+)
+
+// ProductImportRow reports what an import did, or would do, with one row.
+// Index is the spreadsheet row number with the header as row 1.
+type ProductImportRow struct {
+	Index  int    `json:"index"`
+	SKU    string `json:"sku"`
+	Action string `json:"action"`
+}
+
+// ProductImportResult reports the outcome of a product import. A dry run
+// writes nothing and reports what the import would do.
+type ProductImportResult struct {
+	BulkOperationResult
+	DryRun            bool               `json:"dryRun"`
+	Created           int                `json:"created"`
+	Updated           int                `json:"updated"`
+	CategoriesCreated []string           `json:"categoriesCreated,omitempty"`
+	Rows              []ProductImportRow `json:"rows"`
+}
+
 // ProductFilters represents filters for product queries
 type ProductFilters struct {
 	CategoryID *uuid.UUID     `json:"category_id,omitempty"`
@@ -224,13 +317,21 @@ type CategoryWithProducts struct {
 	Products []Product `json:"products"`
 }
 
-// StockAdjustmentRequest represents a stock adjustment request
+// StockAdjustmentRequest represents a stock adjustment request. Quantity is
+// the signed change to apply; Reason is free text on top of ReasonCode.
 type StockAdjustmentRequest struct {
-	ProductID uuid.UUID `json:"product_id" binding:"required"`
-	Quantity  int       `json:"quantity" binding:"required"`
-	Reason    string    `json:"reason" binding:"required,min=1,max=500"`
-	Reference string    `json:"reference"`
-	Notes     string    `json:"notes"`
+	ProductID  uuid.UUID             `json:"product_id" binding:"required"`
+	Quantity   int                   `json:"quantity" binding:"required"`
+	ReasonCode StockAdjustmentReason `json:"reason_code" binding:"required"`
+	Reason     string                `json:"reason" binding:"omitempty,max=500"`
+	Reference  string                `json:"reference"`
+	Notes      string                `json:"notes"`
+}
+
+// ReviewStockAdjustmentRequest represents a manager's approval or rejection
+// of a pending adjustment
+type ReviewStockAdjustmentRequest struct {
+	Notes string `json:"notes" binding:"omitempty,max=1000"`
 }
 
 // ProductSummary represents a summary of product statistics
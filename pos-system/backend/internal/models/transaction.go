@@ -66,16 +66,21 @@ func (Transaction) TableName() string {
 
 // TransactionItem represents an item in a transaction
 type TransactionItem struct {
-	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	TransactionID uuid.UUID `json:"transactionId" gorm:"type:uuid;not null;index"`
-	ProductID     uuid.UUID `json:"productId" gorm:"type:uuid;not null;index"`
-	ProductName   string    `json:"productName" gorm:"not null"`
-	ProductSKU    string    `json:"productSku" gorm:"not null"`
-	Quantity      int       `json:"quantity" gorm:"not null;check:quantity > 0"`
-	UnitPrice     float64   `json:"unitPrice" gorm:"not null;check:unit_price >= 0"`
-	Discount      float64   `json:"discount" gorm:"not null;default:0;check:discount >= 0"`
-	Subtotal      float64   `json:"subtotal" gorm:"not null;check:subtotal >= 0"`
-	CreatedAt     time.Time `json:"createdAt" gorm:"not null;default:now()"`
+	ID               uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	TransactionID    uuid.UUID `json:"transactionId" gorm:"type:uuid;not null;index"`
+	ProductID        uuid.UUID `json:"productId" gorm:"type:uuid;not null;index"`
+	ProductName      string    `json:"productName" gorm:"not null"`
+	ProductSKU       string    `json:"productSku" gorm:"not null"`
+	Quantity         int       `json:"quantity" gorm:"not null;check:quantity > 0"`
+	UnitPrice        float64   `json:"unitPrice" gorm:"not null;check:unit_price >= 0"`
+	Discount         float64   `json:"discount" gorm:"not null;default:0;check:discount >= 0"`
+	Subtotal         float64   `json:"subtotal" gorm:"not null;check:subtotal >= 0"`
+	RefundedQuantity int       `json:"refundedQuantity" gorm:"not null;default:0;check:refunded_quantity >= 0"`
+	// UnitCost is the product's cost at the time of sale, kept so margins
+	// don't move when the cost changes later. It is empty on lines recorded
+	// before costs were captured.
+	UnitCost  *float64  `json:"-" gorm:"type:decimal(10,2);check:unit_cost >= 0"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null;default:now()"`
 
 	// Relationships
 	Transaction Transaction `json:"transaction,omitempty" gorm:"foreignKey:TransactionID"`
@@ -87,11 +92,18 @@ func (TransactionItem) TableName() string {
 	return "transaction_items"
 }
 
-// Payment represents a payment record
+// Payment status values
+const (
+	PaymentStatusCompleted = "COMPLETED"
+	PaymentStatusRefunded  = "REFUNDED"
+)
+
+// Payment represents a payment record. Refunds are stored as negative
+// payments against the original tender's method.
 type Payment struct {
 	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	TransactionID uuid.UUID     `json:"transactionId" gorm:"type:uuid;not null;index"`
-	Amount        float64       `json:"amount" gorm:"not null;check:amount > 0"`
+	Amount        float64       `json:"amount" gorm:"not null;check:amount <> 0"`
 	Method        PaymentMethod `json:"method" gorm:"type:payment_method;not null"`
 	Reference     *string       `json:"reference,omitempty"`
 	Status        string        `json:"status" gorm:"not null;default:'COMPLETED'"`
@@ -108,12 +120,24 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
-// Cart represents a shopping cart (for draft transactions)
+// CartStatus represents whether a cart is being worked on or parked
+type CartStatus string
+
+const (
+	CartStatusActive CartStatus = "ACTIVE"
+	CartStatusHeld   CartStatus = "HELD"
+)
+
+// Cart represents a shopping cart (for draft transactions). A cashier has at
+// most one active cart and any number of held ones.
 type Cart struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	CashierID uuid.UUID `json:"cashierId" gorm:"type:uuid;not null;index"`
-	CreatedAt time.Time `json:"createdAt" gorm:"not null;default:now()"`
-	UpdatedAt time.Time `json:"updatedAt" gorm:"not null;default:now()"`
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CashierID uuid.UUID  `json:"cashierId" gorm:"type:uuid;not null;index"`
+	Name      *string    `json:"name,omitempty" gorm:"type:varchar(100)"`
+	Status    CartStatus `json:"status" gorm:"type:varchar(20);not null;default:'ACTIVE';index"`
+	HeldAt    *time.Time `json:"heldAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"not null;default:now()"`
 
 	// Relationships
 	Cashier User       `json:"cashier,omitempty" gorm:"foreignKey:CashierID"`
@@ -132,6 +156,7 @@ type CartItem struct {
 	ProductID uuid.UUID `json:"productId" gorm:"type:uuid;not null;index"`
 	Quantity  int       `json:"quantity" gorm:"not null;check:quantity > 0"`
 	Discount  float64   `json:"discount" gorm:"not null;default:0;check:discount >= 0"`
+	UnitPrice float64   `json:"unitPrice" gorm:"not null;default:0;check:unit_price >= 0"` // price when last validated
 	CreatedAt time.Time `json:"createdAt" gorm:"not null;default:now()"`
 	UpdatedAt time.Time `json:"updatedAt" gorm:"not null;default:now()"`
 
@@ -145,6 +170,110 @@ func (CartItem) TableName() string {
 	return "cart_items"
 }
 
+// CartIssue codes reported when a cart is re-validated
+const (
+	CartIssuePriceChanged      = "PRICE_CHANGED"
+	CartIssueInsufficientStock = "INSUFFICIENT_STOCK"
+	CartIssueUnavailable       = "UNAVAILABLE"
+)
+
+// CartIssue describes a cart line that changed or cannot be sold as is
+type CartIssue struct {
+	ProductID   uuid.UUID `json:"productId"`
+	ProductName string    `json:"productName"`
+	Code        string    `json:"code"`
+	Message     string    `json:"message"`
+}
+
+// CartLine represents a cart item priced against current product data
+type CartLine struct {
+	CartItem
+	ProductName string  `json:"productName"`
+	ProductSKU  string  `json:"productSku"`
+	Available   int     `json:"available"`
+	Subtotal    float64 `json:"subtotal"`
+}
+
+// CartDetails represents a cart with live prices, totals and any issues
+// found while validating it
+type CartDetails struct {
+	Cart
+	Lines          []CartLine  `json:"lines"`
+	Subtotal       float64     `json:"subtotal"`
+	DiscountAmount float64     `json:"discountAmount"`
+	TaxAmount      float64     `json:"taxAmount"`
+	Total          float64     `json:"total"`
+	Issues         []CartIssue `json:"issues,omitempty"`
+}
+
+// CreateCartRequest represents the request to start a new cart
+type CreateCartRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,max=100"`
+}
+
+// UpdateCartRequest represents the request to rename a cart
+type UpdateCartRequest struct {
+	Name *string `json:"name" binding:"omitempty,max=100"`
+}
+
+// HoldCartRequest represents the request to park a cart
+type HoldCartRequest struct {
+	Name *string `json:"name,omitempty" binding:"omitempty,max=100"`
+}
+
+// AddCartItemRequest represents the request to add a product to a cart
+type AddCartItemRequest struct {
+	ProductID uuid.UUID `json:"productId" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,gt=0"`
+	Discount  *float64  `json:"discount,omitempty" binding:"omitempty,gte=0"`
+}
+
+// UpdateCartItemRequest represents the request to change a cart line
+type UpdateCartItemRequest struct {
+	Quantity int      `json:"quantity" binding:"required,gt=0"`
+	Discount *float64 `json:"discount,omitempty" binding:"omitempty,gte=0"`
+}
+
+// CheckoutCartRequest represents the request to turn a cart into a
+// transaction; the items come from the cart
+type CheckoutCartRequest struct {
+	CustomerName   *string                    `json:"customerName,omitempty" binding:"omitempty,max=100"`
+	CustomerEmail  *string                    `json:"customerEmail,omitempty" binding:"omitempty,email"`
+	CustomerPhone  *string                    `json:"customerPhone,omitempty" binding:"omitempty,max=20"`
+	DiscountAmount *float64                   `json:"discountAmount,omitempty" binding:"omitempty,gte=0"`
+	Payments       []CreateTransactionPayment `json:"payments,omitempty" binding:"omitempty,dive"`
+	PaymentMethod  PaymentMethod              `json:"paymentMethod,omitempty" binding:"required_without=Payments"`
+	AmountPaid     float64                    `json:"amountPaid,omitempty" binding:"required_without=Payments,omitempty,gt=0"`
+	PaymentRef     *string                    `json:"paymentRef,omitempty" binding:"omitempty,max=100"`
+	Notes          *string                    `json:"notes,omitempty" binding:"omitempty,max=500"`
+	EmailReceipt   *bool                      `json:"emailReceipt,omitempty"`
+}
+
+// ToTransactionRequest builds the checkout request for the given cart items
+func (r *CheckoutCartRequest) ToTransactionRequest(items []CartItem) *CreateTransactionRequest {
+	req := &CreateTransactionRequest{
+		CustomerName:   r.CustomerName,
+		CustomerEmail:  r.CustomerEmail,
+		CustomerPhone:  r.CustomerPhone,
+		DiscountAmount: r.DiscountAmount,
+		Payments:       r.Payments,
+		PaymentMethod:  r.PaymentMethod,
+		AmountPaid:     r.AmountPaid,
+		PaymentRef:     r.PaymentRef,
+		Notes:          r.Notes,
+		EmailReceipt:   r.EmailReceipt,
+	}
+	for _, item := range items {
+		discount := item.Discount
+		req.Items = append(req.Items, CreateTransactionItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Discount:  &discount,
+		})
+	}
+	return req
+}
+
 // TransactionWithDetails represents a transaction with cashier details
 type TransactionWithDetails struct {
 	Transaction `gorm:"embedded"`
@@ -158,10 +287,22 @@ type CreateTransactionRequest struct {
 	CustomerPhone  *string                 `json:"customerPhone,omitempty" binding:"omitempty,max=20"`
 	Items          []CreateTransactionItem `json:"items" binding:"required,min=1,dive"`
 	DiscountAmount *float64                `json:"discountAmount,omitempty" binding:"omitempty,gte=0"`
-	PaymentMethod  PaymentMethod           `json:"paymentMethod" binding:"required"`
-	AmountPaid     float64                 `json:"amountPaid" binding:"required,gt=0"`
-	PaymentRef     *string                 `json:"paymentRef,omitempty" binding:"omitempty,max=100"`
-	Notes          *string                 `json:"notes,omitempty" binding:"omitempty,max=500"`
+	// Payments lists the tenders for a split payment. When empty the single
+	// PaymentMethod/AmountPaid pair is used instead.
+	Payments      []CreateTransactionPayment `json:"payments,omitempty" binding:"omitempty,dive"`
+	PaymentMethod PaymentMethod              `json:"paymentMethod,omitempty" binding:"required_without=Payments"`
+	AmountPaid    float64                    `json:"amountPaid,omitempty" binding:"required_without=Payments,omitempty,gt=0"`
+	PaymentRef    *string                    `json:"paymentRef,omitempty" binding:"omitempty,max=100"`
+	Notes         *string                    `json:"notes,omitempty" binding:"omitempty,max=500"`
+	// EmailReceipt set to false skips emailing the receipt to CustomerEmail
+	EmailReceipt *bool `json:"emailReceipt,omitempty"`
+}
+
+// CreateTransactionPayment represents one tender of a split payment
+type CreateTransactionPayment struct {
+	Method    PaymentMethod `json:"method" binding:"required"`
+	Amount    float64       `json:"amount" binding:"required,gt=0"`
+	Reference *string       `json:"reference,omitempty" binding:"omitempty,max=100"`
 }
 
 // CreateTransactionItem represents an item in the create transaction request
@@ -184,6 +325,14 @@ type RefundTransactionItem struct {
 	Quantity  int       `json:"quantity" binding:"required,gt=0"`
 }
 
+// RefundResult represents the outcome of a refund
+type RefundResult struct {
+	Transaction      *Transaction `json:"transaction"`
+	RefundAmount     float64      `json:"refundAmount"`
+	RemainingBalance float64      `json:"remainingBalance"`
+	Payments         []Payment    `json:"payments"`
+}
+
 // TransactionFilters represents filters for transaction queries
 type TransactionFilters struct {
 	CashierID     *uuid.UUID         `json:"cashierId,omitempty"`
@@ -212,6 +361,43 @@ type DailySales struct {
 	PaymentMethods     map[string]float64 `json:"paymentMethods"`
 }
 
+// ReportGroupBy sets the period length of a sales report's series
+type ReportGroupBy string
+
+const (
+	ReportGroupByDay   ReportGroupBy = "day"
+	ReportGroupByWeek  ReportGroupBy = "week"
+	ReportGroupByMonth ReportGroupBy = "month"
+)
+
+// IsValid reports whether g is a known grouping
+func (g ReportGroupBy) IsValid() bool {
+	switch g {
+	case ReportGroupByDay, ReportGroupByWeek, ReportGroupByMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// SalesPeriod summarises the sales of one day, week or month. Refunds are
+// counted against the period of the original sale.
+type SalesPeriod struct {
+	Date             time.Time          `json:"date"` // Start of the period
+	TransactionCount int                `json:"transactionCount"`
+	ItemsSold        int                `json:"itemsSold"`
+	GrossSales       float64            `json:"grossSales"`
+	Refunds          float64            `json:"refunds"`
+	TotalSales       float64            `json:"totalSales"` // Gross sales less refunds
+	TotalTax         float64            `json:"totalTax"`
+	TotalDiscount    float64            `json:"totalDiscount"`
+	NetRevenue       float64            `json:"netRevenue"` // Total sales excluding tax
+	TotalCost        float64            `json:"totalCost"`
+	GrossProfit      float64            `json:"grossProfit"`
+	GrossMargin      float64            `json:"grossMargin"` // Percentage of net revenue
+	PaymentMethods   map[string]float64 `json:"paymentMethods"`
+}
+
 // ProductSales represents product sales summary
 type ProductSales struct {
 	ProductID        uuid.UUID `json:"productId"`
@@ -219,6 +405,8 @@ type ProductSales struct {
 	ProductSKU       string    `json:"productSku"`
 	TotalQuantity    int       `json:"totalQuantity"`
 	TotalRevenue     float64   `json:"totalRevenue"`
+	TotalCost        float64   `json:"totalCost,omitempty"`
+	GrossProfit      float64   `json:"grossProfit,omitempty"`
 	TransactionCount int       `json:"transactionCount"`
 }
 
@@ -246,9 +434,12 @@ type TransactionSummary struct {
 type Receipt struct {
 	Transaction   Transaction `json:"transaction"`
 	CompanyInfo   CompanyInfo `json:"companyInfo"`
+	Header        *string     `json:"header,omitempty"`
+	Footer        *string     `json:"footer,omitempty"`
+	Currency      string      `json:"currency"`
 	FormattedDate string      `json:"formattedDate"`
 	FormattedTime string      `json:"formattedTime"`
-	QRCode        *string     `json:"qrCode,omitempty"`
+	QRCode        *string     `json:"qrCode,omitempty"` // Data encoded in the receipt's QR code
 }
 
 // CompanyInfo represents company information for receipts
@@ -263,9 +454,30 @@ type CompanyInfo struct {
 
 // Helper methods for Transaction model
 
-// IsRefundable checks if transaction can be refunded
+// IsRefundable checks if transaction can be refunded. Partially refunded
+// transactions stay completed until every item has been returned.
 func (t *Transaction) IsRefundable() bool {
-	return t.Status == TransactionStatusCompleted && t.RefundedAt == nil
+	return t.Status == TransactionStatusCompleted
+}
+
+// GetRefundedAmount returns the total already refunded (requires Payments)
+func (t *Transaction) GetRefundedAmount() float64 {
+	refunded := 0.0
+	for _, payment := range t.Payments {
+		if payment.Amount < 0 {
+			refunded -= payment.Amount
+		}
+	}
+	return refunded
+}
+
+// GetRefundableBalance returns how much of the total can still be refunded
+func (t *Transaction) GetRefundableBalance() float64 {
+	balance := t.Total - t.GetRefundedAmount()
+	if balance < 0 {
+		return 0
+	}
+	return balance
 }
 
 // IsCompleted checks if transaction is completed
@@ -321,6 +533,11 @@ func ValidatePaymentMethod(method string) bool {
 
 // Helper methods for TransactionItem model
 
+// GetRefundableQuantity returns how many units have not been refunded yet
+func (ti *TransactionItem) GetRefundableQuantity() int {
+	return ti.Quantity - ti.RefundedQuantity
+}
+
 // GetTotalWithDiscount calculates item total including discount
 func (ti *TransactionItem) GetTotalWithDiscount() float64 {
 	return ti.Subtotal - ti.Discount
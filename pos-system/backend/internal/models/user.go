@@ -77,6 +77,12 @@ type Session struct {
 	CreatedAt    time.Time `json:"createdAt" gorm:"not null;default:now()"`
 	UpdatedAt    time.Time `json:"updatedAt" gorm:"not null;default:now()"`
 
+	// FamilyID is shared by every session descended from one login through
+	// refresh token rotation. RotatedAt is set once the session's token has
+	// been exchanged; presenting it again means it was stolen.
+	FamilyID  uuid.UUID  `json:"familyId" gorm:"type:uuid;index"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -86,19 +92,126 @@ func (Session) TableName() string {
 	return "sessions"
 }
 
+// RevokedToken records an access token revocation so that every server
+// instance rejects it: a single token when JTI is set, otherwise every
+// token issued to the user at or before IssuedBefore. The row can be
+// deleted after ExpiresAt, once the tokens it covers have expired.
+type RevokedToken struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID       uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	JTI          *string    `json:"jti,omitempty" gorm:"uniqueIndex"`
+	IssuedBefore *time.Time `json:"issuedBefore,omitempty"`
+	ExpiresAt    time.Time  `json:"expiresAt" gorm:"not null;index"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// Login throttle scopes
+const (
+	LoginThrottleAccount = "account" // identified by lower-cased email
+	LoginThrottleIP      = "ip"
+)
+
+// LoginThrottle counts recent failed sign-ins for an account or a client IP
+type LoginThrottle struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Scope         string     `json:"scope" gorm:"not null;uniqueIndex:idx_login_throttles_scope_identifier"`
+	Identifier    string     `json:"identifier" gorm:"not null;uniqueIndex:idx_login_throttles_scope_identifier"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"lastFailureAt" gorm:"not null;default:now()"`
+	LockedUntil   *time.Time `json:"lockedUntil,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt     time.Time  `json:"updatedAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
+
+// TwoFactor holds a user's TOTP authenticator. It is created disabled when
+// enrollment starts and enabled once the user proves their app generates
+// codes for Secret. LastUsedStep is the time step of the last code accepted,
+// so each code works only once.
+type TwoFactor struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID       uuid.UUID  `json:"userId" gorm:"type:uuid;not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"not null"`
+	Enabled      bool       `json:"enabled" gorm:"not null;default:false"`
+	EnabledAt    *time.Time `json:"enabledAt,omitempty"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt    time.Time  `json:"updatedAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (TwoFactor) TableName() string {
+	return "two_factors"
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the
+// authenticator is lost. Only its hash is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (RecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+// TwoFactorChallenge is the second step of a login that needs a TOTP code.
+// Its token is handed out once the password has been checked and is
+// exchanged, with a code, for the session tokens.
+type TwoFactorChallenge struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID `json:"userId" gorm:"type:uuid;not null;index"`
+	TokenHash string    `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"not null;index"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (TwoFactorChallenge) TableName() string {
+	return "two_factor_challenges"
+}
+
+// TwoFactorPolicy records whether users with Role must sign in with two
+// factors. Roles without a row are not required to.
+type TwoFactorPolicy struct {
+	Role      Role      `json:"role" gorm:"type:user_role;primary_key"`
+	Required  bool      `json:"required" gorm:"not null;default:false"`
+	UpdatedBy uuid.UUID `json:"updatedBy" gorm:"type:uuid;not null"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for GORM
+func (TwoFactorPolicy) TableName() string {
+	return "two_factor_policies"
+}
+
 // Password represents password-based authentication
 type Password struct {
-	ID                     uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	UserID                 uuid.UUID  `json:"userId" gorm:"type:uuid;not null;uniqueIndex"`
-	HashedPassword         string     `json:"-" gorm:"not null"`
-	ResetToken             *string    `json:"-"`
-	ResetTokenExpiresAt    *time.Time `json:"-"`
-	EmailVerificationToken *string    `json:"-"`
-	EmailVerified          bool       `json:"emailVerified" gorm:"not null;default:false"`
-	EmailVerifiedAt        *time.Time `json:"emailVerifiedAt,omitempty"`
-	LastPasswordChange     time.Time  `json:"lastPasswordChange" gorm:"not null;default:now()"`
-	CreatedAt              time.Time  `json:"createdAt" gorm:"not null;default:now()"`
-	UpdatedAt              time.Time  `json:"updatedAt" gorm:"not null;default:now()"`
+	ID                      uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID                  uuid.UUID  `json:"userId" gorm:"type:uuid;not null;uniqueIndex"`
+	HashedPassword          string     `json:"-" gorm:"not null"`
+	ResetToken              *string    `json:"-"`
+	ResetTokenExpiresAt     *time.Time `json:"-"`
+	EmailVerificationToken  *string    `json:"-"`
+	EmailVerificationSentAt *time.Time `json:"-"`
+	EmailVerified           bool       `json:"emailVerified" gorm:"not null;default:false"`
+	EmailVerifiedAt         *time.Time `json:"emailVerifiedAt,omitempty"`
+	LastPasswordChange      time.Time  `json:"lastPasswordChange" gorm:"not null;default:now()"`
+	CreatedAt               time.Time  `json:"createdAt" gorm:"not null;default:now()"`
+	UpdatedAt               time.Time  `json:"updatedAt" gorm:"not null;default:now()"`
 
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -157,6 +270,64 @@ type ConfirmResetPasswordRequest struct {
 	NewPassword string `json:"newPassword" binding:"required,min=8"`
 }
 
+// VerifyEmailRequest represents the request to confirm an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request to resend the
+// verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyTwoFactorRequest completes a login with the challenge token from
+// Login and either a TOTP code or a recovery code
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// SetupTwoFactorRequest starts enrollment during a login that requires two
+// factors for a user who has none yet
+type SetupTwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code, or a recovery code where one is
+// accepted, to confirm a two-factor change
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// UpdateTwoFactorPolicyRequest represents the request to require two-factor
+// authentication for a role
+type UpdateTwoFactorPolicyRequest struct {
+	Role     Role  `json:"role" binding:"required"`
+	Required *bool `json:"required" binding:"required"`
+}
+
+// TwoFactorEnrollment is the secret to add to an authenticator app, as text
+// and as an otpauth:// URI for a QR code
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// TwoFactorStatus describes a user's two-factor authentication
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recoveryCodesRemaining"`
+}
+
+// RecoveryCodesResponse returns newly issued recovery codes. They are shown
+// this once and only their hashes are kept.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
 // LoginRequest represents the login request
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -194,9 +365,23 @@ type RegisterRequest struct {
 // AuthResponse represents the authentication response
 type AuthResponse struct {
 	User         User   `json:"user"`
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken"`
-	ExpiresIn    int    `json:"expiresIn"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn,omitempty"`
+
+	// VerificationRequired is set when no tokens are issued until the user
+	// confirms their email address
+	VerificationRequired bool `json:"verificationRequired,omitempty"`
+
+	// TwoFactorRequired is set when the password was accepted and the
+	// challenge token must be sent with a code to finish signing in.
+	// TwoFactorSetupRequired means the user must enroll an authenticator
+	// first. RecoveryCodes are returned once, when enrollment completes.
+	TwoFactorRequired      bool     `json:"twoFactorRequired,omitempty"`
+	TwoFactorSetupRequired bool     `json:"twoFactorSetupRequired,omitempty"`
+	ChallengeToken         string   `json:"challengeToken,omitempty"`
+	ChallengeExpiresIn     int      `json:"challengeExpiresIn,omitempty"`
+	RecoveryCodes          []string `json:"recoveryCodes,omitempty"`
 }
 
 // UpdateProfileRequest represents the request to update user profile
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new GORM-backed account repository
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) Create(ctx context.Context, account *models.Account) error {
+	return conn(ctx, r.db).Create(account).Error
+}
+
+func (r *accountRepository) GetByProviderAndAccountID(ctx context.Context, provider, providerAccountID string) (*models.Account, error) {
+	var account models.Account
+	err := conn(ctx, r.db).
+		Where("provider = ? AND provider_account_id = ?", provider, providerAccountID).
+		First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *accountRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]models.Account, error) {
+	var accounts []models.Account
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *accountRepository) Update(ctx context.Context, account *models.Account) error {
+	return conn(ctx, r.db).Save(account).Error
+}
+
+func (r *accountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&models.Account{}, "id = ?", id).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var auditLogSortColumns = map[string]string{
+	"action":    "action",
+	"resource":  "resource",
+	"userName":  "user_name",
+	"timestamp": "timestamp",
+}
+
+var auditLogFilterColumns = map[string]string{
+	"user_id":     "user_id",
+	"userId":      "user_id",
+	"user_role":   "user_role",
+	"action":      "action",
+	"resource":    "resource",
+	"resource_id": "resource_id",
+	"resourceId":  "resource_id",
+	"ip_address":  "ip_address",
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new GORM-backed audit log repository
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	// ip_address is a NOT NULL inet column; callers without request context
+	// (background jobs, services) leave it empty
+	if log.IPAddress == "" {
+		log.IPAddress = "0.0.0.0"
+	}
+	return conn(ctx, r.db).Omit(clause.Associations).Create(log).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.AuditLog, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.AuditLog{}), filters, auditLogFilterColumns, "timestamp")
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("user_name ILIKE ? OR resource ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	if err := paginate(query, pagination, auditLogSortColumns, "timestamp").Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+func (r *auditLogRepository) GetByUserID(ctx context.Context, userID uuid.UUID, pagination *models.PaginationQuery) ([]models.AuditLog, int64, error) {
+	return r.List(ctx, map[string]interface{}{"user_id": userID}, pagination)
+}
+
+func (r *auditLogRepository) GetByResource(ctx context.Context, resource string, resourceID string, pagination *models.PaginationQuery) ([]models.AuditLog, int64, error) {
+	filters := map[string]interface{}{"resource": resource}
+	if resourceID != "" {
+		filters["resource_id"] = resourceID
+	}
+	return r.List(ctx, filters, pagination)
+}
+
+func (r *auditLogRepository) DeleteOldLogs(ctx context.Context, beforeDate time.Time) error {
+	return conn(ctx, r.db).Where("timestamp < ?", beforeDate).Delete(&models.AuditLog{}).Error
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+// NewCartRepository creates a new GORM-backed cart repository
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+func (r *cartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	return conn(ctx, r.db).Omit("Cashier").Create(cart).Error
+}
+
+func (r *cartRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Cart, error) {
+	var cart models.Cart
+	if err := r.withItems(conn(ctx, r.db)).First(&cart, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetByCashierID returns the cashier's most recently updated cart
+func (r *cartRepository) GetByCashierID(ctx context.Context, cashierID uuid.UUID) (*models.Cart, error) {
+	var cart models.Cart
+	err := r.withItems(conn(ctx, r.db)).
+		Where("cashier_id = ?", cashierID).
+		Order("updated_at DESC").
+		First(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// ListByCashierID returns the cashier's carts, most recently updated first,
+// optionally limited to one status
+func (r *cartRepository) ListByCashierID(ctx context.Context, cashierID uuid.UUID, status *models.CartStatus) ([]models.Cart, error) {
+	query := r.withItems(conn(ctx, r.db)).Where("cashier_id = ?", cashierID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var carts []models.Cart
+	err := query.Order("updated_at DESC").Find(&carts).Error
+	return carts, err
+}
+
+// DeleteHeldBefore removes held carts that have not been touched since
+// before, returning how many were removed
+func (r *cartRepository) DeleteHeldBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+		stale := db.Model(&models.Cart{}).Select("id").
+			Where("status = ? AND updated_at < ?", models.CartStatusHeld, before)
+		if err := db.Where("cart_id IN (?)", stale).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		result := db.Where("status = ? AND updated_at < ?", models.CartStatusHeld, before).Delete(&models.Cart{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// withItems preloads cart items in the order they were added
+func (r *cartRepository) withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") })
+}
+
+func (r *cartRepository) Update(ctx context.Context, cart *models.Cart) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(cart).Error
+}
+
+func (r *cartRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+		if err := db.Delete(&models.CartItem{}, "cart_id = ?", id).Error; err != nil {
+			return err
+		}
+		return db.Delete(&models.Cart{}, "id = ?", id).Error
+	})
+}
+
+// Clear removes all items from every cart owned by the cashier
+func (r *cartRepository) Clear(ctx context.Context, cashierID uuid.UUID) error {
+	cartIDs := conn(ctx, r.db).Model(&models.Cart{}).Select("id").Where("cashier_id = ?", cashierID)
+	return conn(ctx, r.db).Where("cart_id IN (?)", cartIDs).Delete(&models.CartItem{}).Error
+}
+
+// AddItem adds a product to the cart, merging quantities when the product is
+// already present
+func (r *cartRepository) AddItem(ctx context.Context, cartID uuid.UUID, item *models.CartItem) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+
+		var existing models.CartItem
+		err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("cart_id = ? AND product_id = ?", cartID, item.ProductID).
+			First(&existing).Error
+		switch {
+		case err == nil:
+			existing.Quantity += item.Quantity
+			existing.Discount += item.Discount
+			existing.UnitPrice = item.UnitPrice
+			if err := db.Omit(clause.Associations).Save(&existing).Error; err != nil {
+				return err
+			}
+			*item = existing
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			item.CartID = cartID
+			if err := db.Omit(clause.Associations).Create(item).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return r.touch(ctx, cartID)
+	})
+}
+
+func (r *cartRepository) UpdateItem(ctx context.Context, cartID uuid.UUID, item *models.CartItem) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		result := conn(ctx, r.db).
+			Model(&models.CartItem{}).
+			Where("cart_id = ? AND product_id = ?", cartID, item.ProductID).
+			Updates(map[string]interface{}{
+				"quantity":   item.Quantity,
+				"discount":   item.Discount,
+				"unit_price": item.UnitPrice,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return r.touch(ctx, cartID)
+	})
+}
+
+func (r *cartRepository) RemoveItem(ctx context.Context, cartID uuid.UUID, productID uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		result := conn(ctx, r.db).Delete(&models.CartItem{}, "cart_id = ? AND product_id = ?", cartID, productID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return r.touch(ctx, cartID)
+	})
+}
+
+// touch bumps the cart's updated_at so recency ordering reflects item edits
+func (r *cartRepository) touch(ctx context.Context, cartID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&models.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now()).Error
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var categorySortColumns = map[string]string{
+	"name":       "name",
+	"sort_order": "sort_order",
+	"sortOrder":  "sort_order",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+	"updated_at": "updated_at",
+	"updatedAt":  "updated_at",
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository creates a new GORM-backed category repository
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(category).Error
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	if err := conn(ctx, r.db).Preload("Parent").First(&category, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetByName(ctx context.Context, name string) (*models.Category, error) {
+	var category models.Category
+	if err := conn(ctx, r.db).First(&category, "LOWER(name) = LOWER(?)", name).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(category).Error
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.Category{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *categoryRepository) List(ctx context.Context, pagination *models.PaginationQuery) ([]models.Category, int64, error) {
+	query := conn(ctx, r.db).Model(&models.Category{})
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("name ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var categories []models.Category
+	if err := paginate(query, pagination, categorySortColumns, "sort_order").Find(&categories).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return categories, total, nil
+}
+
+// ListWithProductCount lists categories with the number of products in each
+func (r *categoryRepository) ListWithProductCount(ctx context.Context, pagination *models.PaginationQuery) ([]models.CategoryWithProductCount, int64, error) {
+	categories, total, err := r.List(ctx, pagination)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uuid.UUID, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+
+	var rows []struct {
+		CategoryID uuid.UUID
+		Count      int
+	}
+	if len(ids) > 0 {
+		err = conn(ctx, r.db).Model(&models.Product{}).
+			Select("category_id, COUNT(*) AS count").
+			Where("category_id IN ?", ids).
+			Group("category_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+
+	result := make([]models.CategoryWithProductCount, len(categories))
+	for i, category := range categories {
+		result[i] = models.CategoryWithProductCount{
+			Category:     category,
+			ProductCount: counts[category.ID],
+		}
+	}
+	return result, total, nil
+}
+
+func (r *categoryRepository) GetWithProducts(ctx context.Context, id uuid.UUID) (*models.CategoryWithProducts, error) {
+	category, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	err = conn(ctx, r.db).
+		Where("category_id = ?", id).
+		Order("name ASC").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CategoryWithProducts{
+		Category: *category,
+		Products: products,
+	}, nil
+}
+
+// CountProducts returns how many products reference the category
+func (r *categoryRepository) CountProducts(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Product{}).Where("category_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CountChildren returns how many categories have this one as their parent
+func (r *categoryRepository) CountChildren(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// GetTree returns root categories with their descendants nested in Children
+func (r *categoryRepository) GetTree(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	if err := conn(ctx, r.db).Order("sort_order ASC, name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[uuid.UUID][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			byParent[*category.ParentID] = append(byParent[*category.ParentID], category)
+		}
+	}
+
+	return buildCategoryTree(roots, byParent, make(map[uuid.UUID]bool)), nil
+}
+
+// buildCategoryTree attaches children recursively; visited guards against
+// cycles introduced by bad parent assignments
+func buildCategoryTree(nodes []models.Category, byParent map[uuid.UUID][]models.Category, visited map[uuid.UUID]bool) []models.Category {
+	for i := range nodes {
+		if visited[nodes[i].ID] {
+			continue
+		}
+		visited[nodes[i].ID] = true
+		nodes[i].Children = buildCategoryTree(byParent[nodes[i].ID], byParent, visited)
+	}
+	return nodes
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidAction     = errors.New("invalid action for current status")
+)
+
+type txContextKey struct{}
+
+type afterCommitKey struct{}
+
+// afterCommitHooks collects the functions to run once a transaction commits
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// ContextWithTx returns a context that makes repositories run their queries
+// inside the given GORM transaction instead of on the root connection
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored in ctx, if any
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// RunInTx executes fn inside a database transaction. If ctx already carries a
+// transaction it is reused so nested calls join the outer unit of work.
+// Functions passed to AfterCommit run once the outermost transaction has
+// committed.
+func RunInTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	hooks := &afterCommitHooks{}
+	ctx = context.WithValue(ctx, afterCommitKey{}, hooks)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ContextWithTx(ctx, tx))
+	})
+	if err != nil {
+		return err
+	}
+	for _, fn := range hooks.fns {
+		fn()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction in ctx commits, and not at all if
+// it rolls back. Outside a transaction fn runs straight away. Use it for
+// side effects outside the database, such as in-memory state, that must not
+// happen for changes that are undone.
+func AfterCommit(ctx context.Context, fn func()) {
+	if _, ok := TxFromContext(ctx); ok {
+		if hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+			hooks.fns = append(hooks.fns, fn)
+			return
+		}
+	}
+	fn()
+}
+
+// conn returns the connection a repository should use for ctx
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// paginate applies sorting, offset and limit to a query. Only sort keys listed
+// in sortable are honoured; keys may be given either as the JSON field name or
+// the column name. Unknown keys fall back to defaultSort.
+func paginate(query *gorm.DB, pagination *models.PaginationQuery, sortable map[string]string, defaultSort string) *gorm.DB {
+	if pagination == nil {
+		pagination = &models.PaginationQuery{}
+	}
+
+	column, ok := sortable[pagination.GetSort()]
+	if !ok {
+		column = defaultSort
+	}
+
+	return query.
+		Order(column + " " + pagination.GetOrder()).
+		Offset(pagination.GetSkip()).
+		Limit(pagination.GetLimit())
+}
+
+// applyFilters adds equality conditions for each filter key listed in columns
+// and range conditions on dateColumn for the "start_date" and "end_date" keys.
+// Unknown keys are ignored so callers can pass raw query maps safely.
+func applyFilters(query *gorm.DB, filters map[string]interface{}, columns map[string]string, dateColumn string) *gorm.DB {
+	for key, value := range filters {
+		if value == nil {
+			continue
+		}
+
+		switch key {
+		case "start_date":
+			if dateColumn != "" {
+				if t, ok := toTime(value); ok {
+					query = query.Where(dateColumn+" >= ?", t)
+				}
+			}
+		case "end_date":
+			if dateColumn != "" {
+				if t, ok := toTime(value); ok {
+					query = query.Where(dateColumn+" <= ?", t)
+				}
+			}
+		default:
+			if column, ok := columns[key]; ok {
+				query = query.Where(column+" = ?", value)
+			}
+		}
+	}
+	return query
+}
+
+// toTime accepts time values or RFC3339 / YYYY-MM-DD strings
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, true
+		}
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// searchPattern wraps a search term for use with ILIKE
+func searchPattern(term string) string {
+	return "%" + term + "%"
+}
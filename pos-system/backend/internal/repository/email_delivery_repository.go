@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type emailDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailDeliveryRepository creates a new GORM-backed email delivery repository
+func NewEmailDeliveryRepository(db *gorm.DB) EmailDeliveryRepository {
+	return &emailDeliveryRepository{db: db}
+}
+
+func (r *emailDeliveryRepository) Create(ctx context.Context, delivery *models.EmailDelivery) error {
+	return conn(ctx, r.db).Create(delivery).Error
+}
+
+func (r *emailDeliveryRepository) Update(ctx context.Context, delivery *models.EmailDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	return conn(ctx, r.db).Save(delivery).Error
+}
+
+func (r *emailDeliveryRepository) ListByTransaction(ctx context.Context, transactionID uuid.UUID) ([]models.EmailDelivery, error) {
+	var deliveries []models.EmailDelivery
+	err := conn(ctx, r.db).
+		Where("transaction_id = ?", transactionID).
+		Order("created_at DESC").
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDue returns up to limit pending deliveries whose next attempt is due
+// and pushes their next attempt back by lease, so another worker polling in
+// the meantime skips them. Rows locked by a concurrent claim are skipped.
+func (r *emailDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.EmailDelivery, error) {
+	var deliveries []models.EmailDelivery
+	err := RunInTx(ctx, r.db, func(ctx context.Context) error {
+		err := conn(ctx, r.db).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		leaseUntil := now.Add(lease)
+		return conn(ctx, r.db).Model(&models.EmailDelivery{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"next_attempt_at": leaseUntil, "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var expenseSortColumns = map[string]string{
+	"title":      "title",
+	"amount":     "amount",
+	"category":   "category",
+	"date":       "date",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+	"updated_at": "updated_at",
+	"updatedAt":  "updated_at",
+}
+
+var expenseFilterColumns = map[string]string{
+	"category":    "category",
+	"status":      "status",
+	"created_by":  "created_by",
+	"createdBy":   "created_by",
+	"approved_by": "approved_by",
+	"approvedBy":  "approved_by",
+}
+
+type expenseRepository struct {
+	db *gorm.DB
+}
+
+// NewExpenseRepository creates a new GORM-backed expense repository
+func NewExpenseRepository(db *gorm.DB) ExpenseRepository {
+	return &expenseRepository{db: db}
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *models.Expense) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(expense).Error
+}
+
+func (r *expenseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	var expense models.Expense
+	err := conn(ctx, r.db).
+		Preload("CreatedByUser").
+		Preload("ApprovedByUser").
+		Preload("RejectedByUser").
+		First(&expense, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// GetByIDForUpdate loads an expense and locks its row until the surrounding
+// transaction ends; ctx must carry a transaction
+func (r *expenseRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	var expense models.Expense
+	err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&expense, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *expenseRepository) Update(ctx context.Context, expense *models.Expense) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(expense).Error
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.Expense{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *expenseRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.Expense, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.Expense{}), filters, expenseFilterColumns, "date")
+
+	if approved, ok := filters["approved"].(bool); ok {
+		if approved {
+			query = query.Where("approved_at IS NOT NULL")
+		} else {
+			query = query.Where("approved_at IS NULL")
+		}
+	}
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("title ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var expenses []models.Expense
+	err := paginate(query, pagination, expenseSortColumns, "date").
+		Preload("CreatedByUser").
+		Find(&expenses).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return expenses, total, nil
+}
+
+func (r *expenseRepository) GetByCategory(ctx context.Context, category models.ExpenseCategory, startDate, endDate time.Time) ([]models.Expense, error) {
+	var expenses []models.Expense
+	err := conn(ctx, r.db).
+		Where("category = ? AND date BETWEEN ? AND ? AND status <> ?", category, startDate, endDate, models.ExpenseStatusRejected).
+		Order("date ASC").
+		Find(&expenses).Error
+	return expenses, err
+}
+
+func (r *expenseRepository) GetTotalByPeriod(ctx context.Context, startDate, endDate time.Time) (float64, error) {
+	var total float64
+	err := conn(ctx, r.db).
+		Model(&models.Expense{}).
+		Where("date BETWEEN ? AND ? AND status <> ?", startDate, endDate, models.ExpenseStatusRejected).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// Approve marks a pending expense as approved by the given user
+func (r *expenseRepository) Approve(ctx context.Context, id uuid.UUID, approvedBy uuid.UUID) error {
+	now := time.Now()
+	result := conn(ctx, r.db).
+		Model(&models.Expense{}).
+		Where("id = ? AND status = ?", id, models.ExpenseStatusPending).
+		Updates(map[string]interface{}{
+			"status":      models.ExpenseStatusApproved,
+			"approved_by": approvedBy,
+			"approved_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Reject marks a pending expense as rejected by the given user
+func (r *expenseRepository) Reject(ctx context.Context, id uuid.UUID, rejectedBy uuid.UUID, reason string) error {
+	now := time.Now()
+	result := conn(ctx, r.db).
+		Model(&models.Expense{}).
+		Where("id = ? AND status = ?", id, models.ExpenseStatusPending).
+		Updates(map[string]interface{}{
+			"status":           models.ExpenseStatusRejected,
+			"rejected_by":      rejectedBy,
+			"rejected_at":      now,
+			"rejection_reason": reason,
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+// exportBatchSize is how many records an export loads per query
+const exportBatchSize = 500
+
+var exportTransactionFilterColumns = map[string]string{
+	"status":         "status",
+	"payment_method": "payment_method",
+	"paymentMethod":  "payment_method",
+	"cashier_id":     "cashier_id",
+	"cashierId":      "cashier_id",
+}
+
+var exportProductFilterColumns = map[string]string{
+	"category_id": "category_id",
+	"categoryId":  "category_id",
+	"status":      "status",
+	"is_active":   "is_active",
+	"isActive":    "is_active",
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+// NewExportRepository creates a new GORM-backed export repository
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+func (r *exportRepository) EachTransaction(ctx context.Context, filters map[string]interface{}, fn func([]models.Transaction) error) error {
+	var batch []models.Transaction
+	query := applyFilters(conn(ctx, r.db).Model(&models.Transaction{}), filters, exportTransactionFilterColumns, "created_at").
+		Preload("Items").
+		Preload("Cashier")
+	return findInBatches(query, &batch, func() error { return fn(batch) })
+}
+
+func (r *exportRepository) EachProduct(ctx context.Context, filters map[string]interface{}, fn func([]models.Product) error) error {
+	var batch []models.Product
+	query := applyFilters(conn(ctx, r.db).Model(&models.Product{}), filters, exportProductFilterColumns, "created_at").
+		Preload("Category")
+	return findInBatches(query, &batch, func() error { return fn(batch) })
+}
+
+func (r *exportRepository) EachStockMovement(ctx context.Context, filters map[string]interface{}, fn func([]models.StockMovement) error) error {
+	var batch []models.StockMovement
+	query := applyFilters(conn(ctx, r.db).Model(&models.StockMovement{}), filters, stockMovementFilterColumns, "created_at").
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+	return findInBatches(query, &batch, func() error { return fn(batch) })
+}
+
+func (r *exportRepository) EachExpense(ctx context.Context, filters map[string]interface{}, fn func([]models.Expense) error) error {
+	var batch []models.Expense
+	query := applyFilters(conn(ctx, r.db).Model(&models.Expense{}), filters, expenseFilterColumns, "date").
+		Preload("CreatedByUser")
+	return findInBatches(query, &batch, func() error { return fn(batch) })
+}
+
+func (r *exportRepository) EachAuditLog(ctx context.Context, filters map[string]interface{}, fn func([]models.AuditLog) error) error {
+	var batch []models.AuditLog
+	query := applyFilters(conn(ctx, r.db).Model(&models.AuditLog{}), filters, auditLogFilterColumns, "timestamp")
+	return findInBatches(query, &batch, func() error { return fn(batch) })
+}
+
+// findInBatches loads the query's records into dest a batch at a time,
+// calling fn after each batch is loaded
+func findInBatches(query *gorm.DB, dest interface{}, fn func() error) error {
+	return query.FindInBatches(dest, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		return fn()
+	}).Error
+}
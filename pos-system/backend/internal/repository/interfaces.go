@@ -36,11 +36,13 @@ type AccountRepository interface {
 type SessionRepository interface {
 	Create(ctx context.Context, session *models.Session) error
 	GetByToken(ctx context.Context, token string) (*models.Session, error)
+	FindByToken(ctx context.Context, token string) (*models.Session, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
 	Update(ctx context.Context, session *models.Session) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
 	RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
 }
 
 // PasswordRepository defines the interface for password operations
@@ -52,12 +54,14 @@ type PasswordRepository interface {
 	SetResetToken(ctx context.Context, userID uuid.UUID, token string, expiresAt time.Time) error
 	ValidateResetToken(ctx context.Context, token string) (*models.Password, error)
 	ClearResetToken(ctx context.Context, userID uuid.UUID) error
+	GetByEmailVerificationToken(ctx context.Context, token string) (*models.Password, error)
 }
 
 // ProductRepository defines the interface for product data operations
 type ProductRepository interface {
 	Create(ctx context.Context, product *models.Product) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Product, error)
 	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
 	GetByBarcode(ctx context.Context, barcode string) (*models.Product, error)
 	Update(ctx context.Context, product *models.Product) error
@@ -66,6 +70,7 @@ type ProductRepository interface {
 	UpdateStock(ctx context.Context, id uuid.UUID, quantity int, reason string, userID uuid.UUID) error
 	GetLowStock(ctx context.Context, threshold int) ([]models.Product, error)
 	GetOutOfStock(ctx context.Context) ([]models.Product, error)
+	GetActive(ctx context.Context) ([]models.Product, error)
 	BulkUpdateStock(ctx context.Context, updates []models.BulkStockUpdate, userID uuid.UUID) error
 }
 
@@ -77,21 +82,30 @@ type CategoryRepository interface {
 	Update(ctx context.Context, category *models.Category) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, pagination *models.PaginationQuery) ([]models.Category, int64, error)
+	ListWithProductCount(ctx context.Context, pagination *models.PaginationQuery) ([]models.CategoryWithProductCount, int64, error)
 	GetWithProducts(ctx context.Context, id uuid.UUID) (*models.CategoryWithProducts, error)
 	GetTree(ctx context.Context) ([]models.Category, error)
+	CountProducts(ctx context.Context, id uuid.UUID) (int64, error)
+	CountChildren(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 // TransactionRepository defines the interface for transaction data operations
 type TransactionRepository interface {
 	Create(ctx context.Context, transaction *models.Transaction) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Transaction, error)
 	GetByReceiptID(ctx context.Context, receiptID string) (*models.Transaction, error)
 	Update(ctx context.Context, transaction *models.Transaction) error
+	UpdateItem(ctx context.Context, item *models.TransactionItem) error
+	CreatePayment(ctx context.Context, payment *models.Payment) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filters *models.TransactionFilters, pagination *models.PaginationQuery) ([]models.Transaction, int64, error)
 	GetDailySales(ctx context.Context, date time.Time) (*models.DailySales, error)
-	GetSalesReport(ctx context.Context, startDate, endDate time.Time) (*models.SalesReport, error)
+	GetPeriodSales(ctx context.Context, startDate, endDate time.Time) (*models.DashboardSales, error)
+	GetRecent(ctx context.Context, limit int) ([]models.Transaction, error)
+	GetSalesReport(ctx context.Context, startDate, endDate time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error)
 	GetTopProducts(ctx context.Context, startDate, endDate time.Time, limit int) ([]models.ProductSales, error)
+	GetUnitsSold(ctx context.Context, startDate, endDate time.Time) ([]models.ProductSales, error)
 	GetCashierPerformance(ctx context.Context, startDate, endDate time.Time) ([]models.CashierPerformance, error)
 }
 
@@ -102,16 +116,27 @@ type StockMovementRepository interface {
 	List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockMovement, int64, error)
 }
 
+// StockAdjustmentRepository defines the interface for stock adjustment operations
+type StockAdjustmentRepository interface {
+	Create(ctx context.Context, adjustment *models.StockAdjustment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error)
+	Update(ctx context.Context, adjustment *models.StockAdjustment) error
+	List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockAdjustment, int64, error)
+}
+
 // ExpenseRepository defines the interface for expense operations
 type ExpenseRepository interface {
 	Create(ctx context.Context, expense *models.Expense) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Expense, error)
 	Update(ctx context.Context, expense *models.Expense) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.Expense, int64, error)
 	GetByCategory(ctx context.Context, category models.ExpenseCategory, startDate, endDate time.Time) ([]models.Expense, error)
 	GetTotalByPeriod(ctx context.Context, startDate, endDate time.Time) (float64, error)
 	Approve(ctx context.Context, id uuid.UUID, approvedBy uuid.UUID) error
+	Reject(ctx context.Context, id uuid.UUID, rejectedBy uuid.UUID, reason string) error
 }
 
 // StockRecommendationRepository defines the interface for stock recommendation operations
@@ -122,6 +147,7 @@ type StockRecommendationRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockRecommendation, int64, error)
 	GetPending(ctx context.Context) ([]models.StockRecommendation, error)
+	GetOpen(ctx context.Context) ([]models.StockRecommendation, error)
 	TakeAction(ctx context.Context, id uuid.UUID, action string, notes *string, userID uuid.UUID) error
 }
 
@@ -144,7 +170,10 @@ type SystemConfigRepository interface {
 // CartRepository defines the interface for shopping cart operations
 type CartRepository interface {
 	Create(ctx context.Context, cart *models.Cart) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Cart, error)
 	GetByCashierID(ctx context.Context, cashierID uuid.UUID) (*models.Cart, error)
+	ListByCashierID(ctx context.Context, cashierID uuid.UUID, status *models.CartStatus) ([]models.Cart, error)
+	DeleteHeldBefore(ctx context.Context, before time.Time) (int64, error)
 	Update(ctx context.Context, cart *models.Cart) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Clear(ctx context.Context, cashierID uuid.UUID) error
@@ -153,6 +182,58 @@ type CartRepository interface {
 	RemoveItem(ctx context.Context, cartID uuid.UUID, productID uuid.UUID) error
 }
 
+// ExportRepository streams records for data exports a batch at a time so an
+// export never holds a whole table in memory
+type ExportRepository interface {
+	EachTransaction(ctx context.Context, filters map[string]interface{}, fn func([]models.Transaction) error) error
+	EachProduct(ctx context.Context, filters map[string]interface{}, fn func([]models.Product) error) error
+	EachStockMovement(ctx context.Context, filters map[string]interface{}, fn func([]models.StockMovement) error) error
+	EachExpense(ctx context.Context, filters map[string]interface{}, fn func([]models.Expense) error) error
+	EachAuditLog(ctx context.Context, filters map[string]interface{}, fn func([]models.AuditLog) error) error
+}
+
+// EmailDeliveryRepository defines the interface for queued email operations
+type EmailDeliveryRepository interface {
+	Create(ctx context.Context, delivery *models.EmailDelivery) error
+	Update(ctx context.Context, delivery *models.EmailDelivery) error
+	ListByTransaction(ctx context.Context, transactionID uuid.UUID) ([]models.EmailDelivery, error)
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.EmailDelivery, error)
+}
+
+// RevokedTokenRepository defines the interface for access token revocations
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, revoked *models.RevokedToken) error
+	ListActive(ctx context.Context, now time.Time) ([]models.RevokedToken, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginThrottleRepository defines the interface for failed login tracking
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, scope, identifier string) (*models.LoginThrottle, error)
+	Lock(ctx context.Context, scope, identifier string) (*models.LoginThrottle, error)
+	Update(ctx context.Context, throttle *models.LoginThrottle) error
+	Delete(ctx context.Context, scope, identifier string) error
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TwoFactorRepository defines the interface for TOTP authenticators,
+// recovery codes, login challenges and the per-role policy
+type TwoFactorRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error)
+	Save(ctx context.Context, twoFactor *models.TwoFactor) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error
+	GetChallenge(ctx context.Context, tokenHash string) (*models.TwoFactorChallenge, error)
+	DeleteChallenge(ctx context.Context, id uuid.UUID) error
+	DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error)
+	GetPolicy(ctx context.Context, role models.Role) (*models.TwoFactorPolicy, error)
+	ListPolicies(ctx context.Context) ([]models.TwoFactorPolicy, error)
+	SavePolicy(ctx context.Context, policy *models.TwoFactorPolicy) error
+}
+
 // Repositories represents all repository interfaces
 type Repositories struct {
 	User                UserRepository
@@ -163,17 +244,21 @@ type Repositories struct {
 	Category            CategoryRepository
 	Transaction         TransactionRepository
 	StockMovement       StockMovementRepository
+	StockAdjustment     StockAdjustmentRepository
 	Expense             ExpenseRepository
 	StockRecommendation StockRecommendationRepository
 	AuditLog            AuditLogRepository
 	SystemConfig        SystemConfigRepository
 	Cart                CartRepository
+	Export              ExportRepository
+	EmailDelivery       EmailDeliveryRepository
+	RevokedToken        RevokedTokenRepository
+	LoginThrottle       LoginThrottleRepository
+	TwoFactor           TwoFactorRepository
 	DB                  *gorm.DB
 }
 
 // NewRepositories creates new repository instances
-// TODO: Implement repository constructors before enabling this function
-/*
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
 		User:                NewUserRepository(db),
@@ -184,12 +269,17 @@ func NewRepositories(db *gorm.DB) *Repositories {
 		Category:            NewCategoryRepository(db),
 		Transaction:         NewTransactionRepository(db),
 		StockMovement:       NewStockMovementRepository(db),
+		StockAdjustment:     NewStockAdjustmentRepository(db),
 		Expense:             NewExpenseRepository(db),
 		StockRecommendation: NewStockRecommendationRepository(db),
 		AuditLog:            NewAuditLogRepository(db),
 		SystemConfig:        NewSystemConfigRepository(db),
 		Cart:                NewCartRepository(db),
+		Export:              NewExportRepository(db),
+		EmailDelivery:       NewEmailDeliveryRepository(db),
+		RevokedToken:        NewRevokedTokenRepository(db),
+		LoginThrottle:       NewLoginThrottleRepository(db),
+		TwoFactor:           NewTwoFactorRepository(db),
 		DB:                  db,
 	}
 }
-*/
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginThrottleRepository creates a new GORM-backed login throttle repository
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Get(ctx context.Context, scope, identifier string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := conn(ctx, r.db).
+		Where("scope = ? AND identifier = ?", scope, identifier).
+		First(&throttle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// Lock returns the throttle for scope and identifier, creating it if needed,
+// with the row locked until the surrounding transaction ends. It must be
+// called inside RunInTx.
+func (r *loginThrottleRepository) Lock(ctx context.Context, scope, identifier string) (*models.LoginThrottle, error) {
+	db := conn(ctx, r.db)
+	throttle := &models.LoginThrottle{
+		ID:            uuid.New(),
+		Scope:         scope,
+		Identifier:    identifier,
+		LastFailureAt: time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(throttle).Error; err != nil {
+		return nil, err
+	}
+
+	var locked models.LoginThrottle
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("scope = ? AND identifier = ?", scope, identifier).
+		First(&locked).Error
+	if err != nil {
+		return nil, err
+	}
+	return &locked, nil
+}
+
+func (r *loginThrottleRepository) Update(ctx context.Context, throttle *models.LoginThrottle) error {
+	throttle.UpdatedAt = time.Now()
+	return conn(ctx, r.db).Save(throttle).Error
+}
+
+func (r *loginThrottleRepository) Delete(ctx context.Context, scope, identifier string) error {
+	return conn(ctx, r.db).
+		Where("scope = ? AND identifier = ?", scope, identifier).
+		Delete(&models.LoginThrottle{}).Error
+}
+
+// DeleteStale removes throttles with no failure since before and no lockout
+// still running
+func (r *loginThrottleRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now()).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type passwordRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordRepository creates a new GORM-backed password repository
+func NewPasswordRepository(db *gorm.DB) PasswordRepository {
+	return &passwordRepository{db: db}
+}
+
+func (r *passwordRepository) Create(ctx context.Context, password *models.Password) error {
+	return conn(ctx, r.db).Create(password).Error
+}
+
+func (r *passwordRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Password, error) {
+	var password models.Password
+	if err := conn(ctx, r.db).First(&password, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+func (r *passwordRepository) Update(ctx context.Context, password *models.Password) error {
+	return conn(ctx, r.db).Save(password).Error
+}
+
+func (r *passwordRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&models.Password{}, "user_id = ?", userID).Error
+}
+
+func (r *passwordRepository) SetResetToken(ctx context.Context, userID uuid.UUID, token string, expiresAt time.Time) error {
+	result := conn(ctx, r.db).
+		Model(&models.Password{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"reset_token":            token,
+			"reset_token_expires_at": expiresAt,
+			"updated_at":             time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ValidateResetToken finds the password record holding an unexpired reset
+// token. Inside a transaction the row stays locked so a token cannot be
+// redeemed twice concurrently.
+func (r *passwordRepository) ValidateResetToken(ctx context.Context, token string) (*models.Password, error) {
+	var password models.Password
+	query := conn(ctx, r.db)
+	if _, ok := TxFromContext(ctx); ok {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	err := query.
+		Where("reset_token = ? AND reset_token_expires_at > ?", token, time.Now()).
+		First(&password).Error
+	if err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
+
+func (r *passwordRepository) ClearResetToken(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).
+		Model(&models.Password{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"reset_token":            nil,
+			"reset_token_expires_at": nil,
+			"updated_at":             time.Now(),
+		}).Error
+}
+
+// GetByEmailVerificationToken finds the password record holding an email
+// verification token, locking the row inside a transaction
+func (r *passwordRepository) GetByEmailVerificationToken(ctx context.Context, token string) (*models.Password, error) {
+	var password models.Password
+	query := conn(ctx, r.db)
+	if _, ok := TxFromContext(ctx); ok {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	err := query.Where("email_verification_token = ?", token).First(&password).Error
+	if err != nil {
+		return nil, err
+	}
+	return &password, nil
+}
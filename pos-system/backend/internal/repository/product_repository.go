@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var productSortColumns = map[string]string{
+	"name":       "products.name",
+	"sku":        "products.sku",
+	"price":      "products.price",
+	"cost":       "products.cost",
+	"stock":      "products.stock",
+	"status":     "products.status",
+	"created_at": "products.created_at",
+	"createdAt":  "products.created_at",
+	"updated_at": "products.updated_at",
+	"updatedAt":  "products.updated_at",
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+// NewProductRepository creates a new GORM-backed product repository
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{db: db}
+}
+
+func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(product).Error
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	if err := conn(ctx, r.db).Preload("Category").First(&product, "products.id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetByIDForUpdate loads a product and locks its row until the surrounding
+// transaction ends; ctx must carry a transaction
+func (r *productRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	var product models.Product
+	if err := conn(ctx, r.db).Preload("Category").First(&product, "products.sku = ?", sku).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) GetByBarcode(ctx context.Context, barcode string) (*models.Product, error) {
+	var product models.Product
+	if err := conn(ctx, r.db).Preload("Category").First(&product, "products.barcode = ?", barcode).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) Update(ctx context.Context, product *models.Product) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(product).Error
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.Product{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *productRepository) List(ctx context.Context, filters *models.ProductFilters, pagination *models.PaginationQuery) ([]models.Product, int64, error) {
+	query := conn(ctx, r.db).Model(&models.Product{})
+
+	if filters != nil {
+		if filters.CategoryID != nil {
+			query = query.Where("products.category_id = ?", *filters.CategoryID)
+		}
+		if filters.Status != nil {
+			query = query.Where("products.status = ?", *filters.Status)
+		}
+		if filters.IsActive != nil {
+			query = query.Where("products.is_active = ?", *filters.IsActive)
+		}
+		if filters.MinPrice != nil {
+			query = query.Where("products.price >= ?", *filters.MinPrice)
+		}
+		if filters.MaxPrice != nil {
+			query = query.Where("products.price <= ?", *filters.MaxPrice)
+		}
+		if filters.LowStock != nil {
+			if *filters.LowStock {
+				query = query.Where("products.stock <= products.min_stock")
+			} else {
+				query = query.Where("products.stock > products.min_stock")
+			}
+		}
+		if filters.Supplier != "" {
+			query = query.Where("products.supplier ILIKE ?", searchPattern(filters.Supplier))
+		}
+		if filters.SearchTerm != "" {
+			query = r.applySearch(query, filters.SearchTerm)
+		}
+	}
+
+	if pagination != nil && pagination.Search != "" {
+		query = r.applySearch(query, pagination.Search)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	err := paginate(query, pagination, productSortColumns, "products.created_at").
+		Preload("Category").
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// applySearch matches a term against name, SKU, barcode and description
+func (r *productRepository) applySearch(query *gorm.DB, term string) *gorm.DB {
+	pattern := searchPattern(term)
+	return query.Where(
+		"products.name ILIKE ? OR products.sku ILIKE ? OR products.barcode ILIKE ? OR products.description ILIKE ?",
+		pattern, pattern, pattern, pattern,
+	)
+}
+
+// UpdateStock applies a signed quantity delta to a product's stock and
+// records the matching stock movement in the same transaction
+func (r *productRepository) UpdateStock(ctx context.Context, id uuid.UUID, quantity int, reason string, userID uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		return r.applyStockDelta(ctx, id, quantity, reason, userID)
+	})
+}
+
+func (r *productRepository) GetLowStock(ctx context.Context, threshold int) ([]models.Product, error) {
+	var products []models.Product
+	err := conn(ctx, r.db).
+		Preload("Category").
+		Where("is_active = ? AND stock > 0 AND (stock <= ? OR stock <= min_stock)", true, threshold).
+		Order("stock ASC").
+		Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) GetOutOfStock(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := conn(ctx, r.db).
+		Preload("Category").
+		Where("is_active = ? AND stock = 0", true).
+		Order("name ASC").
+		Find(&products).Error
+	return products, err
+}
+
+// GetActive returns every active product that is still on sale
+func (r *productRepository) GetActive(ctx context.Context) ([]models.Product, error) {
+	var products []models.Product
+	err := conn(ctx, r.db).
+		Where("is_active = ? AND status = ?", true, models.ProductStatusActive).
+		Order("name ASC").
+		Find(&products).Error
+	return products, err
+}
+
+// BulkUpdateStock applies every update atomically; if any product would go
+// negative nothing is changed
+func (r *productRepository) BulkUpdateStock(ctx context.Context, updates []models.BulkStockUpdate, userID uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		for _, update := range updates {
+			if err := r.applyStockDelta(ctx, update.ProductID, update.Quantity, update.Reason, userID); err != nil {
+				return fmt.Errorf("product %s: %w", update.ProductID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// applyStockDelta must run inside a transaction; it locks the product row
+// before reading the current stock
+func (r *productRepository) applyStockDelta(ctx context.Context, id uuid.UUID, delta int, reason string, userID uuid.UUID) error {
+	db := conn(ctx, r.db)
+
+	product, err := r.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	newStock := product.Stock + delta
+	if newStock < 0 {
+		return ErrInsufficientStock
+	}
+
+	if err := db.Model(&models.Product{}).Where("id = ?", id).Update("stock", newStock).Error; err != nil {
+		return err
+	}
+
+	movementType := models.StockMovementIn
+	quantity := delta
+	if delta < 0 {
+		movementType = models.StockMovementOut
+		quantity = -delta
+	}
+
+	movement := &models.StockMovement{
+		ProductID:   id,
+		Type:        movementType,
+		Quantity:    quantity,
+		Reason:      reason,
+		PerformedBy: userID,
+	}
+	return db.Omit(clause.Associations).Create(movement).Error
+}
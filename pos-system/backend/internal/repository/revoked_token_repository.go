@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository creates a new GORM-backed revoked token repository
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+// Create records a revocation; revoking the same JTI twice is not an error
+func (r *revokedTokenRepository) Create(ctx context.Context, revoked *models.RevokedToken) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(revoked).Error
+}
+
+// ListActive returns the revocations that still cover unexpired tokens
+func (r *revokedTokenRepository) ListActive(ctx context.Context, now time.Time) ([]models.RevokedToken, error) {
+	var revoked []models.RevokedToken
+	err := conn(ctx, r.db).Where("expires_at > ?", now).Find(&revoked).Error
+	return revoked, err
+}
+
+func (r *revokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new GORM-backed session repository
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	normalizeSession(session)
+	return conn(ctx, r.db).Create(session).Error
+}
+
+func (r *sessionRepository) GetByToken(ctx context.Context, token string) (*models.Session, error) {
+	var session models.Session
+	err := conn(ctx, r.db).
+		Where("session_token = ? AND is_active = ?", token, true).
+		First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByToken returns the session issued with token whether or not it is
+// still active, so rotated tokens can be recognised when replayed. Inside a
+// transaction the row stays locked so a token cannot be rotated twice
+// concurrently.
+func (r *sessionRepository) FindByToken(ctx context.Context, token string) (*models.Session, error) {
+	var session models.Session
+	query := conn(ctx, r.db)
+	if _, ok := TxFromContext(ctx); ok {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	if err := query.Where("session_token = ?", token).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := conn(ctx, r.db).
+		Where("user_id = ? AND is_active = ? AND expires_at > ?", userID, true, time.Now()).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *models.Session) error {
+	normalizeSession(session)
+	return conn(ctx, r.db).Save(session).Error
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&models.Session{}, "id = ?", id).Error
+}
+
+// DeleteExpired removes expired and revoked sessions. Rotated sessions are
+// kept until they expire so a replayed token is still recognised.
+func (r *sessionRepository) DeleteExpired(ctx context.Context) error {
+	return conn(ctx, r.db).
+		Where("expires_at < ? OR (is_active = ? AND rotated_at IS NULL)", time.Now(), false).
+		Delete(&models.Session{}).Error
+}
+
+func (r *sessionRepository) RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).
+		Model(&models.Session{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error
+}
+
+// RevokeFamily deactivates every session descended from the same login.
+// Sessions created before families were recorded are their own family.
+func (r *sessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	return conn(ctx, r.db).
+		Model(&models.Session{}).
+		Where("(family_id = ? OR id = ?) AND is_active = ?", familyID, familyID, true).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error
+}
+
+// normalizeSession clears empty optional fields; ip_address is an inet column
+// and rejects empty strings
+func normalizeSession(session *models.Session) {
+	if session.IPAddress != nil && *session.IPAddress == "" {
+		session.IPAddress = nil
+	}
+	if session.UserAgent != nil && *session.UserAgent == "" {
+		session.UserAgent = nil
+	}
+}
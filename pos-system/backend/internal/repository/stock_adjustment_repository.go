@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var stockAdjustmentSortColumns = map[string]string{
+	"quantity":   "quantity",
+	"value":      "value",
+	"status":     "status",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+}
+
+var stockAdjustmentFilterColumns = map[string]string{
+	"product_id":   "product_id",
+	"productId":    "product_id",
+	"status":       "status",
+	"reason_code":  "reason_code",
+	"reasonCode":   "reason_code",
+	"requested_by": "requested_by",
+	"requestedBy":  "requested_by",
+	"reviewed_by":  "reviewed_by",
+	"reviewedBy":   "reviewed_by",
+}
+
+type stockAdjustmentRepository struct {
+	db *gorm.DB
+}
+
+// NewStockAdjustmentRepository creates a new GORM-backed stock adjustment repository
+func NewStockAdjustmentRepository(db *gorm.DB) StockAdjustmentRepository {
+	return &stockAdjustmentRepository{db: db}
+}
+
+func (r *stockAdjustmentRepository) Create(ctx context.Context, adjustment *models.StockAdjustment) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(adjustment).Error
+}
+
+func (r *stockAdjustmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	var adjustment models.StockAdjustment
+	err := conn(ctx, r.db).
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&adjustment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// GetByIDForUpdate loads an adjustment and locks its row until the surrounding
+// transaction ends; ctx must carry a transaction
+func (r *stockAdjustmentRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	var locked models.StockAdjustment
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&locked, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *stockAdjustmentRepository) Update(ctx context.Context, adjustment *models.StockAdjustment) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(adjustment).Error
+}
+
+func (r *stockAdjustmentRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockAdjustment, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.StockAdjustment{}), filters, stockAdjustmentFilterColumns, "created_at")
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("reason ILIKE ? OR reference ILIKE ? OR notes ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var adjustments []models.StockAdjustment
+	err := paginate(query, pagination, stockAdjustmentSortColumns, "created_at").
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Find(&adjustments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return adjustments, total, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var stockMovementSortColumns = map[string]string{
+	"type":       "type",
+	"quantity":   "quantity",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+}
+
+var stockMovementFilterColumns = map[string]string{
+	"product_id":   "product_id",
+	"productId":    "product_id",
+	"type":         "type",
+	"performed_by": "performed_by",
+	"performedBy":  "performed_by",
+	"reference":    "reference",
+}
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new GORM-backed stock movement repository
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+func (r *stockMovementRepository) Create(ctx context.Context, movement *models.StockMovement) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(movement).Error
+}
+
+func (r *stockMovementRepository) GetByProductID(ctx context.Context, productID uuid.UUID, pagination *models.PaginationQuery) ([]models.StockMovement, int64, error) {
+	return r.List(ctx, map[string]interface{}{"product_id": productID}, pagination)
+}
+
+func (r *stockMovementRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockMovement, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.StockMovement{}), filters, stockMovementFilterColumns, "created_at")
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("reason ILIKE ? OR reference ILIKE ? OR notes ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movements []models.StockMovement
+	err := paginate(query, pagination, stockMovementSortColumns, "created_at").
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Find(&movements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movements, total, nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var recommendationSortColumns = map[string]string{
+	"priority":          "priority",
+	"status":            "status",
+	"estimatedCost":     "estimated_cost",
+	"salesVelocity":     "sales_velocity",
+	"daysUntilStockout": "days_until_stockout",
+	"createdAt":         "created_at",
+	"created_at":        "created_at",
+}
+
+var recommendationFilterColumns = map[string]string{
+	"status":     "status",
+	"priority":   "priority",
+	"product_id": "product_id",
+	"productId":  "product_id",
+}
+
+type stockRecommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewStockRecommendationRepository creates a new GORM-backed stock recommendation repository
+func NewStockRecommendationRepository(db *gorm.DB) StockRecommendationRepository {
+	return &stockRecommendationRepository{db: db}
+}
+
+func (r *stockRecommendationRepository) Create(ctx context.Context, recommendation *models.StockRecommendation) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(recommendation).Error
+}
+
+func (r *stockRecommendationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StockRecommendation, error) {
+	var recommendation models.StockRecommendation
+	if err := conn(ctx, r.db).Preload("ActionTakenByUser").First(&recommendation, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &recommendation, nil
+}
+
+func (r *stockRecommendationRepository) Update(ctx context.Context, recommendation *models.StockRecommendation) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(recommendation).Error
+}
+
+func (r *stockRecommendationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&models.StockRecommendation{}, "id = ?", id).Error
+}
+
+func (r *stockRecommendationRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockRecommendation, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.StockRecommendation{}), filters, recommendationFilterColumns, "created_at")
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("product_name ILIKE ? OR product_sku ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var recommendations []models.StockRecommendation
+	if err := paginate(query, pagination, recommendationSortColumns, "created_at").Find(&recommendations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return recommendations, total, nil
+}
+
+// GetPending returns pending recommendations, most urgent first
+func (r *stockRecommendationRepository) GetPending(ctx context.Context) ([]models.StockRecommendation, error) {
+	var recommendations []models.StockRecommendation
+	err := conn(ctx, r.db).
+		Where("status = ?", models.RecommendationStatusPending).
+		Order(`CASE priority
+			WHEN 'URGENT' THEN 0 WHEN 'HIGH' THEN 1 WHEN 'MEDIUM' THEN 2 ELSE 3 END`).
+		Order("days_until_stockout ASC NULLS LAST").
+		Find(&recommendations).Error
+	return recommendations, err
+}
+
+// GetOpen returns recommendations still awaiting a decision or a delivery:
+// pending and accepted ones
+func (r *stockRecommendationRepository) GetOpen(ctx context.Context) ([]models.StockRecommendation, error) {
+	var recommendations []models.StockRecommendation
+	err := conn(ctx, r.db).
+		Where("status IN ?", []models.StockRecommendationStatus{
+			models.RecommendationStatusPending,
+			models.RecommendationStatusAccepted,
+		}).
+		Find(&recommendations).Error
+	return recommendations, err
+}
+
+// TakeAction applies "accept", "reject" or "process" to a recommendation.
+// Pending recommendations can be accepted, rejected or processed directly;
+// accepted ones can only be processed.
+func (r *stockRecommendationRepository) TakeAction(ctx context.Context, id uuid.UUID, action string, notes *string, userID uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+
+		var recommendation models.StockRecommendation
+		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&recommendation, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		var status models.StockRecommendationStatus
+		switch action {
+		case "accept":
+			if !recommendation.CanTakeAction() {
+				return ErrInvalidAction
+			}
+			status = models.RecommendationStatusAccepted
+		case "reject":
+			if !recommendation.CanTakeAction() {
+				return ErrInvalidAction
+			}
+			status = models.RecommendationStatusRejected
+		case "process":
+			if !recommendation.CanTakeAction() && recommendation.Status != models.RecommendationStatusAccepted {
+				return ErrInvalidAction
+			}
+			status = models.RecommendationStatusProcessed
+		default:
+			return ErrInvalidAction
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":          status,
+			"action_taken_by": userID,
+			"action_taken_at": now,
+			"updated_at":      now,
+		}
+		if notes != nil {
+			updates["notes"] = *notes
+		}
+
+		return db.Model(&models.StockRecommendation{}).Where("id = ?", id).Updates(updates).Error
+	})
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type systemConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewSystemConfigRepository creates a new GORM-backed system config repository
+func NewSystemConfigRepository(db *gorm.DB) SystemConfigRepository {
+	return &systemConfigRepository{db: db}
+}
+
+// Get returns the single system configuration row
+func (r *systemConfigRepository) Get(ctx context.Context) (*models.SystemConfig, error) {
+	var config models.SystemConfig
+	if err := conn(ctx, r.db).Order("updated_at DESC").First(&config).Error; err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *systemConfigRepository) Update(ctx context.Context, config *models.SystemConfig, updatedBy uuid.UUID) error {
+	config.UpdatedBy = updatedBy
+	return conn(ctx, r.db).Omit(clause.Associations).Save(config).Error
+}
+
+func (r *systemConfigRepository) GetCompanyInfo(ctx context.Context) (*models.CompanyInfo, error) {
+	config, err := r.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CompanyInfo{
+		Name:    config.CompanyName,
+		Address: config.CompanyAddress,
+		Phone:   config.CompanyPhone,
+		Email:   config.CompanyEmail,
+		Website: config.CompanyWebsite,
+		TaxID:   config.CompanyTaxID,
+	}, nil
+}
@@ -0,0 +1,679 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var transactionSortColumns = map[string]string{
+	"receiptId":     "receipt_id",
+	"receipt_id":    "receipt_id",
+	"total":         "total",
+	"subtotal":      "subtotal",
+	"status":        "status",
+	"paymentMethod": "payment_method",
+	"createdAt":     "created_at",
+	"created_at":    "created_at",
+	"updatedAt":     "updated_at",
+	"updated_at":    "updated_at",
+}
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+// NewTransactionRepository creates a new GORM-backed transaction repository
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+// Create inserts the transaction together with its items and payments
+func (r *transactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	return conn(ctx, r.db).Omit("Cashier", "RefundedByUser").Create(transaction).Error
+}
+
+func (r *transactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.withDetails(conn(ctx, r.db)).First(&transaction, "transactions.id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetByIDForUpdate loads a transaction with its items and payments and
+// locks the transaction row until the surrounding transaction ends
+func (r *transactionRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	// Lock on a plain query; the locking clause must not leak into preloads
+	var locked models.Transaction
+	err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *transactionRepository) GetByReceiptID(ctx context.Context, receiptID string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.withDetails(conn(ctx, r.db)).First(&transaction, "transactions.receipt_id = ?", receiptID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// withDetails preloads the relations needed to display a receipt
+func (r *transactionRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Cashier")
+}
+
+func (r *transactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(transaction).Error
+}
+
+func (r *transactionRepository) UpdateItem(ctx context.Context, item *models.TransactionItem) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(item).Error
+}
+
+func (r *transactionRepository) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Create(payment).Error
+}
+
+func (r *transactionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+		if err := db.Delete(&models.TransactionItem{}, "transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := db.Delete(&models.Payment{}, "transaction_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := db.Delete(&models.Transaction{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *transactionRepository) List(ctx context.Context, filters *models.TransactionFilters, pagination *models.PaginationQuery) ([]models.Transaction, int64, error) {
+	query := conn(ctx, r.db).Model(&models.Transaction{})
+
+	if filters != nil {
+		if filters.CashierID != nil {
+			query = query.Where("cashier_id = ?", *filters.CashierID)
+		}
+		if filters.UserID != nil {
+			query = query.Where("cashier_id = ?", *filters.UserID)
+		}
+		if filters.Status != nil {
+			query = query.Where("status = ?", *filters.Status)
+		}
+		if filters.PaymentMethod != nil {
+			// Matches any tender, not just the primary method on the header
+			query = query.Where("EXISTS (SELECT 1 FROM payments WHERE payments.transaction_id = transactions.id AND payments.method = ?)", *filters.PaymentMethod)
+		}
+		if filters.MinTotal != nil {
+			query = query.Where("total >= ?", *filters.MinTotal)
+		}
+		if filters.MaxTotal != nil {
+			query = query.Where("total <= ?", *filters.MaxTotal)
+		}
+		if filters.MinAmount != nil {
+			query = query.Where("amount_paid >= ?", *filters.MinAmount)
+		}
+		if filters.MaxAmount != nil {
+			query = query.Where("amount_paid <= ?", *filters.MaxAmount)
+		}
+		if filters.StartDate != nil {
+			query = query.Where("created_at >= ?", *filters.StartDate)
+		}
+		if filters.EndDate != nil {
+			query = query.Where("created_at <= ?", *filters.EndDate)
+		}
+		if filters.ReceiptID != nil {
+			query = query.Where("receipt_id = ?", *filters.ReceiptID)
+		}
+		if filters.CustomerEmail != nil {
+			query = query.Where("LOWER(customer_email) = LOWER(?)", *filters.CustomerEmail)
+		}
+		if filters.CustomerPhone != nil {
+			query = query.Where("customer_phone = ?", *filters.CustomerPhone)
+		}
+	}
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("receipt_id ILIKE ? OR customer_name ILIKE ? OR customer_email ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var transactions []models.Transaction
+	err := paginate(query, pagination, transactionSortColumns, "created_at").
+		Preload("Items").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// salesStatuses are the transaction states that count as sales. Fully
+// refunded transactions stay in so their refunds show up in reports; pending
+// and cancelled ones never took any money.
+var salesStatuses = []models.TransactionStatus{
+	models.TransactionStatusCompleted,
+	models.TransactionStatusRefunded,
+}
+
+// salesBetween scopes a transactions query to sales made in a period
+func salesBetween(db *gorm.DB, startDate, endDate time.Time) *gorm.DB {
+	return db.Where("transactions.status IN ? AND transactions.created_at BETWEEN ? AND ?",
+		salesStatuses, startDate, endDate)
+}
+
+// paymentsJoin selects payment rows joined to their transaction so tenders
+// can be filtered by the transaction's status and date
+func paymentsJoin(db *gorm.DB) *gorm.DB {
+	return db.Table("payments").Joins("JOIN transactions ON transactions.id = payments.transaction_id")
+}
+
+// withRefunds joins the amount refunded against each transaction as
+// refunds.amount, which is NULL for transactions without refunds
+func (r *transactionRepository) withRefunds(ctx context.Context, db *gorm.DB) *gorm.DB {
+	refunds := conn(ctx, r.db).Table("payments").
+		Select("transaction_id, -SUM(amount) AS amount").
+		Where("amount < 0").
+		Group("transaction_id")
+	return db.Joins("LEFT JOIN (?) AS refunds ON refunds.transaction_id = transactions.id", refunds)
+}
+
+// Sales figures are net of refunds. Tax and discounts are scaled by the share
+// of the transaction that was kept, the same proportion refunds are priced at.
+const (
+	keptShare = `CASE WHEN transactions.total > 0
+		THEN (transactions.total - COALESCE(refunds.amount, 0)) / transactions.total ELSE 1 END`
+
+	salesTotalsColumns = `COALESCE(SUM(CASE WHEN transactions.status = ? THEN 1 ELSE 0 END), 0) AS transaction_count,
+		COALESCE(SUM(transactions.total), 0) AS gross_sales,
+		COALESCE(SUM(refunds.amount), 0) AS refunds,
+		COALESCE(SUM(transactions.total - COALESCE(refunds.amount, 0)), 0) AS total_sales,
+		COALESCE(SUM(transactions.tax_amount * ` + keptShare + `), 0) AS total_tax,
+		COALESCE(SUM(transactions.discount_amount * ` + keptShare + `), 0) AS total_discount`
+)
+
+// salesTotals holds transaction-level figures. TransactionCount leaves out
+// fully refunded sales.
+type salesTotals struct {
+	Period           time.Time
+	TransactionCount int
+	GrossSales       float64
+	Refunds          float64
+	TotalSales       float64
+	TotalTax         float64
+	TotalDiscount    float64
+}
+
+func (r *transactionRepository) salesTotals(ctx context.Context, startDate, endDate time.Time) (*salesTotals, error) {
+	rows, err := r.salesTotalsBy(ctx, startDate, endDate, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return &salesTotals{}, nil
+	}
+	return &rows[0], nil
+}
+
+// salesTotalsBy returns one row per value of the period expression, or a
+// single row for the whole range when period is empty
+func (r *transactionRepository) salesTotalsBy(ctx context.Context, startDate, endDate time.Time, period string) ([]salesTotals, error) {
+	query := r.withRefunds(ctx, salesBetween(conn(ctx, r.db).Model(&models.Transaction{}), startDate, endDate))
+	columns := salesTotalsColumns
+	if period != "" {
+		columns = period + " AS period, " + columns
+		query = query.Group(period).Order("period ASC")
+	}
+
+	var rows []salesTotals
+	err := query.Select(columns, models.TransactionStatusCompleted).Scan(&rows).Error
+	return rows, err
+}
+
+// Line figures count only the units that were not refunded. Revenue spreads
+// the order-level discount over the lines and leaves out tax, so it is what
+// the business keeps; cost is the unit cost captured at checkout, falling back
+// to the product's current cost for lines recorded before it was captured.
+const (
+	netQuantity = "(transaction_items.quantity - transaction_items.refunded_quantity)"
+
+	netItemRevenue = `(transaction_items.subtotal - transaction_items.discount) * ` + netQuantity + ` / transaction_items.quantity *
+		CASE WHEN item_totals.net > 0
+		THEN (transactions.subtotal - transactions.discount_amount) / item_totals.net ELSE 0 END`
+
+	netItemCost = "COALESCE(transaction_items.unit_cost, products.cost, 0) * " + netQuantity
+
+	itemTotalsColumns = `COALESCE(SUM(` + netQuantity + `), 0) AS items_sold,
+		COALESCE(SUM(` + netItemRevenue + `), 0) AS net_revenue,
+		COALESCE(SUM(` + netItemCost + `), 0) AS total_cost`
+)
+
+type itemTotals struct {
+	Period     time.Time
+	ItemsSold  int
+	NetRevenue float64
+	TotalCost  float64
+}
+
+// itemsBetween selects the lines of sales made in a period joined to their
+// transaction, the transaction's line total and the product
+func (r *transactionRepository) itemsBetween(ctx context.Context, startDate, endDate time.Time) *gorm.DB {
+	lineTotals := conn(ctx, r.db).Table("transaction_items").
+		Select("transaction_id, SUM(subtotal - discount) AS net").
+		Group("transaction_id")
+	return salesBetween(conn(ctx, r.db).Table("transaction_items").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id").
+		Joins("JOIN (?) AS item_totals ON item_totals.transaction_id = transactions.id", lineTotals).
+		Joins("LEFT JOIN products ON products.id = transaction_items.product_id"), startDate, endDate)
+}
+
+func (r *transactionRepository) itemTotals(ctx context.Context, startDate, endDate time.Time) (*itemTotals, error) {
+	rows, err := r.itemTotalsBy(ctx, startDate, endDate, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return &itemTotals{}, nil
+	}
+	return &rows[0], nil
+}
+
+// itemTotalsBy returns one row per value of the period expression, or a
+// single row for the whole range when period is empty
+func (r *transactionRepository) itemTotalsBy(ctx context.Context, startDate, endDate time.Time, period string) ([]itemTotals, error) {
+	query := r.itemsBetween(ctx, startDate, endDate)
+	columns := itemTotalsColumns
+	if period != "" {
+		columns = period + " AS period, " + columns
+		query = query.Group(period).Order("period ASC")
+	}
+
+	var rows []itemTotals
+	err := query.Select(columns).Scan(&rows).Error
+	return rows, err
+}
+
+// periodColumn truncates the sale time to the start of its day, week or
+// month. Weeks start on Monday.
+func periodColumn(groupBy models.ReportGroupBy) string {
+	switch groupBy {
+	case models.ReportGroupByWeek:
+		return "date_trunc('week', transactions.created_at)"
+	case models.ReportGroupByMonth:
+		return "date_trunc('month', transactions.created_at)"
+	default:
+		return "date_trunc('day', transactions.created_at)"
+	}
+}
+
+// grossMargin returns profit as a percentage of revenue
+func grossMargin(profit, revenue float64) float64 {
+	if revenue <= 0 {
+		return 0
+	}
+	return profit / revenue * 100
+}
+
+type methodTotal struct {
+	Period time.Time
+	Method string
+	Amount float64
+}
+
+// paymentMethodTotals sums the tenders applied to sales by method. Refunds
+// are negative payments, so the totals are already net.
+func (r *transactionRepository) paymentMethodTotals(ctx context.Context, startDate, endDate time.Time) (map[string]float64, error) {
+	var rows []methodTotal
+	err := salesBetween(paymentsJoin(conn(ctx, r.db)), startDate, endDate).
+		Select("payments.method AS method, COALESCE(SUM(payments.amount), 0) AS amount").
+		Group("payments.method").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		totals[row.Method] = row.Amount
+	}
+	return totals, nil
+}
+
+// GetPeriodSales returns headline sales figures, net of refunds, for sales
+// between the two dates. Growth is left for the caller to fill in.
+func (r *transactionRepository) GetPeriodSales(ctx context.Context, startDate, endDate time.Time) (*models.DashboardSales, error) {
+	totals, err := r.salesTotals(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales totals: %w", err)
+	}
+
+	items, err := r.itemTotals(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count items sold: %w", err)
+	}
+
+	sales := &models.DashboardSales{
+		TotalSales:       totals.TotalSales,
+		TransactionCount: totals.TransactionCount,
+		ItemsSold:        items.ItemsSold,
+	}
+	if totals.TransactionCount > 0 {
+		sales.AverageOrder = totals.TotalSales / float64(totals.TransactionCount)
+	}
+	return sales, nil
+}
+
+// GetRecent returns the latest transactions of any status with their items
+// and cashier
+func (r *transactionRepository) GetRecent(ctx context.Context, limit int) ([]models.Transaction, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var transactions []models.Transaction
+	err := conn(ctx, r.db).
+		Preload("Items").
+		Preload("Cashier").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *transactionRepository) GetDailySales(ctx context.Context, date time.Time) (*models.DailySales, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	totals, err := r.salesTotals(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily totals: %w", err)
+	}
+
+	methods, err := r.paymentMethodTotals(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method totals: %w", err)
+	}
+
+	daily := &models.DailySales{
+		Date:             start,
+		TransactionCount: totals.TransactionCount,
+		TotalSales:       totals.TotalSales,
+		TotalTax:         totals.TotalTax,
+		TotalDiscount:    totals.TotalDiscount,
+		PaymentMethods:   methods,
+	}
+	if totals.TransactionCount > 0 {
+		daily.AverageTransaction = totals.TotalSales / float64(totals.TransactionCount)
+	}
+
+	return daily, nil
+}
+
+// GetSalesReport builds the sales report for the two dates with its series
+// grouped by day, week or month. Cancelled and pending transactions are left
+// out and refunds are netted against the sales they came from.
+func (r *transactionRepository) GetSalesReport(ctx context.Context, startDate, endDate time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error) {
+	totals, err := r.salesTotals(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales totals: %w", err)
+	}
+
+	items, err := r.itemTotals(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item totals: %w", err)
+	}
+
+	topProducts, err := r.GetTopProducts(ctx, startDate, endDate, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []models.ChartData
+	err = r.itemsBetween(ctx, startDate, endDate).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Select(`categories.name AS label,
+			COALESCE(SUM(` + netItemRevenue + `), 0) AS value,
+			COALESCE(SUM(` + netQuantity + `), 0) AS count`).
+		Group("categories.name").
+		Having("SUM(" + netQuantity + ") > 0").
+		Order("value DESC").
+		Scan(&categories).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category breakdown: %w", err)
+	}
+
+	series, err := r.salesSeries(ctx, startDate, endDate, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	cashiers, err := r.GetCashierPerformance(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := r.paymentMethodTotals(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method totals: %w", err)
+	}
+
+	report := &models.SalesReport{
+		Period:             fmt.Sprintf("%s - %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		StartDate:          startDate,
+		EndDate:            endDate,
+		GroupBy:            groupBy,
+		GrossSales:         totals.GrossSales,
+		TotalRefunds:       totals.Refunds,
+		TotalSales:         totals.TotalSales,
+		TotalTax:           totals.TotalTax,
+		TotalDiscount:      totals.TotalDiscount,
+		NetRevenue:         items.NetRevenue,
+		TotalCost:          items.TotalCost,
+		GrossProfit:        items.NetRevenue - items.TotalCost,
+		GrossMargin:        grossMargin(items.NetRevenue-items.TotalCost, items.NetRevenue),
+		TotalTransactions:  totals.TransactionCount,
+		TotalItems:         items.ItemsSold,
+		TopProducts:        topProducts,
+		CategoryBreakdown:  categories,
+		Series:             series,
+		CashierPerformance: cashiers,
+		PaymentMethods:     methods,
+	}
+	if totals.TransactionCount > 0 {
+		report.AverageOrder = totals.TotalSales / float64(totals.TransactionCount)
+	}
+	if groupBy == models.ReportGroupByDay {
+		report.DailySales = dailySales(series)
+	}
+
+	return report, nil
+}
+
+// dailySales converts a day series to the DailySales rows reports returned
+// before they could be grouped by week or month
+func dailySales(series []models.SalesPeriod) []models.DailySales {
+	daily := make([]models.DailySales, 0, len(series))
+	for _, day := range series {
+		row := models.DailySales{
+			Date:             day.Date,
+			TransactionCount: day.TransactionCount,
+			TotalSales:       day.TotalSales,
+			TotalTax:         day.TotalTax,
+			TotalDiscount:    day.TotalDiscount,
+			PaymentMethods:   day.PaymentMethods,
+		}
+		if day.TransactionCount > 0 {
+			row.AverageTransaction = day.TotalSales / float64(day.TransactionCount)
+		}
+		daily = append(daily, row)
+	}
+	return daily
+}
+
+// salesSeries returns one SalesPeriod per day, week or month with sales
+func (r *transactionRepository) salesSeries(ctx context.Context, startDate, endDate time.Time, groupBy models.ReportGroupBy) ([]models.SalesPeriod, error) {
+	period := periodColumn(groupBy)
+
+	totals, err := r.salesTotalsBy(ctx, startDate, endDate, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales series: %w", err)
+	}
+
+	items, err := r.itemTotalsBy(ctx, startDate, endDate, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item series: %w", err)
+	}
+	itemsByPeriod := make(map[int64]itemTotals, len(items))
+	for _, row := range items {
+		itemsByPeriod[row.Period.Unix()] = row
+	}
+
+	var methodRows []methodTotal
+	err = salesBetween(paymentsJoin(conn(ctx, r.db)), startDate, endDate).
+		Select(period + " AS period, payments.method AS method, COALESCE(SUM(payments.amount), 0) AS amount").
+		Group(period + ", payments.method").
+		Scan(&methodRows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment method series: %w", err)
+	}
+	methodsByPeriod := make(map[int64]map[string]float64)
+	for _, row := range methodRows {
+		key := row.Period.Unix()
+		if methodsByPeriod[key] == nil {
+			methodsByPeriod[key] = make(map[string]float64)
+		}
+		methodsByPeriod[key][row.Method] = row.Amount
+	}
+
+	series := make([]models.SalesPeriod, 0, len(totals))
+	for _, row := range totals {
+		item := itemsByPeriod[row.Period.Unix()]
+		series = append(series, models.SalesPeriod{
+			Date:             row.Period,
+			TransactionCount: row.TransactionCount,
+			ItemsSold:        item.ItemsSold,
+			GrossSales:       row.GrossSales,
+			Refunds:          row.Refunds,
+			TotalSales:       row.TotalSales,
+			TotalTax:         row.TotalTax,
+			TotalDiscount:    row.TotalDiscount,
+			NetRevenue:       item.NetRevenue,
+			TotalCost:        item.TotalCost,
+			GrossProfit:      item.NetRevenue - item.TotalCost,
+			GrossMargin:      grossMargin(item.NetRevenue-item.TotalCost, item.NetRevenue),
+			PaymentMethods:   methodsByPeriod[row.Period.Unix()],
+		})
+	}
+
+	return series, nil
+}
+
+// GetTopProducts returns the best-selling products by net revenue, with
+// refunded units taken off
+func (r *transactionRepository) GetTopProducts(ctx context.Context, startDate, endDate time.Time, limit int) ([]models.ProductSales, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var products []models.ProductSales
+	err := r.itemsBetween(ctx, startDate, endDate).
+		Select(`transaction_items.product_id AS product_id,
+			MAX(transaction_items.product_name) AS product_name,
+			MAX(transaction_items.product_sku) AS product_sku,
+			COALESCE(SUM(` + netQuantity + `), 0) AS total_quantity,
+			COALESCE(SUM(` + netItemRevenue + `), 0) AS total_revenue,
+			COALESCE(SUM(` + netItemCost + `), 0) AS total_cost,
+			COUNT(DISTINCT transaction_items.transaction_id) AS transaction_count`).
+		Group("transaction_items.product_id").
+		Having("SUM(" + netQuantity + ") > 0").
+		Order("total_revenue DESC").
+		Limit(limit).
+		Scan(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top products: %w", err)
+	}
+
+	for i := range products {
+		products[i].GrossProfit = products[i].TotalRevenue - products[i].TotalCost
+	}
+	return products, nil
+}
+
+// GetUnitsSold returns the net units sold per product between the two dates,
+// with refunded units taken off
+func (r *transactionRepository) GetUnitsSold(ctx context.Context, startDate, endDate time.Time) ([]models.ProductSales, error) {
+	var products []models.ProductSales
+	err := salesBetween(conn(ctx, r.db).Table("transaction_items").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id"), startDate, endDate).
+		Select(`transaction_items.product_id AS product_id,
+			MAX(transaction_items.product_name) AS product_name,
+			MAX(transaction_items.product_sku) AS product_sku,
+			COALESCE(SUM(` + netQuantity + `), 0) AS total_quantity,
+			COUNT(DISTINCT transaction_items.transaction_id) AS transaction_count`).
+		Group("transaction_items.product_id").
+		Scan(&products).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get units sold: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetCashierPerformance returns each cashier's sales net of refunds. Fully
+// refunded transactions are not counted towards a cashier's transactions.
+func (r *transactionRepository) GetCashierPerformance(ctx context.Context, startDate, endDate time.Time) ([]models.CashierPerformance, error) {
+	itemCounts := conn(ctx, r.db).Table("transaction_items").
+		Select("transaction_id, SUM(quantity - refunded_quantity) AS quantity").
+		Group("transaction_id")
+
+	var performance []models.CashierPerformance
+	err := r.withRefunds(ctx, salesBetween(conn(ctx, r.db).Table("transactions").
+		Joins("JOIN users ON users.id = transactions.cashier_id").
+		Joins("LEFT JOIN (?) AS item_counts ON item_counts.transaction_id = transactions.id", itemCounts), startDate, endDate)).
+		Select(`transactions.cashier_id AS cashier_id,
+			users.name AS cashier_name,
+			COALESCE(SUM(CASE WHEN transactions.status = ? THEN 1 ELSE 0 END), 0) AS transaction_count,
+			COALESCE(SUM(transactions.total - COALESCE(refunds.amount, 0)), 0) AS total_sales,
+			COALESCE(SUM(item_counts.quantity), 0) AS items_sold`, models.TransactionStatusCompleted).
+		Group("transactions.cashier_id, users.name").
+		Order("total_sales DESC").
+		Scan(&performance).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cashier performance: %w", err)
+	}
+
+	for i := range performance {
+		if performance[i].TransactionCount > 0 {
+			performance[i].AverageTransaction = performance[i].TotalSales / float64(performance[i].TransactionCount)
+		}
+	}
+	return performance, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+// reportSchema holds just the columns the report queries read
+const reportSchema = `
+CREATE TABLE users (id uuid PRIMARY KEY, name text NOT NULL);
+CREATE TABLE categories (id uuid PRIMARY KEY, name text NOT NULL);
+CREATE TABLE products (id uuid PRIMARY KEY, category_id uuid REFERENCES categories, cost numeric(10,2) NOT NULL);
+CREATE TABLE transactions (
+	id uuid PRIMARY KEY,
+	cashier_id uuid NOT NULL REFERENCES users,
+	status text NOT NULL,
+	subtotal numeric(10,2) NOT NULL,
+	tax_amount numeric(10,2) NOT NULL,
+	discount_amount numeric(10,2) NOT NULL,
+	total numeric(10,2) NOT NULL,
+	created_at timestamptz NOT NULL
+);
+CREATE TABLE transaction_items (
+	id uuid PRIMARY KEY,
+	transaction_id uuid NOT NULL REFERENCES transactions,
+	product_id uuid NOT NULL REFERENCES products,
+	product_name text NOT NULL,
+	product_sku text NOT NULL,
+	quantity int NOT NULL,
+	refunded_quantity int NOT NULL,
+	subtotal numeric(10,2) NOT NULL,
+	discount numeric(10,2) NOT NULL,
+	unit_cost numeric(10,2)
+);
+CREATE TABLE payments (
+	id uuid PRIMARY KEY,
+	transaction_id uuid NOT NULL REFERENCES transactions,
+	method text NOT NULL,
+	amount numeric(10,2) NOT NULL
+);`
+
+// reportTestDB connects to TEST_DATABASE_URL with its own schema, which is
+// dropped when the test ends. Tests that need it are skipped without one.
+func reportTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get sql.DB: %v", err)
+	}
+	// One connection so the search path applies to every query
+	sqlDB.SetMaxOpenConns(1)
+
+	schema := "report_test_" + uuid.NewString()[:8]
+	if err := db.Exec(fmt.Sprintf("CREATE SCHEMA %s; SET search_path TO %s", schema, schema)).Error; err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		sqlDB.Close()
+	})
+	if err := db.Exec(reportSchema).Error; err != nil {
+		t.Fatalf("Failed to create tables: %v", err)
+	}
+	return db
+}
+
+func TestSalesReportPartialRefund(t *testing.T) {
+	db := reportTestDB(t)
+	exec := func(sql string, values ...interface{}) {
+		t.Helper()
+		if err := db.Exec(sql, values...).Error; err != nil {
+			t.Fatalf("Failed to insert fixtures: %v", err)
+		}
+	}
+
+	now := time.Now()
+	soldAt := now.Add(-time.Hour)
+	cashier, category := uuid.New(), uuid.New()
+	shirt, socks := uuid.New(), uuid.New()
+	sale, cancelled := uuid.New(), uuid.New()
+
+	exec("INSERT INTO users VALUES (?, 'Casey')", cashier)
+	exec("INSERT INTO categories VALUES (?, 'Clothing')", category)
+	// Costs have gone up since the sale; margins must use the captured cost
+	exec("INSERT INTO products VALUES (?, ?, 9), (?, ?, 9)", shirt, category, socks, category)
+
+	// 3 x 10.00 and 1 x 5.00 at 10% tax paid 20.00 by card and 18.50 in cash,
+	// then one shirt refunded for 11.00 split over both tenders
+	exec("INSERT INTO transactions VALUES (?, ?, 'COMPLETED', 35.00, 3.50, 0, 38.50, ?)", sale, cashier, soldAt)
+	exec(`INSERT INTO transaction_items VALUES
+		(?, ?, ?, 'shirt', 'SKU-shirt', 3, 1, 30.00, 0, 4.00),
+		(?, ?, ?, 'socks', 'SKU-socks', 1, 0, 5.00, 0, 1.00)`,
+		uuid.New(), sale, shirt, uuid.New(), sale, socks)
+	exec(`INSERT INTO payments VALUES
+		(?, ?, 'CARD', 20.00), (?, ?, 'CASH', 18.50),
+		(?, ?, 'CARD', -5.71), (?, ?, 'CASH', -5.29)`,
+		uuid.New(), sale, uuid.New(), sale, uuid.New(), sale, uuid.New(), sale)
+
+	// A cancelled sale took no money and must not count
+	exec("INSERT INTO transactions VALUES (?, ?, 'CANCELLED', 100, 10, 0, 110, ?)", cancelled, cashier, soldAt)
+	exec("INSERT INTO transaction_items VALUES (?, ?, ?, 'shirt', 'SKU-shirt', 10, 0, 100, 0, 4.00)", uuid.New(), cancelled, shirt)
+
+	repo := NewTransactionRepository(db)
+	report, err := repo.GetSalesReport(context.Background(), now.Add(-24*time.Hour), now, models.ReportGroupByDay)
+	if err != nil {
+		t.Fatalf("GetSalesReport failed: %v", err)
+	}
+
+	figures := []struct {
+		name      string
+		got, want float64
+	}{
+		{"gross sales", report.GrossSales, 38.50},
+		{"refunds", report.TotalRefunds, 11.00},
+		{"total sales", report.TotalSales, 27.50},
+		{"tax on the kept share", report.TotalTax, 2.50},
+		{"net revenue", report.NetRevenue, 25.00},
+		{"cost of kept units", report.TotalCost, 9.00},
+		{"gross profit", report.GrossProfit, 16.00},
+		{"gross margin", report.GrossMargin, 64.00},
+		{"average order", report.AverageOrder, 27.50},
+		{"card", report.PaymentMethods["CARD"], 14.29},
+		{"cash", report.PaymentMethods["CASH"], 13.21},
+	}
+	for _, figure := range figures {
+		if math.Abs(figure.got-figure.want) > 0.005 {
+			t.Errorf("Expected %s of %.2f, got %.4f", figure.name, figure.want, figure.got)
+		}
+	}
+	if report.TotalTransactions != 1 || report.TotalItems != 3 {
+		t.Errorf("Expected 1 transaction with 3 items kept, got %d and %d", report.TotalTransactions, report.TotalItems)
+	}
+
+	if len(report.TopProducts) != 2 || report.TopProducts[0].ProductID != shirt || report.TopProducts[0].TotalQuantity != 2 {
+		t.Errorf("Expected the shirt first with 2 kept, got %+v", report.TopProducts)
+	}
+	if len(report.Series) != 1 || math.Abs(report.Series[0].TotalSales-27.50) > 0.005 {
+		t.Errorf("Expected one day of 27.50, got %+v", report.Series)
+	}
+	if len(report.DailySales) != 1 || math.Abs(report.DailySales[0].TotalSales-27.50) > 0.005 {
+		t.Errorf("Expected dailySales to match the series, got %+v", report.DailySales)
+	}
+}
+
+func TestDailySales(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	series := []models.SalesPeriod{
+		{Date: day, TransactionCount: 4, TotalSales: 50, TotalTax: 5, TotalDiscount: 1, PaymentMethods: map[string]float64{"CASH": 50}},
+		{Date: day.AddDate(0, 0, 1), GrossSales: 8, Refunds: 8}, // only a fully refunded sale
+	}
+
+	daily := dailySales(series)
+
+	if len(daily) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(daily))
+	}
+	first := daily[0]
+	if !first.Date.Equal(day) || first.TransactionCount != 4 || first.TotalSales != 50 || first.TotalTax != 5 ||
+		first.TotalDiscount != 1 || first.AverageTransaction != 12.5 || first.PaymentMethods["CASH"] != 50 {
+		t.Errorf("Expected the first day copied from the series, got %+v", first)
+	}
+	if daily[1].AverageTransaction != 0 {
+		t.Errorf("Expected no average without transactions, got %.2f", daily[1].AverageTransaction)
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new GORM-backed two-factor repository
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// GetByUserID returns a user's authenticator, locking the row inside a
+// transaction
+func (r *twoFactorRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	query := conn(ctx, r.db)
+	if _, ok := TxFromContext(ctx); ok {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	if err := query.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(ctx context.Context, twoFactor *models.TwoFactor) error {
+	twoFactor.UpdatedAt = time.Now()
+	return conn(ctx, r.db).Save(twoFactor).Error
+}
+
+// Delete removes a user's authenticator along with their recovery codes and
+// pending challenges
+func (r *twoFactorRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+		if err := db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("user_id = ?", userID).Delete(&models.TwoFactorChallenge{}).Error; err != nil {
+			return err
+		}
+		return db.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes discards a user's recovery codes, used or not, and
+// stores the given hashes in their place
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	return RunInTx(ctx, r.db, func(ctx context.Context) error {
+		db := conn(ctx, r.db)
+		if err := db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.RecoveryCode, len(hashes))
+		now := time.Now()
+		for i, hash := range hashes {
+			codes[i] = models.RecoveryCode{
+				ID:        uuid.New(),
+				UserID:    userID,
+				CodeHash:  hash,
+				CreatedAt: now,
+			}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return db.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used and reports whether
+// there was one to mark
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountRecoveryCodes returns how many of a user's recovery codes are unused
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *twoFactorRepository) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error {
+	return conn(ctx, r.db).Create(challenge).Error
+}
+
+// GetChallenge finds a challenge by token hash, locking the row inside a
+// transaction
+func (r *twoFactorRepository) GetChallenge(ctx context.Context, tokenHash string) (*models.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	query := conn(ctx, r.db)
+	if _, ok := TxFromContext(ctx); ok {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	if err := query.Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *twoFactorRepository) DeleteChallenge(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&models.TwoFactorChallenge{}, "id = ?", id).Error
+}
+
+func (r *twoFactorRepository) DeleteExpiredChallenges(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at < ?", now).Delete(&models.TwoFactorChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *twoFactorRepository) GetPolicy(ctx context.Context, role models.Role) (*models.TwoFactorPolicy, error) {
+	var policy models.TwoFactorPolicy
+	if err := conn(ctx, r.db).Where("role = ?", role).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *twoFactorRepository) ListPolicies(ctx context.Context) ([]models.TwoFactorPolicy, error) {
+	var policies []models.TwoFactorPolicy
+	if err := conn(ctx, r.db).Order("role").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *twoFactorRepository) SavePolicy(ctx context.Context, policy *models.TwoFactorPolicy) error {
+	policy.UpdatedAt = time.Now()
+	return conn(ctx, r.db).Save(policy).Error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+var userSortColumns = map[string]string{
+	"name":        "name",
+	"email":       "email",
+	"role":        "role",
+	"isActive":    "is_active",
+	"is_active":   "is_active",
+	"lastLoginAt": "last_login_at",
+	"createdAt":   "created_at",
+	"created_at":  "created_at",
+	"updatedAt":   "updated_at",
+	"updated_at":  "updated_at",
+}
+
+var userFilterColumns = map[string]string{
+	"role":      "role",
+	"is_active": "is_active",
+	"isActive":  "is_active",
+	"email":     "email",
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new GORM-backed user repository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Create(user).Error
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := conn(ctx, r.db).First(&user, "LOWER(email) = LOWER(?)", email).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return conn(ctx, r.db).Save(user).Error
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.User{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) List(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.User, int64, error) {
+	query := applyFilters(conn(ctx, r.db).Model(&models.User{}), filters, userFilterColumns, "created_at")
+
+	if pagination != nil && pagination.Search != "" {
+		pattern := searchPattern(pagination.Search)
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := paginate(query, pagination, userSortColumns, "created_at").Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
+	return r.updateColumns(ctx, id, map[string]interface{}{"last_login_at": time.Now()})
+}
+
+func (r *userRepository) SetActiveStatus(ctx context.Context, id uuid.UUID, isActive bool) error {
+	return r.updateColumns(ctx, id, map[string]interface{}{"is_active": isActive})
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uuid.UUID, role models.Role) error {
+	return r.updateColumns(ctx, id, map[string]interface{}{"role": role})
+}
+
+// updateColumns updates the given columns and reports ErrRecordNotFound when
+// no user matched the ID
+func (r *userRepository) updateColumns(ctx context.Context, id uuid.UUID, values map[string]interface{}) error {
+	values["updated_at"] = time.Now()
+	result := conn(ctx, r.db).Model(&models.User{}).Where("id = ?", id).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
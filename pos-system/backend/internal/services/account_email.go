@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/mailer"
+)
+
+// accountEmailTimeout bounds sending an account email in the background
+const accountEmailTimeout = 30 * time.Second
+
+// accountLink builds a link into the frontend carrying a one-time token
+func accountLink(base, path, token string) string {
+	return strings.TrimRight(base, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// passwordResetMessage is the email carrying a password reset link
+func passwordResetMessage(user *models.User, link string, ttl time.Duration) *mailer.Message {
+	return accountMessage(user, "Reset your password",
+		fmt.Sprintf("We received a request to reset your password. The link below is valid for %s.", formatTTL(ttl)),
+		"Reset password", link,
+		"If you did not ask to reset your password you can ignore this email; your password will not change.")
+}
+
+// verificationMessage is the email asking a new user to confirm their address
+func verificationMessage(user *models.User, link string, ttl time.Duration) *mailer.Message {
+	return accountMessage(user, "Confirm your email address",
+		fmt.Sprintf("Please confirm your email address to finish setting up your account. The link below is valid for %s.", formatTTL(ttl)),
+		"Confirm email", link,
+		"If you did not create an account you can ignore this email.")
+}
+
+// loginLockedMessage tells a user that sign-in to their account was locked
+// after repeated failed attempts
+func loginLockedMessage(user *models.User, ip string, until time.Time, link string) *mailer.Message {
+	intro := "We temporarily locked sign-in to your account after several failed attempts"
+	if ip != "" {
+		intro += " from " + ip
+	}
+	intro += fmt.Sprintf(". You can try again after %s UTC.", until.UTC().Format("2006-01-02 15:04"))
+	return accountMessage(user, "Sign-in to your account was locked", intro,
+		"Reset password", link,
+		"If these attempts were not you, we recommend resetting your password. An administrator can also unlock your account.")
+}
+
+// accountMessage lays out a short account email with a single action link
+func accountMessage(user *models.User, subject, intro, action, link, outro string) *mailer.Message {
+	greeting := "Hello " + user.Name + ","
+
+	text := strings.Join([]string{greeting, "", intro, "", link, "", outro, ""}, "\n")
+
+	var body strings.Builder
+	body.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"></head>` +
+		`<body style="margin:0;padding:16px;background:#f4f4f4;font-family:Arial,Helvetica,sans-serif;font-size:14px;color:#222222">` +
+		`<div style="max-width:480px;margin:0 auto;padding:24px;background:#ffffff">`)
+	fmt.Fprintf(&body, "<p>%s</p><p>%s</p>", html.EscapeString(greeting), html.EscapeString(intro))
+	fmt.Fprintf(&body, `<p><a href="%s" style="display:inline-block;padding:10px 16px;background:#2563eb;color:#ffffff;text-decoration:none;border-radius:4px">%s</a></p>`,
+		html.EscapeString(link), html.EscapeString(action))
+	fmt.Fprintf(&body, `<p style="font-size:12px;color:#666666">Or open this link: %s</p><p>%s</p>`,
+		html.EscapeString(link), html.EscapeString(outro))
+	body.WriteString("</div></body></html>\n")
+
+	return &mailer.Message{
+		To:      []string{user.Email},
+		Subject: subject,
+		HTML:    body.String(),
+		Text:    text,
+	}
+}
+
+// sendAccountEmail sends msg without holding up the request, so response
+// times do not reveal whether an address is registered. Failures are logged.
+func sendAccountEmail(m mailer.Mailer, msg *mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), accountEmailTimeout)
+		defer cancel()
+		if err := m.Send(ctx, msg); err != nil {
+			log.Printf("failed to send %q to %s: %v", msg.Subject, strings.Join(msg.To, ", "), err)
+		}
+	}()
+}
+
+// formatTTL writes a token lifetime in whole hours or minutes
+func formatTTL(ttl time.Duration) string {
+	switch {
+	case ttl >= time.Hour && ttl%time.Hour == 0:
+		if ttl == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(ttl/time.Hour))
+	case ttl == time.Minute:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", int(ttl/time.Minute))
+	}
+}
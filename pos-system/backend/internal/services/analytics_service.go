@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+const (
+	dashboardTopProducts     = 10
+	dashboardRecentLimit     = 10
+	dashboardRecommendations = 10
+	dashboardChartDays       = 30
+)
+
+// AnalyticsService computes dashboard and reporting figures
+type AnalyticsService struct {
+	transactionRepo    repository.TransactionRepository
+	recommendationRepo repository.StockRecommendationRepository
+	cacheTTL           time.Duration
+
+	// The dashboard is shared by every manager, so one cached copy serves
+	// all of them until it expires
+	mu          sync.Mutex
+	dashboard   *models.Dashboard
+	cachedUntil time.Time
+}
+
+// NewAnalyticsService creates a new analytics service. Dashboards are cached
+// for cacheTTL; zero disables caching.
+func NewAnalyticsService(
+	transactionRepo repository.TransactionRepository,
+	recommendationRepo repository.StockRecommendationRepository,
+	cacheTTL time.Duration,
+) *AnalyticsService {
+	return &AnalyticsService{
+		transactionRepo:    transactionRepo,
+		recommendationRepo: recommendationRepo,
+		cacheTTL:           cacheTTL,
+	}
+}
+
+// GetDashboard returns the dashboard, computing it at most once per cache
+// period. Concurrent callers wait for a single computation rather than each
+// querying the database.
+func (s *AnalyticsService) GetDashboard(ctx context.Context) (*models.Dashboard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.dashboard != nil && now.Before(s.cachedUntil) {
+		return s.dashboard, nil
+	}
+
+	dashboard, err := s.buildDashboard(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.dashboard = dashboard
+	s.cachedUntil = now.Add(s.cacheTTL)
+	return dashboard, nil
+}
+
+func (s *AnalyticsService) buildDashboard(ctx context.Context, now time.Time) (*models.Dashboard, error) {
+	today := startOfDay(now)
+	week := today.AddDate(0, 0, -(int(today.Weekday())+6)%7) // Monday
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	dashboard := &models.Dashboard{GeneratedAt: now}
+	periods := []struct {
+		target        *models.DashboardSales
+		start         time.Time
+		previousStart time.Time
+	}{
+		{&dashboard.TodaySales, today, today.AddDate(0, 0, -1)},
+		{&dashboard.WeeklySales, week, week.AddDate(0, 0, -7)},
+		{&dashboard.MonthlySales, month, month.AddDate(0, -1, 0)},
+	}
+	for _, period := range periods {
+		sales, err := s.periodSales(ctx, period.start, period.previousStart, now)
+		if err != nil {
+			return nil, err
+		}
+		*period.target = *sales
+	}
+
+	chartStart := today.AddDate(0, 0, -(dashboardChartDays - 1))
+	report, err := s.transactionRepo.GetSalesReport(ctx, chartStart, now, models.ReportGroupByDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales report: %w", err)
+	}
+	dashboard.TopProducts = report.TopProducts
+	dashboard.CategoryChart = report.CategoryBreakdown
+	dashboard.SalesChart = salesChart(report.Series, chartStart, dashboardChartDays)
+	dashboard.PaymentMethodChart = paymentMethodChart(report.PaymentMethods)
+	if len(dashboard.TopProducts) > dashboardTopProducts {
+		dashboard.TopProducts = dashboard.TopProducts[:dashboardTopProducts]
+	}
+
+	recent, err := s.transactionRepo.GetRecent(ctx, dashboardRecentLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent transactions: %w", err)
+	}
+	dashboard.RecentTransactions = make([]models.RecentTransaction, 0, len(recent))
+	for _, transaction := range recent {
+		dashboard.RecentTransactions = append(dashboard.RecentTransactions, models.RecentTransaction{
+			ID:            transaction.ID,
+			ReceiptID:     transaction.ReceiptID,
+			CashierName:   transaction.Cashier.Name,
+			ItemCount:     transaction.GetItemCount(),
+			Total:         transaction.Total,
+			PaymentMethod: transaction.PaymentMethod,
+			Status:        transaction.Status,
+			CreatedAt:     transaction.CreatedAt,
+		})
+	}
+
+	recommendations, err := s.recommendationRepo.GetPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending recommendations: %w", err)
+	}
+	if len(recommendations) > dashboardRecommendations {
+		recommendations = recommendations[:dashboardRecommendations]
+	}
+	dashboard.Recommendations = recommendations
+
+	return dashboard, nil
+}
+
+// periodSales returns sales from start to now with growth measured against
+// the same elapsed time from previousStart, so a partial day or month is
+// compared like for like
+func (s *AnalyticsService) periodSales(ctx context.Context, start, previousStart, now time.Time) (*models.DashboardSales, error) {
+	current, err := s.transactionRepo.GetPeriodSales(ctx, start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	previousEnd := previousStart.Add(now.Sub(start))
+	if previousEnd.After(start) {
+		previousEnd = start.Add(-time.Nanosecond)
+	}
+	previous, err := s.transactionRepo.GetPeriodSales(ctx, previousStart, previousEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	current.TotalSales = roundMoney(current.TotalSales)
+	current.AverageOrder = roundMoney(current.AverageOrder)
+	current.Growth = growth(current.TotalSales, previous.TotalSales)
+	return current, nil
+}
+
+// growth returns the percentage change from previous to current. With no
+// previous sales any current sales count as 100% growth.
+func growth(current, previous float64) float64 {
+	if previous == 0 {
+		if current > 0 {
+			return 100
+		}
+		return 0
+	}
+	return roundMoney((current - previous) / previous * 100)
+}
+
+// salesChart turns the daily series into one point per day, including days
+// without sales
+func salesChart(daily []models.SalesPeriod, start time.Time, days int) []models.ChartData {
+	byDay := make(map[string]models.SalesPeriod, len(daily))
+	for _, day := range daily {
+		byDay[day.Date.Format("2006-01-02")] = day
+	}
+
+	chart := make([]models.ChartData, 0, days)
+	for i := 0; i < days; i++ {
+		label := start.AddDate(0, 0, i).Format("2006-01-02")
+		day := byDay[label]
+		chart = append(chart, models.ChartData{
+			Label: label,
+			Value: roundMoney(day.TotalSales),
+			Count: day.TransactionCount,
+		})
+	}
+	return chart
+}
+
+// paymentMethodChart lists payment method totals, largest first
+func paymentMethodChart(methods map[string]float64) []models.ChartData {
+	chart := make([]models.ChartData, 0, len(methods))
+	for method, amount := range methods {
+		chart = append(chart, models.ChartData{Label: method, Value: roundMoney(amount)})
+	}
+	sort.Slice(chart, func(i, j int) bool {
+		if chart[i].Value != chart[j].Value {
+			return chart[i].Value > chart[j].Value
+		}
+		return chart[i].Label < chart[j].Label
+	})
+	return chart
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+// fakeReportRepo answers the dashboard's report queries and counts how often
+// it is asked
+type fakeReportRepo struct {
+	repository.TransactionRepository
+	periodSales map[time.Time]float64 // total sales by period start
+	queries     int
+}
+
+func (r *fakeReportRepo) GetPeriodSales(ctx context.Context, startDate, endDate time.Time) (*models.DashboardSales, error) {
+	r.queries++
+	return &models.DashboardSales{TotalSales: r.periodSales[startDate]}, nil
+}
+
+func (r *fakeReportRepo) GetSalesReport(ctx context.Context, startDate, endDate time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error) {
+	r.queries++
+	return &models.SalesReport{PaymentMethods: map[string]float64{"CASH": 10}}, nil
+}
+
+func (r *fakeReportRepo) GetRecent(ctx context.Context, limit int) ([]models.Transaction, error) {
+	r.queries++
+	return nil, nil
+}
+
+type fakeRecommendationRepo struct {
+	repository.StockRecommendationRepository
+	pending []models.StockRecommendation
+}
+
+func (r *fakeRecommendationRepo) GetPending(ctx context.Context) ([]models.StockRecommendation, error) {
+	return r.pending, nil
+}
+
+func TestGrowth(t *testing.T) {
+	tests := []struct {
+		name              string
+		current, previous float64
+		want              float64
+	}{
+		{name: "no sales either period", want: 0},
+		{name: "first sales", current: 50, want: 100},
+		{name: "sales stopped", previous: 80, want: -100},
+		{name: "doubled", current: 200, previous: 100, want: 100},
+		{name: "down a third", current: 20, previous: 30, want: -33.33},
+		{name: "flat", current: 12.5, previous: 12.5, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := growth(tt.current, tt.previous); got != tt.want {
+				t.Errorf("Expected growth %.2f, got %.2f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSalesChartFillsEmptyDays(t *testing.T) {
+	start := time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC)
+	daily := []models.SalesPeriod{
+		{Date: start, TotalSales: 10.005, TransactionCount: 2},
+		{Date: start.AddDate(0, 0, 2), TotalSales: 7, TransactionCount: 1},
+	}
+
+	chart := salesChart(daily, start, 4)
+
+	want := []models.ChartData{
+		{Label: "2024-02-28", Value: 10.01, Count: 2},
+		{Label: "2024-02-29", Value: 0},
+		{Label: "2024-03-01", Value: 7, Count: 1},
+		{Label: "2024-03-02", Value: 0},
+	}
+	if len(chart) != len(want) {
+		t.Fatalf("Expected %d points, got %d", len(want), len(chart))
+	}
+	for i := range want {
+		if chart[i] != want[i] {
+			t.Errorf("Expected point %d to be %+v, got %+v", i, want[i], chart[i])
+		}
+	}
+}
+
+func TestPaymentMethodChartOrder(t *testing.T) {
+	chart := paymentMethodChart(map[string]float64{"CARD": 30, "CASH": 50, "MOBILE": 30, "GIFT_CARD": 0.125})
+
+	want := []string{"CASH", "CARD", "MOBILE", "GIFT_CARD"}
+	for i, label := range want {
+		if chart[i].Label != label {
+			t.Fatalf("Expected order %v, got %+v", want, chart)
+		}
+	}
+	if chart[3].Value != 0.13 {
+		t.Errorf("Expected amounts rounded to cents, got %.3f", chart[3].Value)
+	}
+}
+
+func TestPeriodSalesComparesLikeForLike(t *testing.T) {
+	today := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	repo := &fakeReportRepo{periodSales: map[time.Time]float64{today: 150, yesterday: 100}}
+	service := NewAnalyticsService(repo, nil, 0)
+
+	sales, err := service.periodSales(context.Background(), today, yesterday, today.Add(9*time.Hour))
+	if err != nil {
+		t.Fatalf("periodSales failed: %v", err)
+	}
+	if sales.TotalSales != 150 || sales.Growth != 50 {
+		t.Errorf("Expected 150 in sales with 50%% growth, got %.2f and %.2f", sales.TotalSales, sales.Growth)
+	}
+}
+
+func TestGetDashboardCaches(t *testing.T) {
+	repo := &fakeReportRepo{}
+	recommendations := &fakeRecommendationRepo{pending: make([]models.StockRecommendation, dashboardRecommendations+5)}
+
+	service := NewAnalyticsService(repo, recommendations, time.Minute)
+	first, err := service.GetDashboard(context.Background())
+	if err != nil {
+		t.Fatalf("GetDashboard failed: %v", err)
+	}
+	if len(first.Recommendations) != dashboardRecommendations {
+		t.Errorf("Expected %d recommendations, got %d", dashboardRecommendations, len(first.Recommendations))
+	}
+	if len(first.SalesChart) != dashboardChartDays {
+		t.Errorf("Expected a %d day chart, got %d points", dashboardChartDays, len(first.SalesChart))
+	}
+	perBuild := repo.queries
+
+	second, err := service.GetDashboard(context.Background())
+	if err != nil {
+		t.Fatalf("GetDashboard failed: %v", err)
+	}
+	if second != first || repo.queries != perBuild {
+		t.Errorf("Expected the cached dashboard without new queries, got %d more", repo.queries-perBuild)
+	}
+
+	// With caching disabled every call recomputes
+	service = NewAnalyticsService(repo, recommendations, 0)
+	queries := repo.queries
+	if _, err := service.GetDashboard(context.Background()); err != nil {
+		t.Fatalf("GetDashboard failed: %v", err)
+	}
+	if _, err := service.GetDashboard(context.Background()); err != nil {
+		t.Fatalf("GetDashboard failed: %v", err)
+	}
+	if got := repo.queries - queries; got != 2*perBuild {
+		t.Errorf("Expected both calls to query, got %d queries", got)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type clientInfoKey struct{}
+
+// ClientInfo carries request metadata that services record in audit logs
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// ContextWithClientInfo attaches client request metadata to ctx
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFromContext returns the client metadata stored in ctx, if any
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+// newAuditLog builds an audit entry for an action performed by user, taking
+// the client IP and user agent from ctx
+func newAuditLog(ctx context.Context, user *models.User, action models.AuditLogAction, resource, resourceID string, oldValues, newValues map[string]interface{}) *models.AuditLog {
+	client := ClientInfoFromContext(ctx)
+
+	auditLog := &models.AuditLog{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserName:  user.Name,
+		UserRole:  user.Role,
+		Action:    action,
+		Resource:  resource,
+		OldValues: oldValues,
+		NewValues: newValues,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+	if resourceID != "" {
+		auditLog.ResourceID = &resourceID
+	}
+
+	return auditLog
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+// ChangePassword allows a user to change their password
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *models.ChangePasswordRequest) error {
+	// Get current password
+	currentPassword, err := s.passwordRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get current password: %w", err)
+	}
+
+	// Verify current password
+	if err := bcrypt.CompareHashAndPassword([]byte(currentPassword.HashedPassword), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	// Hash new password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash new password: %w", err)
+	}
+
+	// Update password
+	currentPassword.HashedPassword = string(hashedPassword)
+	if err := s.passwordRepo.Update(ctx, currentPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Invalidate all existing sessions and access tokens for the user
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userID)
+	if err == nil {
+		for _, session := range sessions {
+			s.sessionRepo.Delete(ctx, session.ID)
+		}
+	}
+
+	return s.revoker.RevokeUser(ctx, userID)
+}
+
+// ResetPassword starts a password reset by emailing the user a link with a
+// one-time token. Only the token's hash is stored. Unknown, inactive and
+// password-less accounts get no email and the same response, so the
+// endpoint cannot be used to discover registered addresses.
+func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token, err := s.passwordManager.GenerateResetToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	err = s.passwordRepo.SetResetToken(ctx, user.ID, auth.HashToken(token), time.Now().Add(s.resetTokenTTL))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Signed up with OAuth and has no password to reset
+			return nil
+		}
+		return fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	link := accountLink(s.frontendURL, "/reset-password", token)
+	sendAccountEmail(s.mailer, passwordResetMessage(user, link, s.resetTokenTTL))
+	return nil
+}
+
+// ConfirmResetPassword sets a new password using a reset token. The token is
+// single use, and every session is revoked so anyone holding the old
+// password is signed out.
+func (s *AuthService) ConfirmResetPassword(ctx context.Context, req *models.ConfirmResetPasswordRequest) error {
+	if err := s.passwordManager.ValidatePassword(req.NewPassword); err != nil {
+		return &PasswordPolicyError{Reason: err.Error()}
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		password, err := s.passwordRepo.ValidateResetToken(ctx, auth.HashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to validate reset token: %w", err)
+		}
+
+		user, err := s.userRepo.GetByID(ctx, password.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.IsActive {
+			return ErrUserNotActive
+		}
+
+		hashedPassword, err := s.passwordManager.HashPassword(req.NewPassword)
+		if err != nil {
+			return fmt.Errorf("failed to hash new password: %w", err)
+		}
+		password.HashedPassword = hashedPassword
+		password.LastPasswordChange = time.Now()
+		password.ResetToken = nil
+		password.ResetTokenExpiresAt = nil
+		if err := s.passwordRepo.Update(ctx, password); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		if err := s.sessionRepo.RevokeAllUserSessions(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return s.revoker.RevokeUser(ctx, user.ID)
+	})
+}
+
+// VerifyEmail confirms the address a verification token was sent to. The
+// token is single use.
+func (s *AuthService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		password, err := s.passwordRepo.GetByEmailVerificationToken(ctx, auth.HashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidVerificationToken
+			}
+			return fmt.Errorf("failed to get verification token: %w", err)
+		}
+		if password.EmailVerificationSentAt == nil || time.Since(*password.EmailVerificationSentAt) > s.verificationTTL {
+			return ErrInvalidVerificationToken
+		}
+
+		now := time.Now()
+		password.EmailVerified = true
+		password.EmailVerifiedAt = &now
+		password.EmailVerificationToken = nil
+		password.EmailVerificationSentAt = nil
+		if err := s.passwordRepo.Update(ctx, password); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		return nil
+	})
+}
+
+// ResendVerification emails a fresh verification link, replacing the previous
+// one. Like ResetPassword it responds the same way for unknown and already
+// verified addresses, and requests within resendInterval of the last email
+// are ignored.
+func (s *AuthService) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	var token string
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		password, err := s.passwordRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to get password: %w", err)
+		}
+		if password.EmailVerified || password.EmailVerificationToken == nil {
+			return nil
+		}
+		if password.EmailVerificationSentAt != nil && time.Since(*password.EmailVerificationSentAt) < s.resendInterval {
+			return nil
+		}
+
+		token, err = s.passwordManager.GenerateEmailVerificationToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate verification token: %w", err)
+		}
+		hash := auth.HashToken(token)
+		now := time.Now()
+		password.EmailVerificationToken = &hash
+		password.EmailVerificationSentAt = &now
+		if err := s.passwordRepo.Update(ctx, password); err != nil {
+			return fmt.Errorf("failed to store verification token: %w", err)
+		}
+		return nil
+	})
+	if err != nil || token == "" {
+		return err
+	}
+
+	link := accountLink(s.frontendURL, "/verify-email", token)
+	sendAccountEmail(s.mailer, verificationMessage(user, link, s.verificationTTL))
+	return nil
+}
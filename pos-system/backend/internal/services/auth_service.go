@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +15,7 @@ import (
 	"github.com/pos-system/backend/internal/models"
 	"github.com/pos-system/backend/internal/repository"
 	"github.com/pos-system/backend/pkg/auth"
+	"github.com/pos-system/backend/pkg/mailer"
 )
 
 var (
@@ -23,34 +26,98 @@ var (
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrTokenExpired       = errors.New("token expired")
 	ErrInsufficientRole   = errors.New("insufficient role permissions")
+	ErrInvalidResetToken  = errors.New("reset token is invalid or has expired")
+	ErrWeakPassword       = errors.New("password does not meet the requirements")
+
+	ErrRefreshTokenReused       = errors.New("refresh token has already been used")
+	ErrInvalidVerificationToken = errors.New("verification token is invalid or has expired")
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
 )
 
+// PasswordPolicyError reports which password requirement was not met
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, e.Reason)
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// Details returns the fields reported to API clients
+func (e *PasswordPolicyError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"reason": e.Reason,
+	}
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo     repository.UserRepository
-	accountRepo  repository.AccountRepository
-	sessionRepo  repository.SessionRepository
-	passwordRepo repository.PasswordRepository
-	jwtManager   *auth.JWTManager
-	db           *gorm.DB
+	userRepo        repository.UserRepository
+	accountRepo     repository.AccountRepository
+	sessionRepo     repository.SessionRepository
+	passwordRepo    repository.PasswordRepository
+	auditRepo       repository.AuditLogRepository
+	jwtManager      *auth.JWTManager
+	revoker         *TokenRevoker
+	loginGuard      *LoginGuard
+	twoFactor       *TwoFactorService
+	passwordManager *auth.PasswordManager
+	mailer          mailer.Mailer
+	db              *gorm.DB
+	frontendURL     string
+	resetTokenTTL   time.Duration
+
+	requireVerification bool
+	verificationTTL     time.Duration
+	resendInterval      time.Duration
 }
 
-// NewAuthService creates a new authentication service
+// NewAuthService creates a new authentication service. Links in account
+// emails point at frontendURL. With requireVerification set, new accounts
+// cannot sign in until their email address is confirmed; resendInterval
+// limits how often the verification email can be sent again.
 func NewAuthService(
 	userRepo repository.UserRepository,
 	accountRepo repository.AccountRepository,
 	sessionRepo repository.SessionRepository,
 	passwordRepo repository.PasswordRepository,
+	auditRepo repository.AuditLogRepository,
 	jwtManager *auth.JWTManager,
+	revoker *TokenRevoker,
+	loginGuard *LoginGuard,
+	twoFactor *TwoFactorService,
+	passwordManager *auth.PasswordManager,
+	mailer mailer.Mailer,
 	db *gorm.DB,
+	frontendURL string,
+	resetTokenTTL time.Duration,
+	requireVerification bool,
+	verificationTTL time.Duration,
+	resendInterval time.Duration,
 ) *AuthService {
 	return &AuthService{
-		userRepo:     userRepo,
-		accountRepo:  accountRepo,
-		sessionRepo:  sessionRepo,
-		passwordRepo: passwordRepo,
-		jwtManager:   jwtManager,
-		db:           db,
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		sessionRepo:     sessionRepo,
+		passwordRepo:    passwordRepo,
+		auditRepo:       auditRepo,
+		jwtManager:      jwtManager,
+		revoker:         revoker,
+		loginGuard:      loginGuard,
+		twoFactor:       twoFactor,
+		passwordManager: passwordManager,
+		mailer:          mailer,
+		db:              db,
+		frontendURL:     frontendURL,
+		resetTokenTTL:   resetTokenTTL,
+
+		requireVerification: requireVerification,
+		verificationTTL:     verificationTTL,
+		resendInterval:      resendInterval,
 	}
 }
 
@@ -62,13 +129,11 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 		return nil, ErrEmailAlreadyExists
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
 
 	// Create user
 	user := &models.User{
@@ -79,47 +144,65 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 		IsActive: true,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	verificationToken, err := s.passwordManager.GenerateEmailVerificationToken()
 	if err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
 	}
+	verificationHash := auth.HashToken(verificationToken)
+	sentAt := time.Now()
 
-	// Create password record
-	password := &models.Password{
-		ID:             uuid.New(),
-		UserID:         user.ID,
-		HashedPassword: string(hashedPassword),
-	}
+	// Create user, password and account records in one transaction
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	if err := s.passwordRepo.Create(ctx, password); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to create password: %w", err)
-	}
+		// Create password record
+		password := &models.Password{
+			ID:                      uuid.New(),
+			UserID:                  user.ID,
+			HashedPassword:          string(hashedPassword),
+			EmailVerificationToken:  &verificationHash,
+			EmailVerificationSentAt: &sentAt,
+		}
 
-	// Create email account record
-	account := &models.Account{
-		ID:                uuid.New(),
-		UserID:            user.ID,
-		Type:              "email",
-		Provider:          "email",
-		ProviderAccountID: req.Email,
+		if err := s.passwordRepo.Create(ctx, password); err != nil {
+			return fmt.Errorf("failed to create password: %w", err)
+		}
+
+		// Create email account record
+		account := &models.Account{
+			ID:                uuid.New(),
+			UserID:            user.ID,
+			Type:              "email",
+			Provider:          "email",
+			ProviderAccountID: req.Email,
+		}
+
+		if err := s.accountRepo.Create(ctx, account); err != nil {
+			return fmt.Errorf("failed to create account: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.accountRepo.Create(ctx, account); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to create account: %w", err)
+	link := accountLink(s.frontendURL, "/verify-email", verificationToken)
+	sendAccountEmail(s.mailer, verificationMessage(user, link, s.verificationTTL))
+
+	if s.requireVerification {
+		return &models.AuthResponse{User: *user, VerificationRequired: true}, nil
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	// New cashiers enroll an authenticator first if their role requires one
+	challenge, err := s.twoFactor.BeginLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
 	}
 
 	// Generate tokens
@@ -134,17 +217,7 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 	}
 
 	// Create session
-	userAgent := ""
-	ipAddress := ""
-	session := &models.Session{
-		ID:           uuid.New(),
-		UserID:       user.ID,
-		SessionToken: refreshToken,
-		UserAgent:    &userAgent,                          // Will be set by middleware
-		IPAddress:    &ipAddress,                          // Will be set by middleware
-		ExpiresAt:    time.Now().Add(24 * time.Hour * 30), // 30 days
-	}
-
+	session := newSession(ctx, user.ID, refreshToken, uuid.Nil)
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
@@ -157,13 +230,20 @@ func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest)
 	}, nil
 }
 
-// Login authenticates a user with email/password
+// Login authenticates a user with email/password. When the user has an
+// authenticator, or their role requires one, no tokens are issued yet: the
+// response carries a challenge token to send to VerifyTwoFactor with a code.
 func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
+	// Refuse throttled accounts and clients before looking at the password
+	if err := s.loginGuard.Check(ctx, req.Email, ClientInfoFromContext(ctx).IPAddress); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrInvalidCredentials
+			return nil, s.loginFailed(ctx, req.Email, nil, "unknown_email")
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -176,12 +256,77 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	// Get password record
 	password, err := s.passwordRepo.GetByUserID(ctx, user.ID)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, req.Email, user, "no_password")
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(password.HashedPassword), []byte(req.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+		return nil, s.loginFailed(ctx, req.Email, user, "invalid_password")
+	}
+
+	// Accounts created by an admin or before verification existed carry no
+	// pending token and are let through
+	if s.requireVerification && !password.EmailVerified && password.EmailVerificationToken != nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Failures are only forgotten once the second factor is checked too, so
+	// a known password does not reset the count for guessing codes
+	challenge, err := s.twoFactor.BeginLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// VerifyTwoFactor finishes a login with the challenge token from Login and a
+// TOTP or recovery code. Wrong codes count as failed sign-ins. For a user
+// enrolling during login, the code confirms the new authenticator and the
+// response includes their recovery codes.
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, req *models.VerifyTwoFactorRequest) (*models.AuthResponse, error) {
+	user, err := s.twoFactor.ChallengeUser(ctx, req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loginGuard.Check(ctx, user.Email, ClientInfoFromContext(ctx).IPAddress); err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrUserNotActive
+	}
+
+	recoveryCodes, err := s.twoFactor.CompleteLogin(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(ctx, user.Email, user, "invalid_two_factor_code")
+		}
+		return nil, err
+	}
+
+	resp, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// SetupTwoFactor returns a new authenticator secret for a user whose login
+// challenge requires them to enroll. The first code from it goes to
+// VerifyTwoFactor.
+func (s *AuthService) SetupTwoFactor(ctx context.Context, req *models.SetupTwoFactorRequest) (*models.TwoFactorEnrollment, error) {
+	return s.twoFactor.SetupLogin(ctx, req.ChallengeToken)
+}
+
+// completeLogin issues tokens and a session to a user who has passed every
+// login check
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User) (*models.AuthResponse, error) {
+	if err := s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		log.Printf("Failed to clear login failures for user %s: %v", user.ID, err)
 	}
 
 	// Update last login
@@ -201,18 +346,8 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	// Create or update session
-	userAgent := ""
-	ipAddress := ""
-	session := &models.Session{
-		ID:           uuid.New(),
-		UserID:       user.ID,
-		SessionToken: refreshToken,
-		UserAgent:    &userAgent,                          // Will be set by middleware
-		IPAddress:    &ipAddress,                          // Will be set by middleware
-		ExpiresAt:    time.Now().Add(24 * time.Hour * 30), // 30 days
-	}
-
+	// Create session
+	session := newSession(ctx, user.ID, refreshToken, uuid.Nil)
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
@@ -225,63 +360,38 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 	}, nil
 }
 
-// RefreshToken generates new tokens using a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, req *models.RefreshTokenRequest) (*models.RefreshTokenResponse, error) {
-	// Validate refresh token
-	claims, err := s.jwtManager.ValidateRefreshToken(req.RefreshToken)
-	if err != nil {
-		return nil, ErrInvalidToken
-	}
-
-	// Get user
-	userID, err := uuid.Parse(claims.UserID)
-	if err != nil {
-		return nil, ErrInvalidToken
-	}
-
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return nil, ErrUserNotFound
-	}
-
-	// Check if user is still active
-	if !user.IsActive {
-		return nil, ErrUserNotActive
-	}
+// loginFailed records a failed sign-in and returns the error for the caller
+func (s *AuthService) loginFailed(ctx context.Context, email string, user *models.User, reason string) error {
+	s.recordLoginFailure(ctx, email, user, reason)
+	return ErrInvalidCredentials
+}
 
-	// Check if session exists and is valid
-	session, err := s.sessionRepo.GetByToken(ctx, req.RefreshToken)
+// recordLoginFailure counts a failed sign-in against the account and client
+// IP. Failures on a known account are audited, and its owner is emailed when
+// it gets locked.
+func (s *AuthService) recordLoginFailure(ctx context.Context, email string, user *models.User, reason string) {
+	lockedUntil, err := s.loginGuard.RecordFailure(ctx, email, ClientInfoFromContext(ctx).IPAddress)
 	if err != nil {
-		return nil, ErrInvalidToken
+		log.Printf("Failed to record login failure for %s: %v", email, err)
 	}
-
-	if session.ExpiresAt.Before(time.Now()) {
-		// Clean up expired session
-		s.sessionRepo.Delete(ctx, session.ID)
-		return nil, ErrTokenExpired
+	if user == nil {
+		return
 	}
 
-	// Generate new access token
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID.String(), user.Email, string(user.Role), user.Name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	auditLog := newAuditLog(ctx, user, models.AuditActionLogin, "user", user.ID.String(), nil, map[string]interface{}{
+		"success": false,
+		"reason":  reason,
+		"locked":  lockedUntil != nil,
+	})
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		log.Printf("Failed to audit login failure for user %s: %v", user.ID, err)
 	}
 
-	return &models.RefreshTokenResponse{
-		AccessToken: accessToken,
-		ExpiresIn:   3600, // 1 hour
-	}, nil
-}
-
-// Logout invalidates a user's session
-func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	session, err := s.sessionRepo.GetByToken(ctx, refreshToken)
-	if err != nil {
-		// Session not found, consider it already logged out
-		return nil
+	if lockedUntil != nil {
+		client := ClientInfoFromContext(ctx)
+		link := strings.TrimRight(s.frontendURL, "/") + "/forgot-password"
+		sendAccountEmail(s.mailer, loginLockedMessage(user, client.IPAddress, *lockedUntil, link))
 	}
-
-	return s.sessionRepo.Delete(ctx, session.ID)
 }
 
 // GetUserFromToken extracts and validates user information from an access token
@@ -308,6 +418,11 @@ func (s *AuthService) GetUserFromToken(ctx context.Context, token string) (*mode
 	return user, nil
 }
 
+// JWKS returns the public keys that verify tokens issued by this service
+func (s *AuthService) JWKS() auth.JWKSet {
+	return s.jwtManager.JWKS()
+}
+
 // ValidateRole checks if a user has the required role
 func (s *AuthService) ValidateRole(userRole models.Role, requiredRole models.Role) error {
 	roleHierarchy := map[models.Role]int{
@@ -325,68 +440,3 @@ func (s *AuthService) ValidateRole(userRole models.Role, requiredRole models.Rol
 
 	return nil
 }
-
-// ChangePassword allows a user to change their password
-func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *models.ChangePasswordRequest) error {
-	// Get current password
-	currentPassword, err := s.passwordRepo.GetByUserID(ctx, userID)
-	if err != nil {
-		return fmt.Errorf("failed to get current password: %w", err)
-	}
-
-	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(currentPassword.HashedPassword), []byte(req.CurrentPassword)); err != nil {
-		return ErrInvalidCredentials
-	}
-
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return fmt.Errorf("failed to hash new password: %w", err)
-	}
-
-	// Update password
-	currentPassword.HashedPassword = string(hashedPassword)
-	if err := s.passwordRepo.Update(ctx, currentPassword); err != nil {
-		return fmt.Errorf("failed to update password: %w", err)
-	}
-
-	// Invalidate all existing sessions for the user
-	sessions, err := s.sessionRepo.GetByUserID(ctx, userID)
-	if err == nil {
-		for _, session := range sessions {
-			s.sessionRepo.Delete(ctx, session.ID)
-		}
-	}
-
-	return nil
-}
-
-// ResetPassword initiates a password reset process
-func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
-	user, err := s.userRepo.GetByEmail(ctx, req.Email)
-	if err != nil {
-		// Don't reveal if email exists or not
-		return nil
-	}
-
-	if !user.IsActive {
-		return nil
-	}
-
-	// Generate reset token (in a real implementation, this would be sent via email)
-	resetToken := uuid.New().String()
-
-	// Store reset token (this would typically be stored in a separate table)
-	// For now, we'll just log it (in production, send via email)
-	fmt.Printf("Password reset token for %s: %s\n", user.Email, resetToken)
-
-	return nil
-}
-
-// ConfirmResetPassword completes the password reset process
-func (s *AuthService) ConfirmResetPassword(ctx context.Context, req *models.ConfirmResetPasswordRequest) error {
-	// In a real implementation, validate the reset token and extract user info
-	// For now, we'll return an error since we need the email or user ID
-	return fmt.Errorf("password reset confirmation not implemented - requires token validation")
-}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+// RefreshToken exchanges a refresh token for a new access and refresh token
+// pair. Each refresh token can be used once: its session is marked rotated
+// and a new session in the same family takes its place. Presenting a rotated
+// token again means it has leaked, so the whole family is revoked and the
+// attempt is recorded in the audit log.
+func (s *AuthService) RefreshToken(ctx context.Context, req *models.RefreshTokenRequest) (*models.RefreshTokenResponse, error) {
+	// Validate refresh token
+	claims, err := s.jwtManager.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Get user
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var resp *models.RefreshTokenResponse
+	reused := false
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		session, err := s.sessionRepo.FindByToken(ctx, req.RefreshToken)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+		if session.UserID != user.ID {
+			return ErrInvalidToken
+		}
+
+		familyID := session.FamilyID
+		if familyID == uuid.Nil {
+			familyID = session.ID
+		}
+
+		if session.RotatedAt != nil {
+			reused = true
+			return s.revokeReusedFamily(ctx, user, session, familyID)
+		}
+		if !session.IsActive {
+			return ErrInvalidToken
+		}
+		if session.ExpiresAt.Before(time.Now()) {
+			return ErrTokenExpired
+		}
+
+		// Check if user is still active
+		if !user.IsActive {
+			return ErrUserNotActive
+		}
+
+		accessToken, err := s.jwtManager.GenerateAccessToken(user.ID.String(), user.Email, string(user.Role), user.Name)
+		if err != nil {
+			return fmt.Errorf("failed to generate access token: %w", err)
+		}
+		refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID.String(), user.Email)
+		if err != nil {
+			return fmt.Errorf("failed to generate refresh token: %w", err)
+		}
+
+		now := time.Now()
+		session.IsActive = false
+		session.RotatedAt = &now
+		if err := s.sessionRepo.Update(ctx, session); err != nil {
+			return fmt.Errorf("failed to rotate session: %w", err)
+		}
+		if err := s.sessionRepo.Create(ctx, newSession(ctx, user.ID, refreshToken, familyID)); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+
+		resp = &models.RefreshTokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			ExpiresIn:    3600, // 1 hour
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return resp, nil
+}
+
+// revokeReusedFamily signs out every session descended from the login a
+// replayed refresh token belongs to and records the event
+func (s *AuthService) revokeReusedFamily(ctx context.Context, user *models.User, session *models.Session, familyID uuid.UUID) error {
+	if err := s.sessionRepo.RevokeFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke session family: %w", err)
+	}
+
+	auditLog := newAuditLog(ctx, user, models.AuditActionTokenReuse, "session", familyID.String(), nil, map[string]interface{}{
+		"sessionId": session.ID,
+		"rotatedAt": session.RotatedAt,
+	})
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	log.Printf("refresh token reuse detected for user %s, revoked session family %s", user.ID, familyID)
+	return nil
+}
+
+// newSession builds the session backing a refresh token. A nil familyID
+// starts a new family for a fresh login.
+func newSession(ctx context.Context, userID uuid.UUID, refreshToken string, familyID uuid.UUID) *models.Session {
+	client := ClientInfoFromContext(ctx)
+	id := uuid.New()
+	if familyID == uuid.Nil {
+		familyID = id
+	}
+	return &models.Session{
+		ID:           id,
+		UserID:       userID,
+		SessionToken: refreshToken,
+		UserAgent:    &client.UserAgent,
+		IPAddress:    &client.IPAddress,
+		ExpiresAt:    time.Now().Add(24 * time.Hour * 30), // 30 days
+		FamilyID:     familyID,
+	}
+}
+
+// Logout invalidates a user's session. When the access token used alongside
+// it is given, that token is revoked too rather than left to expire.
+func (s *AuthService) Logout(ctx context.Context, refreshToken, accessToken string) error {
+	if accessToken != "" {
+		if claims, err := s.jwtManager.ValidateAccessToken(accessToken); err == nil {
+			if err := s.revoker.RevokeToken(ctx, claims); err != nil {
+				return err
+			}
+		}
+	}
+
+	session, err := s.sessionRepo.GetByToken(ctx, refreshToken)
+	if err != nil {
+		// Session not found, consider it already logged out
+		return nil
+	}
+
+	return s.sessionRepo.Delete(ctx, session.ID)
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrInvalidBulkOperation = errors.New("invalid bulk operation")
+	ErrBulkEmpty            = errors.New("bulk operation has no items")
+	ErrBulkTooLarge         = errors.New("bulk operation has too many items")
+	ErrInvalidBulkItem      = errors.New("invalid item")
+)
+
+// errBulkRollback aborts the transaction of an atomic bulk operation after
+// one of its items failed
+var errBulkRollback = errors.New("bulk operation rolled back")
+
+// bulkItem is one unit of work in a bulk operation. Items that could not be
+// parsed carry err and are reported without being applied.
+type bulkItem struct {
+	id    string
+	err   error
+	apply func(ctx context.Context) error
+}
+
+// bulkActions builds the items of a bulk operation for a resource. create
+// and update decode the item's JSON payload themselves and return an item
+// carrying the error when it is invalid.
+type bulkActions struct {
+	create func(payload json.RawMessage) bulkItem
+	update func(id uuid.UUID, payload json.RawMessage) bulkItem
+	delete func(id uuid.UUID) bulkItem
+}
+
+// planBulk turns a bulk request into items, checking its shape and size
+func planBulk(op *models.BulkOperation, maxItems int, actions bulkActions) ([]bulkItem, error) {
+	var items []bulkItem
+
+	switch op.Operation {
+	case models.BulkOperationCreate:
+		if len(op.IDs) > 0 {
+			return nil, fmt.Errorf("%w: create takes payloads in data, not ids", ErrInvalidBulkOperation)
+		}
+		payloads, err := bulkPayloads(op.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, payload := range payloads {
+			items = append(items, actions.create(payload))
+		}
+	case models.BulkOperationUpdate:
+		if len(op.IDs) > 0 {
+			// One payload applied to every listed record
+			if !isJSONObject(op.Data) {
+				return nil, fmt.Errorf("%w: update with ids takes a single object in data", ErrInvalidBulkOperation)
+			}
+			for _, id := range op.IDs {
+				items = append(items, actions.update(id, op.Data))
+			}
+			break
+		}
+		payloads, err := bulkPayloads(op.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, payload := range payloads {
+			var target struct {
+				ID uuid.UUID `json:"id"`
+			}
+			if err := json.Unmarshal(payload, &target); err != nil || target.ID == uuid.Nil {
+				items = append(items, bulkItem{err: fmt.Errorf("%w: id is required", ErrInvalidBulkItem)})
+				continue
+			}
+			items = append(items, actions.update(target.ID, payload))
+		}
+	case models.BulkOperationDelete:
+		if len(op.Data) > 0 && string(op.Data) != "null" {
+			return nil, fmt.Errorf("%w: delete takes ids, not data", ErrInvalidBulkOperation)
+		}
+		for _, id := range op.IDs {
+			items = append(items, actions.delete(id))
+		}
+	default:
+		return nil, ErrInvalidBulkOperation
+	}
+
+	if len(items) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	if len(items) > maxItems {
+		return nil, ErrBulkTooLarge
+	}
+	return items, nil
+}
+
+// runBulk applies items in order. Best-effort operations apply each item on
+// its own and carry on past failures. Atomic operations apply everything in
+// one transaction, giving each item a savepoint so every failure is found
+// and reported before the whole operation is rolled back.
+func runBulk(ctx context.Context, db *gorm.DB, op *models.BulkOperation, items []bulkItem) (*models.BulkOperationResult, error) {
+	result := &models.BulkOperationResult{
+		Operation:      op.Operation,
+		TotalRequested: len(items),
+	}
+	record := func(index int, item bulkItem, err error) {
+		if err == nil {
+			result.Successful++
+			return
+		}
+		result.Failed++
+		result.Errors = append(result.Errors, models.BulkOperationError{
+			Index:   index,
+			ID:      item.id,
+			Message: bulkErrorMessage(err),
+		})
+	}
+
+	if !op.Atomic {
+		for i, item := range items {
+			err := item.err
+			if err == nil {
+				err = item.apply(ctx)
+			}
+			record(i, item, err)
+		}
+		return result, nil
+	}
+
+	for i, item := range items {
+		if item.err != nil {
+			record(i, item, item.err)
+		}
+	}
+	if result.Failed > 0 {
+		result.RolledBack = true
+		return result, nil
+	}
+
+	err := repository.RunInTx(ctx, db, func(ctx context.Context) error {
+		tx, _ := repository.TxFromContext(ctx)
+		for i, item := range items {
+			err := tx.Transaction(func(savepoint *gorm.DB) error {
+				return item.apply(repository.ContextWithTx(ctx, savepoint))
+			})
+			record(i, item, err)
+		}
+		if result.Failed > 0 {
+			return errBulkRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errBulkRollback) {
+		result.Successful = 0
+		result.RolledBack = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// bulkPayloads splits a JSON array of payloads
+func bulkPayloads(data json.RawMessage) ([]json.RawMessage, error) {
+	var payloads []json.RawMessage
+	if err := json.Unmarshal(data, &payloads); err != nil {
+		return nil, fmt.Errorf("%w: data must be an array of objects", ErrInvalidBulkOperation)
+	}
+	return payloads, nil
+}
+
+// bulkValidator checks the binding tags on request types, the rules the
+// single-record endpoints apply when gin binds a request
+var bulkValidator = newBulkValidator()
+
+func newBulkValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+
+// decodeBulkPayload reads one payload into a request type and validates it
+// with the same binding rules the single-record endpoints use
+func decodeBulkPayload(payload json.RawMessage, dest interface{}) error {
+	if !isJSONObject(payload) {
+		return fmt.Errorf("%w: expected an object", ErrInvalidBulkItem)
+	}
+	if err := json.Unmarshal(payload, dest); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBulkItem, err)
+	}
+	if err := bulkValidator.Struct(dest); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBulkItem, err)
+	}
+	return nil
+}
+
+func isJSONObject(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// bulkErrors lists the service errors shown to clients as they are when a
+// bulk item fails
+var bulkErrors = []error{
+	ErrProductNotFound,
+	ErrProductExists,
+	ErrInvalidStockLevels,
+	ErrInvalidStatus,
+	ErrCategoryNotFound,
+	ErrCategoryNameExists,
+	ErrCategoryInUse,
+	ErrCategoryHasChildren,
+	ErrInvalidParent,
+	ErrUserProfileNotFound,
+	ErrEmailAlreadyExists,
+	ErrCannotDeactivateAdmin,
+	ErrCannotDeleteOwnAccount,
+}
+
+// bulkErrorMessage describes a failed item. Service errors are shown as
+// they are; anything else is logged and reported generically.
+func bulkErrorMessage(err error) string {
+	if errors.Is(err, ErrInvalidBulkItem) {
+		return err.Error()
+	}
+	for _, known := range bulkErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	log.Printf("bulk operation: %v", err)
+	return "record could not be saved"
+}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrCartNotFound     = errors.New("cart not found")
+	ErrCartItemNotFound = errors.New("product is not in the cart")
+	ErrCartEmpty        = errors.New("cart is empty")
+	ErrCartNotActive    = errors.New("cart must be resumed first")
+	ErrCartHasIssues    = errors.New("cart has items that cannot be sold as is")
+)
+
+// CartIssuesError reports the problems found when validating a cart
+type CartIssuesError struct {
+	Issues []models.CartIssue
+}
+
+func (e *CartIssuesError) Error() string {
+	return fmt.Sprintf("cart has %d issue(s)", len(e.Issues))
+}
+
+func (e *CartIssuesError) Unwrap() error {
+	return ErrCartHasIssues
+}
+
+// Details returns the fields reported to API clients
+func (e *CartIssuesError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"issues": e.Issues,
+	}
+}
+
+// CartService manages draft carts that cashiers can park and resume
+type CartService struct {
+	cartRepo     repository.CartRepository
+	productRepo  repository.ProductRepository
+	transactions *TransactionService
+	db           *gorm.DB
+	heldTTL      time.Duration
+}
+
+// NewCartService creates a new cart service. Held carts untouched for
+// longer than heldTTL are removed by ExpireHeldCarts.
+func NewCartService(
+	cartRepo repository.CartRepository,
+	productRepo repository.ProductRepository,
+	transactions *TransactionService,
+	db *gorm.DB,
+	heldTTL time.Duration,
+) *CartService {
+	return &CartService{
+		cartRepo:     cartRepo,
+		productRepo:  productRepo,
+		transactions: transactions,
+		db:           db,
+		heldTTL:      heldTTL,
+	}
+}
+
+// ListCarts returns the cashier's carts, optionally filtered by status
+func (s *CartService) ListCarts(ctx context.Context, cashierID uuid.UUID, status *models.CartStatus) ([]models.Cart, error) {
+	carts, err := s.cartRepo.ListByCashierID(ctx, cashierID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list carts: %w", err)
+	}
+	return carts, nil
+}
+
+// CreateCart starts a new active cart, parking the cashier's current one
+func (s *CartService) CreateCart(ctx context.Context, cashierID uuid.UUID, req *models.CreateCartRequest) (*models.CartDetails, error) {
+	cart := &models.Cart{
+		ID:        uuid.New(),
+		CashierID: cashierID,
+		Name:      req.Name,
+		Status:    models.CartStatusActive,
+	}
+
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.parkActiveCarts(ctx, cashierID, uuid.Nil); err != nil {
+			return err
+		}
+		if err := s.cartRepo.Create(ctx, cart); err != nil {
+			return fmt.Errorf("failed to create cart: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, cashierID, cart.ID)
+}
+
+// GetCart returns a cart priced against current product data
+func (s *CartService) GetCart(ctx context.Context, cashierID, cartID uuid.UUID) (*models.CartDetails, error) {
+	cart, err := s.getOwnedCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+	return s.price(ctx, cart, false)
+}
+
+// RenameCart changes a cart's display name
+func (s *CartService) RenameCart(ctx context.Context, cashierID, cartID uuid.UUID, req *models.UpdateCartRequest) (*models.CartDetails, error) {
+	cart, err := s.getOwnedCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.Name = req.Name
+	if err := s.cartRepo.Update(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to update cart: %w", err)
+	}
+
+	return s.price(ctx, cart, false)
+}
+
+// DeleteCart discards a cart and its items
+func (s *CartService) DeleteCart(ctx context.Context, cashierID, cartID uuid.UUID) error {
+	if _, err := s.getOwnedCart(ctx, cashierID, cartID); err != nil {
+		return err
+	}
+	if err := s.cartRepo.Delete(ctx, cartID); err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
+	}
+	return nil
+}
+
+// AddItem adds a product to an active cart at its current price
+func (s *CartService) AddItem(ctx context.Context, cashierID, cartID uuid.UUID, req *models.AddCartItemRequest) (*models.CartDetails, error) {
+	cart, err := s.getActiveCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.saleableProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.CartItem{
+		ProductID: product.ID,
+		Quantity:  req.Quantity,
+		UnitPrice: product.Price,
+	}
+	if req.Discount != nil {
+		item.Discount = *req.Discount
+	}
+
+	if err := s.cartRepo.AddItem(ctx, cart.ID, item); err != nil {
+		return nil, fmt.Errorf("failed to add cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, cashierID, cart.ID)
+}
+
+// UpdateItem changes the quantity and discount of a cart line
+func (s *CartService) UpdateItem(ctx context.Context, cashierID, cartID, productID uuid.UUID, req *models.UpdateCartItemRequest) (*models.CartDetails, error) {
+	cart, err := s.getActiveCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.saleableProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.CartItem{
+		ProductID: productID,
+		Quantity:  req.Quantity,
+		UnitPrice: product.Price,
+	}
+	if req.Discount != nil {
+		item.Discount = *req.Discount
+	}
+
+	if err := s.cartRepo.UpdateItem(ctx, cart.ID, item); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartItemNotFound
+		}
+		return nil, fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, cashierID, cart.ID)
+}
+
+// RemoveItem removes a product from a cart
+func (s *CartService) RemoveItem(ctx context.Context, cashierID, cartID, productID uuid.UUID) (*models.CartDetails, error) {
+	cart, err := s.getActiveCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cartRepo.RemoveItem(ctx, cart.ID, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartItemNotFound
+		}
+		return nil, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, cashierID, cart.ID)
+}
+
+// HoldCart parks a cart so the cashier can serve another customer
+func (s *CartService) HoldCart(ctx context.Context, cashierID, cartID uuid.UUID, req *models.HoldCartRequest) (*models.CartDetails, error) {
+	cart, err := s.getOwnedCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	now := time.Now()
+	cart.Status = models.CartStatusHeld
+	cart.HeldAt = &now
+	if req.Name != nil {
+		cart.Name = req.Name
+	}
+	if err := s.cartRepo.Update(ctx, cart); err != nil {
+		return nil, fmt.Errorf("failed to hold cart: %w", err)
+	}
+
+	return s.price(ctx, cart, false)
+}
+
+// ResumeCart makes a held cart active again, parking whatever cart was
+// active. Prices are refreshed to current values and any price changes or
+// stock shortfalls are reported as issues on the returned cart.
+func (s *CartService) ResumeCart(ctx context.Context, cashierID, cartID uuid.UUID) (*models.CartDetails, error) {
+	var details *models.CartDetails
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		cart, err := s.getOwnedCart(ctx, cashierID, cartID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.parkActiveCarts(ctx, cashierID, cart.ID); err != nil {
+			return err
+		}
+
+		cart.Status = models.CartStatusActive
+		cart.HeldAt = nil
+		if err := s.cartRepo.Update(ctx, cart); err != nil {
+			return fmt.Errorf("failed to resume cart: %w", err)
+		}
+
+		details, err = s.price(ctx, cart, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// Checkout turns an active cart into a completed transaction and removes
+// the cart. Checkout is refused while the cart has unresolved stock or
+// availability issues.
+func (s *CartService) Checkout(ctx context.Context, cashierID, cartID uuid.UUID, req *models.CheckoutCartRequest) (*models.Transaction, error) {
+	var transaction *models.Transaction
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		cart, err := s.getActiveCart(ctx, cashierID, cartID)
+		if err != nil {
+			return err
+		}
+		if len(cart.Items) == 0 {
+			return ErrCartEmpty
+		}
+
+		details, err := s.price(ctx, cart, false)
+		if err != nil {
+			return err
+		}
+		var blocking []models.CartIssue
+		for _, issue := range details.Issues {
+			if issue.Code != models.CartIssuePriceChanged {
+				blocking = append(blocking, issue)
+			}
+		}
+		if len(blocking) > 0 {
+			return &CartIssuesError{Issues: blocking}
+		}
+
+		transaction, err = s.transactions.Checkout(ctx, cashierID, req.ToTransactionRequest(cart.Items))
+		if err != nil {
+			return err
+		}
+
+		if err := s.cartRepo.Delete(ctx, cart.ID); err != nil {
+			return fmt.Errorf("failed to delete cart: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// ExpireHeldCarts removes held carts untouched for longer than the
+// configured TTL
+func (s *CartService) ExpireHeldCarts(ctx context.Context) (int64, error) {
+	if s.heldTTL <= 0 {
+		return 0, nil
+	}
+	deleted, err := s.cartRepo.DeleteHeldBefore(ctx, time.Now().Add(-s.heldTTL))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire held carts: %w", err)
+	}
+	return deleted, nil
+}
+
+// getOwnedCart loads a cart, hiding carts that belong to other cashiers
+func (s *CartService) getOwnedCart(ctx context.Context, cashierID, cartID uuid.UUID) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetByID(ctx, cartID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCartNotFound
+		}
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if cart.CashierID != cashierID {
+		return nil, ErrCartNotFound
+	}
+	return cart, nil
+}
+
+// getActiveCart loads a cart that can be edited
+func (s *CartService) getActiveCart(ctx context.Context, cashierID, cartID uuid.UUID) (*models.Cart, error) {
+	cart, err := s.getOwnedCart(ctx, cashierID, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cart.Status != models.CartStatusActive {
+		return nil, ErrCartNotActive
+	}
+	return cart, nil
+}
+
+// parkActiveCarts holds the cashier's active carts other than keepID, and
+// drops the ones that are empty
+func (s *CartService) parkActiveCarts(ctx context.Context, cashierID, keepID uuid.UUID) error {
+	status := models.CartStatusActive
+	active, err := s.cartRepo.ListByCashierID(ctx, cashierID, &status)
+	if err != nil {
+		return fmt.Errorf("failed to list active carts: %w", err)
+	}
+
+	now := time.Now()
+	for i := range active {
+		cart := &active[i]
+		if cart.ID == keepID {
+			continue
+		}
+		if len(cart.Items) == 0 {
+			if err := s.cartRepo.Delete(ctx, cart.ID); err != nil {
+				return fmt.Errorf("failed to delete empty cart: %w", err)
+			}
+			continue
+		}
+		cart.Status = models.CartStatusHeld
+		cart.HeldAt = &now
+		if err := s.cartRepo.Update(ctx, cart); err != nil {
+			return fmt.Errorf("failed to hold cart: %w", err)
+		}
+	}
+	return nil
+}
+
+// saleableProduct loads a product that can currently be added to a cart
+func (s *CartService) saleableProduct(ctx context.Context, productID uuid.UUID) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if !product.IsActive || product.Status != models.ProductStatusActive {
+		return nil, ErrProductUnavailable
+	}
+	return product, nil
+}
+
+// price builds the cart details from current product data. With refresh,
+// lines whose price changed since they were last validated are updated to
+// the current price.
+func (s *CartService) price(ctx context.Context, cart *models.Cart, refresh bool) (*models.CartDetails, error) {
+	taxRate, err := s.transactions.taxRate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &models.CartDetails{Cart: *cart, Lines: make([]models.CartLine, 0, len(cart.Items))}
+	details.Items = nil // reported as Lines
+	for _, item := range cart.Items {
+		line := models.CartLine{CartItem: item}
+
+		product, err := s.productRepo.GetByID(ctx, item.ProductID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			details.Issues = append(details.Issues, models.CartIssue{
+				ProductID: item.ProductID,
+				Code:      models.CartIssueUnavailable,
+				Message:   "product no longer exists",
+			})
+			details.Lines = append(details.Lines, line)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to get product: %w", err)
+		}
+
+		line.ProductName = product.Name
+		line.ProductSKU = product.SKU
+		line.Available = product.Stock
+
+		if !product.IsActive || product.Status != models.ProductStatusActive {
+			details.Issues = append(details.Issues, models.CartIssue{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				Code:        models.CartIssueUnavailable,
+				Message:     "product is not available for sale",
+			})
+		}
+		if product.Stock < item.Quantity {
+			details.Issues = append(details.Issues, models.CartIssue{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				Code:        models.CartIssueInsufficientStock,
+				Message:     fmt.Sprintf("only %d in stock", product.Stock),
+			})
+		}
+		if item.UnitPrice != product.Price {
+			details.Issues = append(details.Issues, models.CartIssue{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				Code:        models.CartIssuePriceChanged,
+				Message:     fmt.Sprintf("price changed from %.2f to %.2f", item.UnitPrice, product.Price),
+			})
+			if refresh {
+				line.UnitPrice = product.Price
+				if err := s.cartRepo.UpdateItem(ctx, cart.ID, &line.CartItem); err != nil {
+					return nil, fmt.Errorf("failed to refresh cart item: %w", err)
+				}
+			}
+		}
+
+		line.UnitPrice = product.Price
+		line.Subtotal = roundMoney(product.Price * float64(item.Quantity))
+		details.Subtotal += line.Subtotal
+		details.DiscountAmount += item.Discount
+		details.Lines = append(details.Lines, line)
+	}
+
+	details.Subtotal = roundMoney(details.Subtotal)
+	details.DiscountAmount = roundMoney(details.DiscountAmount)
+	taxable := details.Subtotal - details.DiscountAmount
+	if taxable < 0 {
+		taxable = 0
+	}
+	details.TaxAmount = roundMoney(taxable * taxRate)
+	details.Total = roundMoney(taxable + details.TaxAmount)
+
+	return details, nil
+}
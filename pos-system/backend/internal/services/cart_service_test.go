@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+func newTestCart(cashierID uuid.UUID, status models.CartStatus, items ...models.CartItem) *models.Cart {
+	cart := &models.Cart{ID: uuid.New(), CashierID: cashierID, Status: status, Items: items}
+	if status == models.CartStatusHeld {
+		heldAt := time.Now().Add(-time.Hour)
+		cart.HeldAt = &heldAt
+	}
+	return cart
+}
+
+func newTestCartService(carts *fakeCartRepo, products *fakeProductRepo) *CartService {
+	transactions := NewTransactionService(nil, products, nil, &fakeSystemConfigRepo{}, nil, nil, nil, nil, 0.10)
+	return NewCartService(carts, products, transactions, nil, 24*time.Hour)
+}
+
+func TestResumeCartRevalidates(t *testing.T) {
+	cashierID := uuid.New()
+
+	tests := []struct {
+		name      string
+		product   func(p *models.Product)
+		quantity  int
+		heldPrice float64
+		wantCodes []string
+		wantPrice float64 // the line's price after resuming
+	}{
+		{
+			name:      "unchanged",
+			quantity:  2,
+			heldPrice: 2.50,
+			wantPrice: 2.50,
+		},
+		{
+			name:      "price went up",
+			quantity:  2,
+			heldPrice: 2.00,
+			wantCodes: []string{models.CartIssuePriceChanged},
+			wantPrice: 2.50,
+		},
+		{
+			name:      "stock ran low while parked",
+			product:   func(p *models.Product) { p.Stock = 1 },
+			quantity:  2,
+			heldPrice: 2.50,
+			wantCodes: []string{models.CartIssueInsufficientStock},
+			wantPrice: 2.50,
+		},
+		{
+			name:      "exactly the stock left",
+			product:   func(p *models.Product) { p.Stock = 2 },
+			quantity:  2,
+			heldPrice: 2.50,
+			wantPrice: 2.50,
+		},
+		{
+			name:      "discontinued and repriced",
+			product:   func(p *models.Product) { p.Status = models.ProductStatusDiscontinued; p.Price = 3.00 },
+			quantity:  1,
+			heldPrice: 2.50,
+			wantCodes: []string{models.CartIssueUnavailable, models.CartIssuePriceChanged},
+			wantPrice: 3.00,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := newTestProduct("coffee", 2.50, 1.00, 10)
+			if tt.product != nil {
+				tt.product(product)
+			}
+			held := newTestCart(cashierID, models.CartStatusHeld,
+				models.CartItem{ProductID: product.ID, Quantity: tt.quantity, UnitPrice: tt.heldPrice})
+			carts := newFakeCartRepo(held)
+			service := newTestCartService(carts, newFakeProductRepo(product))
+
+			details, err := service.ResumeCart(txContext(), cashierID, held.ID)
+			if err != nil {
+				t.Fatalf("ResumeCart failed: %v", err)
+			}
+
+			var codes []string
+			for _, issue := range details.Issues {
+				codes = append(codes, issue.Code)
+			}
+			if len(codes) != len(tt.wantCodes) {
+				t.Fatalf("Expected issues %v, got %v", tt.wantCodes, codes)
+			}
+			for i := range codes {
+				if codes[i] != tt.wantCodes[i] {
+					t.Errorf("Expected issues %v, got %v", tt.wantCodes, codes)
+				}
+			}
+
+			if details.Status != models.CartStatusActive || details.HeldAt != nil {
+				t.Errorf("Expected the cart active again, got %s", details.Status)
+			}
+			if stored := carts.carts[held.ID].Items[0].UnitPrice; stored != tt.wantPrice {
+				t.Errorf("Expected the stored price refreshed to %.2f, got %.2f", tt.wantPrice, stored)
+			}
+			wantSubtotal := roundMoney(tt.wantPrice * float64(tt.quantity))
+			if details.Subtotal != wantSubtotal {
+				t.Errorf("Expected subtotal %.2f at current prices, got %.2f", wantSubtotal, details.Subtotal)
+			}
+		})
+	}
+}
+
+func TestResumeCartDeletedProduct(t *testing.T) {
+	cashierID := uuid.New()
+	held := newTestCart(cashierID, models.CartStatusHeld,
+		models.CartItem{ProductID: uuid.New(), Quantity: 1, UnitPrice: 2.50})
+	service := newTestCartService(newFakeCartRepo(held), newFakeProductRepo())
+
+	details, err := service.ResumeCart(txContext(), cashierID, held.ID)
+	if err != nil {
+		t.Fatalf("ResumeCart failed: %v", err)
+	}
+	if len(details.Issues) != 1 || details.Issues[0].Code != models.CartIssueUnavailable {
+		t.Errorf("Expected an unavailable issue for a deleted product, got %+v", details.Issues)
+	}
+	if details.Subtotal != 0 {
+		t.Errorf("Expected a deleted product left out of the totals, got %.2f", details.Subtotal)
+	}
+}
+
+func TestResumeCartParksActiveCart(t *testing.T) {
+	cashierID := uuid.New()
+	product := newTestProduct("coffee", 2.50, 1.00, 10)
+	item := models.CartItem{ProductID: product.ID, Quantity: 1, UnitPrice: 2.50}
+
+	held := newTestCart(cashierID, models.CartStatusHeld, item)
+	active := newTestCart(cashierID, models.CartStatusActive, item)
+	empty := newTestCart(cashierID, models.CartStatusActive)
+	otherCashier := newTestCart(uuid.New(), models.CartStatusActive, item)
+	carts := newFakeCartRepo(held, active, empty, otherCashier)
+	service := newTestCartService(carts, newFakeProductRepo(product))
+
+	if _, err := service.ResumeCart(txContext(), cashierID, held.ID); err != nil {
+		t.Fatalf("ResumeCart failed: %v", err)
+	}
+
+	if carts.carts[held.ID].Status != models.CartStatusActive {
+		t.Error("Expected the resumed cart to be active")
+	}
+	if carts.carts[active.ID].Status != models.CartStatusHeld || carts.carts[active.ID].HeldAt == nil {
+		t.Error("Expected the previously active cart to be parked")
+	}
+	if _, ok := carts.carts[empty.ID]; ok {
+		t.Error("Expected the empty active cart to be dropped")
+	}
+	if carts.carts[otherCashier.ID].Status != models.CartStatusActive {
+		t.Error("Expected another cashier's cart to be left alone")
+	}
+
+	if _, err := service.ResumeCart(txContext(), otherCashier.CashierID, held.ID); !errors.Is(err, ErrCartNotFound) {
+		t.Errorf("Expected ErrCartNotFound resuming another cashier's cart, got %v", err)
+	}
+}
+
+func TestCartCheckoutBlockedByIssues(t *testing.T) {
+	cashierID := uuid.New()
+	product := newTestProduct("coffee", 2.50, 1.00, 1)
+	active := newTestCart(cashierID, models.CartStatusActive,
+		models.CartItem{ProductID: product.ID, Quantity: 3, UnitPrice: 2.50})
+	carts := newFakeCartRepo(active)
+	service := newTestCartService(carts, newFakeProductRepo(product))
+
+	_, err := service.Checkout(txContext(), cashierID, active.ID, &models.CheckoutCartRequest{})
+	var issuesErr *CartIssuesError
+	if !errors.As(err, &issuesErr) {
+		t.Fatalf("Expected a *CartIssuesError, got %v", err)
+	}
+	if len(issuesErr.Issues) != 1 || issuesErr.Issues[0].Code != models.CartIssueInsufficientStock {
+		t.Errorf("Expected a single stock issue, got %+v", issuesErr.Issues)
+	}
+	if _, ok := carts.carts[active.ID]; !ok {
+		t.Error("Expected the cart kept when checkout is refused")
+	}
+}
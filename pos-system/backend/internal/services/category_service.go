@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryNameExists  = errors.New("a category with this name already exists")
+	ErrCategoryInUse       = errors.New("category still has products")
+	ErrCategoryHasChildren = errors.New("category still has subcategories")
+	ErrInvalidParent       = errors.New("invalid parent category")
+)
+
+// CategoryService handles product category management
+type CategoryService struct {
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+	auditRepo    repository.AuditLogRepository
+	db           *gorm.DB
+	maxBulkItems int
+}
+
+// NewCategoryService creates a new category service. Bulk operations are
+// limited to maxBulkItems records.
+func NewCategoryService(
+	categoryRepo repository.CategoryRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	maxBulkItems int,
+) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+		maxBulkItems: maxBulkItems,
+	}
+}
+
+// ListCategories returns a page of categories with their product counts
+func (s *CategoryService) ListCategories(ctx context.Context, pagination *models.PaginationQuery) ([]models.CategoryWithProductCount, int64, error) {
+	categories, total, err := s.categoryRepo.ListWithProductCount(ctx, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, total, nil
+}
+
+// GetTree returns the category hierarchy starting from root categories
+func (s *CategoryService) GetTree(ctx context.Context) ([]models.Category, error) {
+	tree, err := s.categoryRepo.GetTree(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category tree: %w", err)
+	}
+	return tree, nil
+}
+
+// GetCategory retrieves a category by ID
+func (s *CategoryService) GetCategory(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return category, nil
+}
+
+// GetCategoryWithProducts retrieves a category and the products in it
+func (s *CategoryService) GetCategoryWithProducts(ctx context.Context, id uuid.UUID) (*models.CategoryWithProducts, error) {
+	category, err := s.categoryRepo.GetWithProducts(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return category, nil
+}
+
+// CreateCategory creates a new category
+func (s *CategoryService) CreateCategory(ctx context.Context, userID uuid.UUID, req *models.CreateCategoryRequest) (*models.Category, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureNameAvailable(ctx, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	category := &models.Category{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+		SortOrder:   req.SortOrder,
+	}
+	if req.ParentID != nil {
+		if err := s.validateParent(ctx, category.ID, *req.ParentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = req.ParentID
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.categoryRepo.Create(ctx, category); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrCategoryNameExists
+			}
+			return fmt.Errorf("failed to create category: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionCreateCategory, "category", category.ID.String(), nil, categoryAuditValues(category))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategory(ctx, category.ID)
+}
+
+// UpdateCategory applies the non-nil fields of req to a category
+func (s *CategoryService) UpdateCategory(ctx context.Context, userID, id uuid.UUID, req *models.UpdateCategoryRequest) (*models.Category, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := s.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldValues := categoryAuditValues(category)
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := s.ensureNameAvailable(ctx, name, id); err != nil {
+			return nil, err
+		}
+		category.Name = name
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == uuid.Nil {
+			category.ParentID = nil
+		} else {
+			if err := s.validateParent(ctx, id, *req.ParentID); err != nil {
+				return nil, err
+			}
+			category.ParentID = req.ParentID
+		}
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+
+	category.Parent = nil
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.categoryRepo.Update(ctx, category); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrCategoryNameExists
+			}
+			return fmt.Errorf("failed to update category: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionUpdateCategory, "category", id.String(), oldValues, categoryAuditValues(category))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategory(ctx, id)
+}
+
+// DeleteCategory removes a category that has no products or subcategories.
+// Products reference categories with ON DELETE RESTRICT, and the check is
+// done up front because categories are soft deleted.
+func (s *CategoryService) DeleteCategory(ctx context.Context, userID, id uuid.UUID) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		category, err := s.GetCategory(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		products, err := s.categoryRepo.CountProducts(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to count category products: %w", err)
+		}
+		if products > 0 {
+			return ErrCategoryInUse
+		}
+
+		children, err := s.categoryRepo.CountChildren(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to count subcategories: %w", err)
+		}
+		if children > 0 {
+			return ErrCategoryHasChildren
+		}
+
+		if err := s.categoryRepo.Delete(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				return ErrCategoryInUse
+			}
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionDeleteCategory, "category", id.String(), categoryAuditValues(category), nil)
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+// BulkCategories creates, updates or deletes many categories at once. Each
+// record goes through the single-category path, so it is validated and
+// audited the same way.
+func (s *CategoryService) BulkCategories(ctx context.Context, userID uuid.UUID, op *models.BulkOperation) (*models.BulkOperationResult, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := planBulk(op, s.maxBulkItems, bulkActions{
+		create: func(payload json.RawMessage) bulkItem {
+			var req models.CreateCategoryRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{err: err}
+			}
+			return bulkItem{id: req.Name, apply: func(ctx context.Context) error {
+				_, err := s.CreateCategory(ctx, userID, &req)
+				return err
+			}}
+		},
+		update: func(id uuid.UUID, payload json.RawMessage) bulkItem {
+			var req models.UpdateCategoryRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{id: id.String(), err: err}
+			}
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				_, err := s.UpdateCategory(ctx, userID, id, &req)
+				return err
+			}}
+		},
+		delete: func(id uuid.UUID) bulkItem {
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				return s.DeleteCategory(ctx, userID, id)
+			}}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return runBulk(ctx, s.db, op, items)
+}
+
+func (s *CategoryService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// categoryAuditValues captures the fields tracked in category audit entries
+func categoryAuditValues(category *models.Category) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      category.Name,
+		"parentId":  category.ParentID,
+		"isActive":  category.IsActive,
+		"sortOrder": category.SortOrder,
+	}
+}
+
+// ensureNameAvailable checks that no other category uses name
+func (s *CategoryService) ensureNameAvailable(ctx context.Context, name string, selfID uuid.UUID) error {
+	existing, err := s.categoryRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check category name: %w", err)
+	}
+	if existing.ID != selfID {
+		return ErrCategoryNameExists
+	}
+	return nil
+}
+
+// validateParent checks that parentID exists and is not the category itself
+// or one of its descendants
+func (s *CategoryService) validateParent(ctx context.Context, categoryID, parentID uuid.UUID) error {
+	if parentID == categoryID {
+		return ErrInvalidParent
+	}
+
+	seen := map[uuid.UUID]bool{categoryID: true}
+	for current := &parentID; current != nil; {
+		if seen[*current] {
+			return ErrInvalidParent
+		}
+		seen[*current] = true
+
+		parent, err := s.categoryRepo.GetByID(ctx, *current)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidParent
+			}
+			return fmt.Errorf("failed to get parent category: %w", err)
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
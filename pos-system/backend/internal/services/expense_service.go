@@ -0,0 +1,479 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/storage"
+)
+
+var (
+	ErrExpenseNotFound        = errors.New("expense not found")
+	ErrExpenseImmutable       = errors.New("approved expenses cannot be changed")
+	ErrExpenseNotPending      = errors.New("expense has already been reviewed")
+	ErrInvalidExpenseCategory = errors.New("invalid expense category")
+	ErrReceiptNotFound        = errors.New("expense has no receipt")
+	ErrFileTooLarge           = errors.New("file exceeds the maximum upload size")
+	ErrFileTypeNotAllowed     = errors.New("file type is not allowed")
+)
+
+// receiptPrefix is the storage directory for expense receipts
+const receiptPrefix = "receipts"
+
+// ExpenseService handles expense submission, approval and receipts
+type ExpenseService struct {
+	expenseRepo  repository.ExpenseRepository
+	userRepo     repository.UserRepository
+	auditRepo    repository.AuditLogRepository
+	db           *gorm.DB
+	files        *storage.LocalStore
+	maxFileSize  int64
+	allowedTypes []string
+}
+
+// NewExpenseService creates a new expense service. Receipts are kept in
+// files and must be at most maxFileSize bytes of one of allowedTypes.
+func NewExpenseService(
+	expenseRepo repository.ExpenseRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	files *storage.LocalStore,
+	maxFileSize int64,
+	allowedTypes []string,
+) *ExpenseService {
+	return &ExpenseService{
+		expenseRepo:  expenseRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+		files:        files,
+		maxFileSize:  maxFileSize,
+		allowedTypes: allowedTypes,
+	}
+}
+
+// ListExpenses returns a page of expenses
+func (s *ExpenseService) ListExpenses(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.Expense, int64, error) {
+	expenses, total, err := s.expenseRepo.List(ctx, filters, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list expenses: %w", err)
+	}
+	return expenses, total, nil
+}
+
+// GetExpense retrieves an expense by ID
+func (s *ExpenseService) GetExpense(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	expense, err := s.expenseRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, fmt.Errorf("failed to get expense: %w", err)
+	}
+	return expense, nil
+}
+
+// CreateExpense submits a new expense for approval
+func (s *ExpenseService) CreateExpense(ctx context.Context, userID uuid.UUID, req *models.CreateExpenseRequest) (*models.Expense, error) {
+	if !models.ValidateExpenseCategory(string(req.Category)) {
+		return nil, ErrInvalidExpenseCategory
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expense := &models.Expense{
+		ID:          uuid.New(),
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		Amount:      roundMoney(req.Amount),
+		Category:    req.Category,
+		Date:        req.Date,
+		Status:      models.ExpenseStatusPending,
+		CreatedBy:   userID,
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.expenseRepo.Create(ctx, expense); err != nil {
+			return fmt.Errorf("failed to create expense: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionCreateExpense, "expense", expense.ID.String(), nil, expenseAuditValues(expense))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetExpense(ctx, expense.ID)
+}
+
+// UpdateExpense applies the non-nil fields of req. Approved expenses cannot
+// change; editing a rejected expense sends it back for approval.
+func (s *ExpenseService) UpdateExpense(ctx context.Context, userID, id uuid.UUID, req *models.UpdateExpenseRequest) (*models.Expense, error) {
+	if req.Category != nil && !models.ValidateExpenseCategory(string(*req.Category)) {
+		return nil, ErrInvalidExpenseCategory
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		expense, err := s.lockEditable(ctx, user, id)
+		if err != nil {
+			return err
+		}
+		oldValues := expenseAuditValues(expense)
+
+		if req.Title != nil {
+			expense.Title = strings.TrimSpace(*req.Title)
+		}
+		if req.Description != nil {
+			expense.Description = req.Description
+		}
+		if req.Amount != nil {
+			expense.Amount = roundMoney(*req.Amount)
+		}
+		if req.Category != nil {
+			expense.Category = *req.Category
+		}
+		if req.Date != nil {
+			expense.Date = *req.Date
+		}
+		resubmit(expense)
+
+		if err := s.expenseRepo.Update(ctx, expense); err != nil {
+			return fmt.Errorf("failed to update expense: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionUpdateExpense, "expense", id.String(), oldValues, expenseAuditValues(expense))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetExpense(ctx, id)
+}
+
+// DeleteExpense removes an expense that has not been approved, along with
+// its receipt file
+func (s *ExpenseService) DeleteExpense(ctx context.Context, userID, id uuid.UUID) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var receipt *string
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		expense, err := s.lockEditable(ctx, user, id)
+		if err != nil {
+			return err
+		}
+		receipt = expense.Receipt
+
+		if err := s.expenseRepo.Delete(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrExpenseNotFound
+			}
+			return fmt.Errorf("failed to delete expense: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionDeleteExpense, "expense", id.String(), expenseAuditValues(expense), nil)
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if receipt != nil {
+		if err := s.files.Remove(*receipt); err != nil {
+			return fmt.Errorf("failed to remove receipt: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApproveExpense approves a pending expense, after which it is immutable
+func (s *ExpenseService) ApproveExpense(ctx context.Context, managerID, id uuid.UUID) (*models.Expense, error) {
+	return s.review(ctx, managerID, id, func(ctx context.Context) error {
+		return s.expenseRepo.Approve(ctx, id, managerID)
+	})
+}
+
+// RejectExpense rejects a pending expense with a reason for the submitter
+func (s *ExpenseService) RejectExpense(ctx context.Context, managerID, id uuid.UUID, req *models.RejectExpenseRequest) (*models.Expense, error) {
+	reason := strings.TrimSpace(req.Reason)
+	return s.review(ctx, managerID, id, func(ctx context.Context) error {
+		return s.expenseRepo.Reject(ctx, id, managerID, reason)
+	})
+}
+
+// UploadReceipt stores a receipt file for an expense, replacing any earlier
+// one. size is the declared length of the upload; the content type is
+// detected from the data rather than trusted from the client.
+func (s *ExpenseService) UploadReceipt(ctx context.Context, userID, id uuid.UUID, size int64, file io.Reader) (*models.Expense, error) {
+	if s.maxFileSize > 0 && size > s.maxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !s.allowedType(contentType) {
+		return nil, ErrFileTypeNotAllowed
+	}
+	key := path.Join(receiptPrefix, id.String()+extensionFor(contentType))
+
+	var previous *string
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		expense, err := s.lockEditable(ctx, user, id)
+		if err != nil {
+			return err
+		}
+		oldValues := expenseAuditValues(expense)
+		previous = expense.Receipt
+
+		body := io.MultiReader(bytes.NewReader(head), file)
+		if s.maxFileSize > 0 {
+			body = io.LimitReader(body, s.maxFileSize+1)
+		}
+		written, err := s.files.Save(key, body)
+		if err != nil {
+			return fmt.Errorf("failed to store receipt: %w", err)
+		}
+		if s.maxFileSize > 0 && written > s.maxFileSize {
+			s.files.Remove(key)
+			return ErrFileTooLarge
+		}
+
+		expense.Receipt = &key
+		resubmit(expense)
+		if err := s.expenseRepo.Update(ctx, expense); err != nil {
+			s.files.Remove(key)
+			return fmt.Errorf("failed to update expense: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionUpdateExpense, "expense", id.String(), oldValues, expenseAuditValues(expense))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A receipt of a different type is stored under a different key
+	if previous != nil && *previous != key {
+		s.files.Remove(*previous)
+	}
+
+	return s.GetExpense(ctx, id)
+}
+
+// OpenReceipt opens an expense's receipt file and returns it with its
+// content type. The caller must close the file.
+func (s *ExpenseService) OpenReceipt(ctx context.Context, id uuid.UUID) (*os.File, string, error) {
+	expense, err := s.GetExpense(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if expense.Receipt == nil {
+		return nil, "", ErrReceiptNotFound
+	}
+
+	file, err := s.files.Open(*expense.Receipt)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", ErrReceiptNotFound
+		}
+		return nil, "", fmt.Errorf("failed to open receipt: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(*expense.Receipt))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return file, contentType, nil
+}
+
+// review runs an approve or reject update as managerID and records it
+func (s *ExpenseService) review(ctx context.Context, managerID, id uuid.UUID, update func(ctx context.Context) error) (*models.Expense, error) {
+	manager, err := s.getUser(ctx, managerID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager.IsManager() {
+		return nil, ErrInsufficientRole
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		expense, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		if expense.Status != models.ExpenseStatusPending {
+			return ErrExpenseNotPending
+		}
+		oldValues := expenseAuditValues(expense)
+
+		if err := update(ctx); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrExpenseNotPending
+			}
+			return fmt.Errorf("failed to review expense: %w", err)
+		}
+
+		reviewed, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		newValues := expenseAuditValues(reviewed)
+		if reviewed.RejectionReason != nil {
+			newValues["rejectionReason"] = *reviewed.RejectionReason
+		}
+
+		auditLog := newAuditLog(ctx, manager, models.AuditActionUpdateExpense, "expense", id.String(), oldValues, newValues)
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetExpense(ctx, id)
+}
+
+// lockEditable locks an expense that user may change: it must not be
+// approved, and only its submitter or a manager may edit it
+func (s *ExpenseService) lockEditable(ctx context.Context, user *models.User, id uuid.UUID) (*models.Expense, error) {
+	expense, err := s.lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expense.CreatedBy != user.ID && !user.IsManager() {
+		return nil, ErrExpenseNotFound
+	}
+	if expense.IsApproved() {
+		return nil, ErrExpenseImmutable
+	}
+	return expense, nil
+}
+
+func (s *ExpenseService) lock(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	expense, err := s.expenseRepo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, fmt.Errorf("failed to lock expense: %w", err)
+	}
+	return expense, nil
+}
+
+func (s *ExpenseService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *ExpenseService) allowedType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, allowed := range s.allowedTypes {
+		if strings.EqualFold(allowed, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// resubmit puts a rejected expense back into the approval queue after an edit
+func resubmit(expense *models.Expense) {
+	if expense.Status != models.ExpenseStatusRejected {
+		return
+	}
+	expense.Status = models.ExpenseStatusPending
+	expense.RejectedBy = nil
+	expense.RejectedAt = nil
+	expense.RejectionReason = nil
+}
+
+// extensionFor picks a file extension for a detected content type
+func extensionFor(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "application/pdf":
+		return ".pdf"
+	}
+	if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
+
+func expenseAuditValues(expense *models.Expense) map[string]interface{} {
+	values := map[string]interface{}{
+		"title":    expense.Title,
+		"amount":   expense.Amount,
+		"category": expense.Category,
+		"date":     expense.Date,
+		"status":   expense.Status,
+	}
+	if expense.Receipt != nil {
+		values["receipt"] = *expense.Receipt
+	}
+	return values
+}
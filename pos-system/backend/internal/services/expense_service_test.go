@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type expenseFixture struct {
+	service  *ExpenseService
+	expenses *fakeExpenseRepo
+	audit    *fakeAuditRepo
+	cashier  *models.User
+	manager  *models.User
+	expense  *models.Expense
+}
+
+// newExpenseFixture has a cashier submit a 42.50 supplies expense
+func newExpenseFixture(t *testing.T) *expenseFixture {
+	t.Helper()
+	f := &expenseFixture{
+		expenses: newFakeExpenseRepo(),
+		audit:    &fakeAuditRepo{},
+		cashier:  &models.User{ID: uuid.New(), Name: "Casey", Role: models.RoleCashier, IsActive: true},
+		manager:  &models.User{ID: uuid.New(), Name: "Morgan", Role: models.RoleManager, IsActive: true},
+	}
+	f.service = NewExpenseService(f.expenses, newFakeUserRepo(f.cashier, f.manager), f.audit, nil, nil, 0, nil)
+
+	expense, err := f.service.CreateExpense(txContext(), f.cashier.ID, &models.CreateExpenseRequest{
+		Title:    "Till rolls",
+		Amount:   42.50,
+		Category: models.ExpenseCategorySupplies,
+		Date:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("CreateExpense failed: %v", err)
+	}
+	if expense.Status != models.ExpenseStatusPending {
+		t.Fatalf("Expected a new expense to be pending, got %s", expense.Status)
+	}
+	f.expense = expense
+	return f
+}
+
+func TestExpenseImmutableAfterApproval(t *testing.T) {
+	f := newExpenseFixture(t)
+	if _, err := f.service.ApproveExpense(txContext(), f.manager.ID, f.expense.ID); err != nil {
+		t.Fatalf("ApproveExpense failed: %v", err)
+	}
+
+	amount := 1000.00
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "submitter edits",
+			call: func() error {
+				_, err := f.service.UpdateExpense(txContext(), f.cashier.ID, f.expense.ID, &models.UpdateExpenseRequest{Amount: &amount})
+				return err
+			},
+			wantErr: ErrExpenseImmutable,
+		},
+		{
+			name: "manager edits",
+			call: func() error {
+				_, err := f.service.UpdateExpense(txContext(), f.manager.ID, f.expense.ID, &models.UpdateExpenseRequest{Amount: &amount})
+				return err
+			},
+			wantErr: ErrExpenseImmutable,
+		},
+		{
+			name:    "submitter deletes",
+			call:    func() error { return f.service.DeleteExpense(txContext(), f.cashier.ID, f.expense.ID) },
+			wantErr: ErrExpenseImmutable,
+		},
+		{
+			name: "manager approves again",
+			call: func() error {
+				_, err := f.service.ApproveExpense(txContext(), f.manager.ID, f.expense.ID)
+				return err
+			},
+			wantErr: ErrExpenseNotPending,
+		},
+		{
+			name: "manager rejects",
+			call: func() error {
+				_, err := f.service.RejectExpense(txContext(), f.manager.ID, f.expense.ID, &models.RejectExpenseRequest{Reason: "too late"})
+				return err
+			},
+			wantErr: ErrExpenseNotPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditBefore := len(f.audit.logs)
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			stored := f.expenses.expenses[f.expense.ID]
+			if stored == nil || stored.Status != models.ExpenseStatusApproved || stored.Amount != 42.50 {
+				t.Errorf("Expected the approved expense to be unchanged, got %+v", stored)
+			}
+			if len(f.audit.logs) != auditBefore {
+				t.Error("Expected no audit entry for a refused change")
+			}
+		})
+	}
+}
+
+func TestExpenseResubmitAfterRejection(t *testing.T) {
+	f := newExpenseFixture(t)
+
+	rejected, err := f.service.RejectExpense(txContext(), f.manager.ID, f.expense.ID, &models.RejectExpenseRequest{Reason: " missing receipt "})
+	if err != nil {
+		t.Fatalf("RejectExpense failed: %v", err)
+	}
+	if rejected.Status != models.ExpenseStatusRejected || rejected.RejectionReason == nil || *rejected.RejectionReason != "missing receipt" {
+		t.Errorf("Expected a rejection with its reason, got %+v", rejected)
+	}
+	last := f.audit.logs[len(f.audit.logs)-1]
+	if last.NewValues["rejectionReason"] != "missing receipt" {
+		t.Errorf("Expected the rejection reason in the audit entry, got %v", last.NewValues)
+	}
+
+	// Only a pending expense can be reviewed
+	if _, err := f.service.ApproveExpense(txContext(), f.manager.ID, f.expense.ID); !errors.Is(err, ErrExpenseNotPending) {
+		t.Errorf("Expected ErrExpenseNotPending approving a rejected expense, got %v", err)
+	}
+
+	amount := 40.00
+	resubmitted, err := f.service.UpdateExpense(txContext(), f.cashier.ID, f.expense.ID, &models.UpdateExpenseRequest{Amount: &amount})
+	if err != nil {
+		t.Fatalf("UpdateExpense failed: %v", err)
+	}
+	if resubmitted.Status != models.ExpenseStatusPending {
+		t.Errorf("Expected an edited rejection to be pending again, got %s", resubmitted.Status)
+	}
+	if resubmitted.RejectedBy != nil || resubmitted.RejectedAt != nil || resubmitted.RejectionReason != nil {
+		t.Errorf("Expected the rejection to be cleared, got %+v", resubmitted)
+	}
+	if resubmitted.Amount != 40.00 {
+		t.Errorf("Expected the new amount of 40.00, got %.2f", resubmitted.Amount)
+	}
+
+	approved, err := f.service.ApproveExpense(txContext(), f.manager.ID, f.expense.ID)
+	if err != nil {
+		t.Fatalf("ApproveExpense after resubmission failed: %v", err)
+	}
+	if approved.Status != models.ExpenseStatusApproved || approved.ApprovedBy == nil || *approved.ApprovedBy != f.manager.ID {
+		t.Errorf("Expected approval by the manager, got %+v", approved)
+	}
+}
+
+func TestExpenseReviewAccess(t *testing.T) {
+	f := newExpenseFixture(t)
+	other := &models.User{ID: uuid.New(), Name: "Riley", Role: models.RoleCashier, IsActive: true}
+	f.service.userRepo = newFakeUserRepo(f.cashier, f.manager, other)
+
+	if _, err := f.service.ApproveExpense(txContext(), f.cashier.ID, f.expense.ID); !errors.Is(err, ErrInsufficientRole) {
+		t.Errorf("Expected ErrInsufficientRole for a cashier approving, got %v", err)
+	}
+	title := "Mine now"
+	if _, err := f.service.UpdateExpense(txContext(), other.ID, f.expense.ID, &models.UpdateExpenseRequest{Title: &title}); !errors.Is(err, ErrExpenseNotFound) {
+		t.Errorf("Expected ErrExpenseNotFound for another cashier's edit, got %v", err)
+	}
+	if stored := f.expenses.expenses[f.expense.ID]; stored.Status != models.ExpenseStatusPending || stored.Title != "Till rolls" {
+		t.Errorf("Expected the expense to be unchanged, got %+v", stored)
+	}
+}
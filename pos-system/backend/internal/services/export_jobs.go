@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+// exportJobTimeout bounds how long a single export may run
+const exportJobTimeout = 30 * time.Minute
+
+// start records job and runs generate for it in the background once a
+// worker slot is free. It returns a snapshot of the pending job.
+func (s *ExportService) start(ctx context.Context, job *models.ExportJob, generate func(ctx context.Context) (*models.ExportResponse, error)) *models.ExportJob {
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	// The export outlives the request that asked for it
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+
+		s.update(job.ID, func(job *models.ExportJob) {
+			job.Status = models.ExportStatusRunning
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, exportJobTimeout)
+		defer cancel()
+		result, err := generate(ctx)
+
+		s.update(job.ID, func(job *models.ExportJob) {
+			now := time.Now()
+			job.CompletedAt = &now
+			if err != nil {
+				log.Printf("export %s of %s failed: %v", job.ID, job.Resource, err)
+				job.Status = models.ExportStatusFailed
+				job.Error = "export failed, please try again"
+				return
+			}
+			job.Status = models.ExportStatusCompleted
+			job.Result = result
+		})
+	}()
+
+	return &snapshot
+}
+
+// GetExportJob returns the state of an export, with its download link once
+// it has completed. Users only see the exports they asked for.
+func (s *ExportService) GetExportJob(ctx context.Context, userID, id uuid.UUID) (*models.ExportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.RequestedBy != userID {
+		return nil, ErrExportNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+func (s *ExportService) update(id uuid.UUID, fn func(job *models.ExportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job, ok := s.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// forgetFinishedBefore drops jobs that finished before cutoff, by which time
+// their download links have expired
+func (s *ExportService) forgetFinishedBefore(cutoff time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		if job.CompletedAt != nil && job.CompletedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/export"
+	"github.com/pos-system/backend/pkg/storage"
+)
+
+// exportDownloadPath is where the download route is mounted
+const exportDownloadPath = "/api/exports/download/"
+
+var (
+	ErrUnknownExportResource = errors.New("unknown export resource")
+	ErrInvalidExportFormat   = errors.New("format must be csv, xlsx or json")
+	ErrInvalidExportField    = errors.New("unknown export field")
+	ErrInvalidExportFilter   = errors.New("export filter values must be strings, numbers or booleans")
+	ErrExportNotFound        = errors.New("export not found")
+	ErrExportLinkInvalid     = errors.New("invalid download link")
+	ErrExportLinkExpired     = errors.New("download link has expired")
+)
+
+// ExportFieldError reports a requested field the resource does not have
+type ExportFieldError struct {
+	Field     string
+	Available []string
+}
+
+func (e *ExportFieldError) Error() string {
+	return fmt.Sprintf("unknown export field %q", e.Field)
+}
+
+func (e *ExportFieldError) Unwrap() error {
+	return ErrInvalidExportField
+}
+
+// Details returns the fields reported to API clients
+func (e *ExportFieldError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"field":     e.Field,
+		"available": e.Available,
+	}
+}
+
+// exportRecord holds one exported record's values by column name
+type exportRecord map[string]interface{}
+
+// exportDataset describes an exportable resource: its columns in default
+// order and how to stream its records
+type exportDataset struct {
+	columns   []string
+	adminOnly bool
+	each      func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error
+}
+
+// Column names follow each model's JSON field names
+var exportDatasets = map[string]exportDataset{
+	"transactions": {
+		columns: []string{"id", "receiptId", "status", "cashierName", "customerName", "customerEmail", "customerPhone",
+			"itemCount", "subtotal", "discountAmount", "taxAmount", "total", "amountPaid", "change",
+			"paymentMethod", "refundedAt", "refundReason", "createdAt"},
+		each: func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error {
+			return repo.EachTransaction(ctx, filters, func(batch []models.Transaction) error {
+				for i := range batch {
+					t := &batch[i]
+					err := emit(exportRecord{
+						"id": t.ID, "receiptId": t.ReceiptID, "status": t.Status, "cashierName": t.Cashier.Name,
+						"customerName": optional(t.CustomerName), "customerEmail": optional(t.CustomerEmail),
+						"customerPhone": optional(t.CustomerPhone), "itemCount": t.GetItemCount(),
+						"subtotal": t.Subtotal, "discountAmount": t.DiscountAmount, "taxAmount": t.TaxAmount,
+						"total": t.Total, "amountPaid": t.AmountPaid, "change": t.Change,
+						"paymentMethod": t.PaymentMethod, "refundedAt": optionalTime(t.RefundedAt),
+						"refundReason": optional(t.RefundReason), "createdAt": t.CreatedAt,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+	"products": {
+		columns: []string{"id", "sku", "barcode", "name", "description", "category", "price", "cost", "stock",
+			"min_stock", "max_stock", "status", "is_active", "supplier", "weight", "dimensions", "created_at", "updated_at"},
+		each: func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error {
+			return repo.EachProduct(ctx, filters, func(batch []models.Product) error {
+				for i := range batch {
+					p := &batch[i]
+					err := emit(exportRecord{
+						"id": p.ID, "sku": p.SKU, "barcode": optional(p.Barcode), "name": p.Name,
+						"description": p.Description, "category": p.Category.Name, "price": p.Price, "cost": p.Cost,
+						"stock": p.Stock, "min_stock": p.MinStock, "max_stock": p.MaxStock, "status": p.Status,
+						"is_active": p.IsActive, "supplier": p.Supplier, "weight": p.Weight,
+						"dimensions": p.Dimensions, "created_at": p.CreatedAt, "updated_at": p.UpdatedAt,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+	"stock-movements": {
+		columns: []string{"id", "product_id", "product_sku", "product_name", "type", "quantity", "reason",
+			"reference", "notes", "performed_by", "created_at"},
+		each: func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error {
+			return repo.EachStockMovement(ctx, filters, func(batch []models.StockMovement) error {
+				for i := range batch {
+					m := &batch[i]
+					err := emit(exportRecord{
+						"id": m.ID, "product_id": m.ProductID, "product_sku": m.Product.SKU,
+						"product_name": m.Product.Name, "type": m.Type, "quantity": m.Quantity, "reason": m.Reason,
+						"reference": m.Reference, "notes": m.Notes, "performed_by": m.PerformedBy,
+						"created_at": m.CreatedAt,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+	"expenses": {
+		columns: []string{"id", "title", "description", "amount", "category", "date", "status", "createdBy",
+			"createdByName", "approvedAt", "rejectedAt", "rejectionReason", "createdAt"},
+		each: func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error {
+			return repo.EachExpense(ctx, filters, func(batch []models.Expense) error {
+				for i := range batch {
+					e := &batch[i]
+					err := emit(exportRecord{
+						"id": e.ID, "title": e.Title, "description": optional(e.Description), "amount": e.Amount,
+						"category": e.Category, "date": e.Date, "status": e.Status, "createdBy": e.CreatedBy,
+						"createdByName": e.CreatedByUser.Name, "approvedAt": optionalTime(e.ApprovedAt),
+						"rejectedAt": optionalTime(e.RejectedAt), "rejectionReason": optional(e.RejectionReason),
+						"createdAt": e.CreatedAt,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+	"audit-logs": {
+		columns:   []string{"id", "timestamp", "userId", "userName", "userRole", "action", "resource", "resourceId", "ipAddress", "userAgent"},
+		adminOnly: true,
+		each: func(ctx context.Context, repo repository.ExportRepository, filters map[string]interface{}, emit func(exportRecord) error) error {
+			return repo.EachAuditLog(ctx, filters, func(batch []models.AuditLog) error {
+				for i := range batch {
+					l := &batch[i]
+					err := emit(exportRecord{
+						"id": l.ID, "timestamp": l.Timestamp, "userId": l.UserID, "userName": l.UserName,
+						"userRole": l.UserRole, "action": l.Action, "resource": l.Resource,
+						"resourceId": optional(l.ResourceID), "ipAddress": l.IPAddress, "userAgent": l.UserAgent,
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+}
+
+// ExportService writes records to downloadable CSV, XLSX and JSON files in
+// the background. Files are kept in their own store until their download
+// link expires.
+type ExportService struct {
+	exportRepo repository.ExportRepository
+	userRepo   repository.UserRepository
+	store      *storage.LocalStore
+	signingKey []byte
+	linkTTL    time.Duration
+
+	// Jobs are tracked in memory alongside the files they write, which are
+	// on this instance's disk too
+	mu    sync.Mutex
+	jobs  map[uuid.UUID]*models.ExportJob
+	slots chan struct{} // one per export allowed to run at once
+}
+
+// NewExportService creates a new export service. Download links are signed
+// with signingKey and stay valid for linkTTL. At most workers exports are
+// generated at once; the rest wait their turn.
+func NewExportService(
+	exportRepo repository.ExportRepository,
+	userRepo repository.UserRepository,
+	store *storage.LocalStore,
+	signingKey string,
+	linkTTL time.Duration,
+	workers int,
+) *ExportService {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &ExportService{
+		exportRepo: exportRepo,
+		userRepo:   userRepo,
+		store:      store,
+		signingKey: []byte(signingKey),
+		linkTTL:    linkTTL,
+		jobs:       make(map[uuid.UUID]*models.ExportJob),
+		slots:      make(chan struct{}, workers),
+	}
+}
+
+// CreateExport checks the request and starts streaming the resource's
+// records matching it into a file in the background. The returned job is
+// polled with GetExportJob until it has a download link. Audit logs can only
+// be exported by admins.
+func (s *ExportService) CreateExport(ctx context.Context, userID uuid.UUID, resource string, req *models.ExportRequest) (*models.ExportJob, error) {
+	dataset, ok := exportDatasets[resource]
+	if !ok {
+		return nil, ErrUnknownExportResource
+	}
+	format := export.Format(req.Format)
+	if !format.IsValid() {
+		return nil, ErrInvalidExportFormat
+	}
+
+	if dataset.adminOnly {
+		user, err := s.getUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsAdmin() {
+			return nil, ErrInsufficientRole
+		}
+	}
+
+	columns, err := exportColumns(dataset.columns, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := exportFilters(req)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ExportJob{
+		ID:          uuid.New(),
+		Resource:    resource,
+		Format:      string(format),
+		Status:      models.ExportStatusPending,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	return s.start(ctx, job, func(ctx context.Context) (*models.ExportResponse, error) {
+		return s.generate(ctx, resource, dataset, format, columns, filters)
+	}), nil
+}
+
+// generate writes the export file and returns its signed download link
+func (s *ExportService) generate(ctx context.Context, resource string, dataset exportDataset, format export.Format, columns []string, filters map[string]interface{}) (*models.ExportResponse, error) {
+	now := time.Now()
+	fileName := fmt.Sprintf("%s-%s-%s.%s", resource, now.Format("20060102-150405"), uuid.New().String()[:8], format)
+
+	// The file is written through a pipe so records go to disk as they are
+	// read instead of being buffered
+	type result struct {
+		count int
+		err   error
+	}
+	done := make(chan result, 1)
+	reader, writer := io.Pipe()
+	go func() {
+		count, err := s.write(ctx, writer, dataset, format, columns, filters)
+		writer.CloseWithError(err)
+		done <- result{count, err}
+	}()
+
+	_, saveErr := s.store.Save(fileName, reader)
+	reader.CloseWithError(saveErr) // unblocks the writer if saving failed
+	written := <-done
+	if written.err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", resource, written.err)
+	}
+	if saveErr != nil {
+		return nil, fmt.Errorf("failed to save export: %w", saveErr)
+	}
+
+	expiresAt := now.Add(s.linkTTL)
+	return &models.ExportResponse{
+		FileName:    fileName,
+		DownloadURL: s.downloadURL(fileName, expiresAt),
+		Format:      string(format),
+		RecordCount: written.count,
+		GeneratedAt: now,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (s *ExportService) write(ctx context.Context, w io.Writer, dataset exportDataset, format export.Format, columns []string, filters map[string]interface{}) (int, error) {
+	writer, err := export.NewWriter(format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = dataset.each(ctx, s.exportRepo, filters, func(record exportRecord) error {
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = record[column]
+		}
+		count++
+		return writer.Write(values)
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+// OpenExport checks a download link and opens the exported file it points
+// to. The caller must close the file.
+func (s *ExportService) OpenExport(ctx context.Context, fileName, expires, signature string) (*os.File, export.Format, error) {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || strings.ContainsAny(fileName, `/\`) {
+		return nil, "", ErrExportLinkInvalid
+	}
+	provided, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(provided, s.sign(fileName, expiresUnix)) {
+		return nil, "", ErrExportLinkInvalid
+	}
+	if time.Now().Unix() > expiresUnix {
+		return nil, "", ErrExportLinkExpired
+	}
+
+	file, err := s.store.Open(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, storage.ErrInvalidKey) {
+			return nil, "", ErrExportNotFound
+		}
+		return nil, "", fmt.Errorf("failed to open export: %w", err)
+	}
+	return file, export.Format(strings.TrimPrefix(path.Ext(fileName), ".")), nil
+}
+
+// PurgeExpired deletes exported files whose download links have expired
+// and forgets the jobs that wrote them
+func (s *ExportService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.linkTTL)
+	s.forgetFinishedBefore(cutoff)
+
+	removed, err := s.store.RemoveOlderThan("", cutoff)
+	if err != nil {
+		return removed, fmt.Errorf("failed to purge exports: %w", err)
+	}
+	return removed, nil
+}
+
+func (s *ExportService) downloadURL(fileName string, expiresAt time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", hex.EncodeToString(s.sign(fileName, expiresAt.Unix())))
+	return exportDownloadPath + url.PathEscape(fileName) + "?" + query.Encode()
+}
+
+func (s *ExportService) sign(fileName string, expires int64) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%d", fileName, expires)
+	return mac.Sum(nil)
+}
+
+func (s *ExportService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// exportColumns returns the requested fields in request order, or every
+// column when none were requested
+func exportColumns(available, fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return available, nil
+	}
+
+	known := make(map[string]bool, len(available))
+	for _, column := range available {
+		known[column] = true
+	}
+	columns := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !known[field] {
+			return nil, &ExportFieldError{Field: field, Available: available}
+		}
+		if !seen[field] {
+			seen[field] = true
+			columns = append(columns, field)
+		}
+	}
+	return columns, nil
+}
+
+// exportFilters combines the request's filters and date range into
+// repository filters. Only scalar filter values are accepted.
+func exportFilters(req *models.ExportRequest) (map[string]interface{}, error) {
+	filters := make(map[string]interface{}, len(req.Filters)+2)
+	for key, value := range req.Filters {
+		switch value.(type) {
+		case string, float64, bool, nil:
+			filters[key] = value
+		default:
+			return nil, ErrInvalidExportFilter
+		}
+	}
+
+	if req.DateRange != nil {
+		if !req.DateRange.IsValid() {
+			return nil, ErrInvalidDateRange
+		}
+		if start := req.DateRange.GetStartOfDay(); start != nil {
+			filters["start_date"] = *start
+		}
+		if end := req.DateRange.GetEndOfDay(); end != nil {
+			filters["end_date"] = *end
+		}
+	}
+	return filters, nil
+}
+
+// optional returns the string or nil so missing values export as empty
+func optional(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/storage"
+)
+
+type fakeExportRepo struct {
+	products []models.Product
+	release  chan struct{} // when set, EachProduct waits for it to close
+	err      error
+}
+
+func (r *fakeExportRepo) EachProduct(ctx context.Context, filters map[string]interface{}, fn func([]models.Product) error) error {
+	if r.release != nil {
+		<-r.release
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return fn(r.products)
+}
+
+func (r *fakeExportRepo) EachTransaction(ctx context.Context, filters map[string]interface{}, fn func([]models.Transaction) error) error {
+	return nil
+}
+
+func (r *fakeExportRepo) EachStockMovement(ctx context.Context, filters map[string]interface{}, fn func([]models.StockMovement) error) error {
+	return nil
+}
+
+func (r *fakeExportRepo) EachExpense(ctx context.Context, filters map[string]interface{}, fn func([]models.Expense) error) error {
+	return nil
+}
+
+func (r *fakeExportRepo) EachAuditLog(ctx context.Context, filters map[string]interface{}, fn func([]models.AuditLog) error) error {
+	return nil
+}
+
+func newTestExportService(t *testing.T, repo *fakeExportRepo, users ...*models.User) (*ExportService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	return NewExportService(repo, newFakeUserRepo(users...), storage.NewLocalStore(dir), "test-key", time.Hour, 1), dir
+}
+
+// waitForExport polls a job until it has finished
+func waitForExport(t *testing.T, service *ExportService, userID, id uuid.UUID) *models.ExportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := service.GetExportJob(context.Background(), userID, id)
+		if err != nil {
+			t.Fatalf("GetExportJob failed: %v", err)
+		}
+		if job.Status == models.ExportStatusCompleted || job.Status == models.ExportStatusFailed {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Export did not finish")
+	return nil
+}
+
+func TestExportRunsInBackground(t *testing.T) {
+	manager := &models.User{ID: uuid.New(), Role: models.RoleManager, IsActive: true}
+	repo := &fakeExportRepo{
+		products: []models.Product{*newTestProduct("mug", 8, 3, 4), *newTestProduct("tea", 4, 1, 9)},
+		release:  make(chan struct{}),
+	}
+	service, dir := newTestExportService(t, repo, manager)
+
+	// The request returns before any records are read
+	job, err := service.CreateExport(context.Background(), manager.ID, "products", &models.ExportRequest{
+		Format: "csv",
+		Fields: []string{"sku", "name"},
+	})
+	if err != nil {
+		t.Fatalf("CreateExport failed: %v", err)
+	}
+	if job.Status != models.ExportStatusPending || job.Result != nil {
+		t.Errorf("Expected a pending job without a link, got %+v", job)
+	}
+	if _, err := service.GetExportJob(context.Background(), uuid.New(), job.ID); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Expected ErrExportNotFound for another user's export, got %v", err)
+	}
+
+	close(repo.release)
+	done := waitForExport(t, service, manager.ID, job.ID)
+	if done.Status != models.ExportStatusCompleted || done.Result == nil || done.CompletedAt == nil {
+		t.Fatalf("Expected a completed job with a link, got %+v", done)
+	}
+	if done.Result.RecordCount != 2 {
+		t.Errorf("Expected 2 records, got %d", done.Result.RecordCount)
+	}
+
+	// The file is written to the export store's own directory
+	if _, err := os.Stat(filepath.Join(dir, done.Result.FileName)); err != nil {
+		t.Errorf("Expected the file in the export directory, got %v", err)
+	}
+
+	link, err := url.Parse(done.Result.DownloadURL)
+	if err != nil {
+		t.Fatalf("Invalid download URL %q: %v", done.Result.DownloadURL, err)
+	}
+	fileName, _ := url.PathUnescape(path.Base(link.Path))
+	file, format, err := service.OpenExport(context.Background(), fileName, link.Query().Get("expires"), link.Query().Get("signature"))
+	if err != nil {
+		t.Fatalf("OpenExport failed: %v", err)
+	}
+	defer file.Close()
+	content, _ := io.ReadAll(file)
+	if format != "csv" || string(content) != "sku,name\nSKU-mug,mug\nSKU-tea,tea\n" {
+		t.Errorf("Unexpected %s export:\n%s", format, content)
+	}
+}
+
+func TestExportFailure(t *testing.T) {
+	manager := &models.User{ID: uuid.New(), Role: models.RoleManager, IsActive: true}
+	service, dir := newTestExportService(t, &fakeExportRepo{err: errFakeFailure}, manager)
+
+	job, err := service.CreateExport(context.Background(), manager.ID, "products", &models.ExportRequest{Format: "json"})
+	if err != nil {
+		t.Fatalf("CreateExport failed: %v", err)
+	}
+
+	done := waitForExport(t, service, manager.ID, job.ID)
+	if done.Status != models.ExportStatusFailed || done.Result != nil || done.Error == "" {
+		t.Errorf("Expected a failed job with an error, got %+v", done)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no file left behind, got %d", len(entries))
+	}
+}
+
+func TestExportRejectedUpFront(t *testing.T) {
+	cashier := &models.User{ID: uuid.New(), Role: models.RoleCashier, IsActive: true}
+
+	tests := []struct {
+		name     string
+		resource string
+		req      models.ExportRequest
+		wantErr  error
+	}{
+		{name: "unknown resource", resource: "customers", req: models.ExportRequest{Format: "csv"}, wantErr: ErrUnknownExportResource},
+		{name: "unknown format", resource: "products", req: models.ExportRequest{Format: "pdf"}, wantErr: ErrInvalidExportFormat},
+		{name: "unknown field", resource: "products", req: models.ExportRequest{Format: "csv", Fields: []string{"secret"}}, wantErr: ErrInvalidExportField},
+		{
+			name:     "nested filter",
+			resource: "products",
+			req:      models.ExportRequest{Format: "csv", Filters: map[string]interface{}{"sku": []interface{}{"a"}}},
+			wantErr:  ErrInvalidExportFilter,
+		},
+		{name: "audit logs need an admin", resource: "audit-logs", req: models.ExportRequest{Format: "csv"}, wantErr: ErrInsufficientRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestExportService(t, &fakeExportRepo{}, cashier)
+			if _, err := service.CreateExport(context.Background(), cashier.ID, tt.resource, &tt.req); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if len(service.jobs) != 0 {
+				t.Error("Expected no job to be started")
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredExports(t *testing.T) {
+	manager := &models.User{ID: uuid.New(), Role: models.RoleManager, IsActive: true}
+	service, dir := newTestExportService(t, &fakeExportRepo{products: []models.Product{*newTestProduct("mug", 8, 3, 4)}}, manager)
+
+	var jobs []*models.ExportJob
+	for i := 0; i < 2; i++ {
+		job, err := service.CreateExport(context.Background(), manager.ID, "products", &models.ExportRequest{Format: "csv"})
+		if err != nil {
+			t.Fatalf("CreateExport failed: %v", err)
+		}
+		jobs = append(jobs, waitForExport(t, service, manager.ID, job.ID))
+	}
+
+	// Age the first export past its link's lifetime
+	old := time.Now().Add(-2 * time.Hour)
+	service.update(jobs[0].ID, func(job *models.ExportJob) { job.CompletedAt = &old })
+	if err := os.Chtimes(filepath.Join(dir, jobs[0].Result.FileName), old, old); err != nil {
+		t.Fatalf("Failed to age export: %v", err)
+	}
+
+	removed, err := service.PurgeExpired(context.Background())
+	if err != nil || removed != 1 {
+		t.Fatalf("Expected 1 file purged, got %d, %v", removed, err)
+	}
+	if _, err := service.GetExportJob(context.Background(), manager.ID, jobs[0].ID); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Expected the expired job to be forgotten, got %v", err)
+	}
+	if _, err := service.GetExportJob(context.Background(), manager.ID, jobs[1].ID); err != nil {
+		t.Errorf("Expected the current job to be kept, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, jobs[1].Result.FileName)); err != nil {
+		t.Errorf("Expected the current file to be kept, got %v", err)
+	}
+}
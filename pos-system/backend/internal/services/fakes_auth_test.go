@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	revoked []uuid.UUID // users whose sessions were all revoked
+	err     error       // returned by RevokeAllUserSessions when set
+}
+
+func (r *fakeSessionRepo) RevokeAllUserSessions(ctx context.Context, userID uuid.UUID) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+type fakeRevokedTokenRepo struct {
+	repository.RevokedTokenRepository
+	revoked []models.RevokedToken
+	err     error // returned by Create when set
+}
+
+func (r *fakeRevokedTokenRepo) Create(ctx context.Context, revoked *models.RevokedToken) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.revoked = append(r.revoked, *revoked)
+	return nil
+}
+
+type fakeTwoFactorRepo struct {
+	repository.TwoFactorRepository
+	factors    map[uuid.UUID]*models.TwoFactor
+	recovery   map[uuid.UUID]map[string]bool // unused recovery code hashes
+	challenges map[string]*models.TwoFactorChallenge
+	policies   map[models.Role]*models.TwoFactorPolicy
+}
+
+func newFakeTwoFactorRepo() *fakeTwoFactorRepo {
+	return &fakeTwoFactorRepo{
+		factors:    make(map[uuid.UUID]*models.TwoFactor),
+		recovery:   make(map[uuid.UUID]map[string]bool),
+		challenges: make(map[string]*models.TwoFactorChallenge),
+		policies:   make(map[models.Role]*models.TwoFactorPolicy),
+	}
+}
+
+func (r *fakeTwoFactorRepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	twoFactor, ok := r.factors[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	stored := *twoFactor
+	return &stored, nil
+}
+
+func (r *fakeTwoFactorRepo) Save(ctx context.Context, twoFactor *models.TwoFactor) error {
+	stored := *twoFactor
+	r.factors[twoFactor.UserID] = &stored
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	delete(r.factors, userID)
+	delete(r.recovery, userID)
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	r.recovery[userID] = make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		r.recovery[userID][hash] = true
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	if !r.recovery[userID][hash] {
+		return false, nil
+	}
+	delete(r.recovery[userID], hash)
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepo) CreateChallenge(ctx context.Context, challenge *models.TwoFactorChallenge) error {
+	r.challenges[challenge.TokenHash] = challenge
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) GetChallenge(ctx context.Context, tokenHash string) (*models.TwoFactorChallenge, error) {
+	challenge, ok := r.challenges[tokenHash]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return challenge, nil
+}
+
+func (r *fakeTwoFactorRepo) DeleteChallenge(ctx context.Context, id uuid.UUID) error {
+	for hash, challenge := range r.challenges {
+		if challenge.ID == id {
+			delete(r.challenges, hash)
+		}
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepo) GetPolicy(ctx context.Context, role models.Role) (*models.TwoFactorPolicy, error) {
+	policy, ok := r.policies[role]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return policy, nil
+}
+
+func (r *fakeTwoFactorRepo) SavePolicy(ctx context.Context, policy *models.TwoFactorPolicy) error {
+	r.policies[policy.Role] = policy
+	return nil
+}
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+// In-memory repositories for service tests. Each embeds its interface so it
+// only has to implement the methods the code under test calls; anything
+// else panics, which points straight at a missing fake.
+
+var errFakeFailure = errors.New("fake failure")
+
+// txContext returns a context that already carries a transaction, so
+// repository.RunInTx runs its function directly without a database
+func txContext() context.Context {
+	return repository.ContextWithTx(context.Background(), &gorm.DB{})
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	repo := &fakeUserRepo{users: make(map[uuid.UUID]*models.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return repo
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) SetActiveStatus(ctx context.Context, id uuid.UUID, isActive bool) error {
+	user, ok := r.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.IsActive = isActive
+	return nil
+}
+
+type fakeProductRepo struct {
+	repository.ProductRepository
+	products map[uuid.UUID]*models.Product
+	locked   []uuid.UUID // product IDs in the order they were locked
+}
+
+func newFakeProductRepo(products ...*models.Product) *fakeProductRepo {
+	repo := &fakeProductRepo{products: make(map[uuid.UUID]*models.Product)}
+	for _, product := range products {
+		repo.products[product.ID] = product
+	}
+	return repo
+}
+
+func (r *fakeProductRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *product
+	return &copied, nil
+}
+
+func (r *fakeProductRepo) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	r.locked = append(r.locked, id)
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeProductRepo) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	for _, product := range r.products {
+		if product.SKU == sku {
+			return r.GetByID(ctx, product.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeProductRepo) GetByBarcode(ctx context.Context, barcode string) (*models.Product, error) {
+	for _, product := range r.products {
+		if product.Barcode != nil && *product.Barcode == barcode {
+			return r.GetByID(ctx, product.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeProductRepo) Create(ctx context.Context, product *models.Product) error {
+	return r.Update(ctx, product)
+}
+
+func (r *fakeProductRepo) Update(ctx context.Context, product *models.Product) error {
+	copied := *product
+	r.products[product.ID] = &copied
+	return nil
+}
+
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+	categories map[uuid.UUID]*models.Category
+}
+
+func newFakeCategoryRepo(categories ...*models.Category) *fakeCategoryRepo {
+	repo := &fakeCategoryRepo{categories: make(map[uuid.UUID]*models.Category)}
+	for _, category := range categories {
+		repo.categories[category.ID] = category
+	}
+	return repo
+}
+
+func (r *fakeCategoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return category, nil
+}
+
+// GetByName matches names without regard to case, as the database does
+func (r *fakeCategoryRepo) GetByName(ctx context.Context, name string) (*models.Category, error) {
+	for _, category := range r.categories {
+		if strings.EqualFold(category.Name, name) {
+			return category, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeCategoryRepo) Create(ctx context.Context, category *models.Category) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+type fakeTransactionRepo struct {
+	repository.TransactionRepository
+	transactions map[uuid.UUID]*models.Transaction
+}
+
+func newFakeTransactionRepo(transactions ...*models.Transaction) *fakeTransactionRepo {
+	repo := &fakeTransactionRepo{transactions: make(map[uuid.UUID]*models.Transaction)}
+	for _, transaction := range transactions {
+		repo.transactions[transaction.ID] = transaction
+	}
+	return repo
+}
+
+func (r *fakeTransactionRepo) Create(ctx context.Context, transaction *models.Transaction) error {
+	for i := range transaction.Items {
+		if transaction.Items[i].ID == uuid.Nil {
+			transaction.Items[i].ID = uuid.New()
+		}
+	}
+	r.transactions[transaction.ID] = transaction
+	return nil
+}
+
+func (r *fakeTransactionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return transaction, nil
+}
+
+func (r *fakeTransactionRepo) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeTransactionRepo) GetByReceiptID(ctx context.Context, receiptID string) (*models.Transaction, error) {
+	for _, transaction := range r.transactions {
+		if transaction.ReceiptID == receiptID {
+			return transaction, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTransactionRepo) Update(ctx context.Context, transaction *models.Transaction) error {
+	r.transactions[transaction.ID] = transaction
+	return nil
+}
+
+func (r *fakeTransactionRepo) UpdateItem(ctx context.Context, item *models.TransactionItem) error {
+	return nil
+}
+
+func (r *fakeTransactionRepo) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	transaction, ok := r.transactions[payment.TransactionID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	transaction.Payments = append(transaction.Payments, *payment)
+	return nil
+}
+
+type fakeStockMovementRepo struct {
+	repository.StockMovementRepository
+	movements []models.StockMovement
+}
+
+func (r *fakeStockMovementRepo) Create(ctx context.Context, movement *models.StockMovement) error {
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+type fakeSystemConfigRepo struct {
+	repository.SystemConfigRepository
+	config *models.SystemConfig
+}
+
+func (r *fakeSystemConfigRepo) Get(ctx context.Context) (*models.SystemConfig, error) {
+	if r.config == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.config, nil
+}
+
+type fakeAuditRepo struct {
+	repository.AuditLogRepository
+	logs []models.AuditLog
+	err  error // returned by Create when set
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, log *models.AuditLog) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+type fakeEmailRepo struct {
+	repository.EmailDeliveryRepository
+	deliveries []models.EmailDelivery
+}
+
+func (r *fakeEmailRepo) Create(ctx context.Context, delivery *models.EmailDelivery) error {
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+type fakeCartRepo struct {
+	repository.CartRepository
+	carts map[uuid.UUID]*models.Cart
+}
+
+func newFakeCartRepo(carts ...*models.Cart) *fakeCartRepo {
+	repo := &fakeCartRepo{carts: make(map[uuid.UUID]*models.Cart)}
+	for _, cart := range carts {
+		repo.carts[cart.ID] = cart
+	}
+	return repo
+}
+
+func (r *fakeCartRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Cart, error) {
+	cart, ok := r.carts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *cart
+	copied.Items = append([]models.CartItem(nil), cart.Items...)
+	return &copied, nil
+}
+
+func (r *fakeCartRepo) ListByCashierID(ctx context.Context, cashierID uuid.UUID, status *models.CartStatus) ([]models.Cart, error) {
+	var carts []models.Cart
+	for _, cart := range r.carts {
+		if cart.CashierID == cashierID && (status == nil || cart.Status == *status) {
+			carts = append(carts, *cart)
+		}
+	}
+	return carts, nil
+}
+
+func (r *fakeCartRepo) Update(ctx context.Context, cart *models.Cart) error {
+	stored, ok := r.carts[cart.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	items := stored.Items
+	*stored = *cart
+	stored.Items = items
+	return nil
+}
+
+func (r *fakeCartRepo) UpdateItem(ctx context.Context, cartID uuid.UUID, item *models.CartItem) error {
+	cart, ok := r.carts[cartID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == item.ProductID {
+			cart.Items[i].Quantity = item.Quantity
+			cart.Items[i].Discount = item.Discount
+			cart.Items[i].UnitPrice = item.UnitPrice
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeCartRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.carts, id)
+	return nil
+}
+
+type fakeStockAdjustmentRepo struct {
+	repository.StockAdjustmentRepository
+	adjustments map[uuid.UUID]*models.StockAdjustment
+}
+
+func newFakeStockAdjustmentRepo() *fakeStockAdjustmentRepo {
+	return &fakeStockAdjustmentRepo{adjustments: make(map[uuid.UUID]*models.StockAdjustment)}
+}
+
+func (r *fakeStockAdjustmentRepo) Create(ctx context.Context, adjustment *models.StockAdjustment) error {
+	r.adjustments[adjustment.ID] = adjustment
+	return nil
+}
+
+func (r *fakeStockAdjustmentRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	adjustment, ok := r.adjustments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return adjustment, nil
+}
+
+type fakeExpenseRepo struct {
+	repository.ExpenseRepository
+	expenses map[uuid.UUID]*models.Expense
+}
+
+func newFakeExpenseRepo() *fakeExpenseRepo {
+	return &fakeExpenseRepo{expenses: make(map[uuid.UUID]*models.Expense)}
+}
+
+func (r *fakeExpenseRepo) Create(ctx context.Context, expense *models.Expense) error {
+	copied := *expense
+	r.expenses[expense.ID] = &copied
+	return nil
+}
+
+func (r *fakeExpenseRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	expense, ok := r.expenses[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *expense
+	return &copied, nil
+}
+
+func (r *fakeExpenseRepo) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *fakeExpenseRepo) Update(ctx context.Context, expense *models.Expense) error {
+	return r.Create(ctx, expense)
+}
+
+func (r *fakeExpenseRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, ok := r.expenses[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.expenses, id)
+	return nil
+}
+
+func (r *fakeExpenseRepo) Approve(ctx context.Context, id uuid.UUID, approvedBy uuid.UUID) error {
+	expense, ok := r.expenses[id]
+	if !ok || expense.Status != models.ExpenseStatusPending {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	expense.Status = models.ExpenseStatusApproved
+	expense.ApprovedBy = &approvedBy
+	expense.ApprovedAt = &now
+	return nil
+}
+
+func (r *fakeExpenseRepo) Reject(ctx context.Context, id uuid.UUID, rejectedBy uuid.UUID, reason string) error {
+	expense, ok := r.expenses[id]
+	if !ok || expense.Status != models.ExpenseStatusPending {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	expense.Status = models.ExpenseStatusRejected
+	expense.RejectedBy = &rejectedBy
+	expense.RejectedAt = &now
+	expense.RejectionReason = &reason
+	return nil
+}
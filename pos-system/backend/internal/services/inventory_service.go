@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrAdjustmentNotFound   = errors.New("stock adjustment not found")
+	ErrAdjustmentNotPending = errors.New("stock adjustment has already been reviewed")
+	ErrInvalidReasonCode    = errors.New("invalid adjustment reason code")
+)
+
+// InventoryService handles manual stock adjustments and their approval
+type InventoryService struct {
+	productRepo         repository.ProductRepository
+	stockAdjustmentRepo repository.StockAdjustmentRepository
+	stockMovementRepo   repository.StockMovementRepository
+	userRepo            repository.UserRepository
+	auditRepo           repository.AuditLogRepository
+	db                  *gorm.DB
+	approvalQuantity    int
+	approvalValue       float64
+}
+
+// NewInventoryService creates a new inventory service. Adjustments by
+// non-managers that move more than approvalQuantity units or more than
+// approvalValue at cost wait for manager approval; a zero limit disables
+// that check.
+func NewInventoryService(
+	productRepo repository.ProductRepository,
+	stockAdjustmentRepo repository.StockAdjustmentRepository,
+	stockMovementRepo repository.StockMovementRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	approvalQuantity int,
+	approvalValue float64,
+) *InventoryService {
+	return &InventoryService{
+		productRepo:         productRepo,
+		stockAdjustmentRepo: stockAdjustmentRepo,
+		stockMovementRepo:   stockMovementRepo,
+		userRepo:            userRepo,
+		auditRepo:           auditRepo,
+		db:                  db,
+		approvalQuantity:    approvalQuantity,
+		approvalValue:       approvalValue,
+	}
+}
+
+// ListAdjustments returns a page of stock adjustments
+func (s *InventoryService) ListAdjustments(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockAdjustment, int64, error) {
+	adjustments, total, err := s.stockAdjustmentRepo.List(ctx, filters, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list stock adjustments: %w", err)
+	}
+	return adjustments, total, nil
+}
+
+// GetAdjustment retrieves a stock adjustment by ID
+func (s *InventoryService) GetAdjustment(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	adjustment, err := s.stockAdjustmentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdjustmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get stock adjustment: %w", err)
+	}
+	return adjustment, nil
+}
+
+// SubmitAdjustment records a stock adjustment. It is applied straight away
+// unless it crosses the approval threshold, in which case it stays pending
+// until a manager approves it. Managers' own adjustments never wait.
+func (s *InventoryService) SubmitAdjustment(ctx context.Context, userID uuid.UUID, req *models.StockAdjustmentRequest) (*models.StockAdjustment, error) {
+	if !req.ReasonCode.IsValid() {
+		return nil, ErrInvalidReasonCode
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustmentID uuid.UUID
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		product, err := s.lockProduct(ctx, req.ProductID)
+		if err != nil {
+			return err
+		}
+
+		adjustment := &models.StockAdjustment{
+			ID:          uuid.New(),
+			ProductID:   product.ID,
+			Quantity:    req.Quantity,
+			ReasonCode:  req.ReasonCode,
+			Reason:      strings.TrimSpace(req.Reason),
+			Reference:   req.Reference,
+			Notes:       req.Notes,
+			Value:       roundMoney(float64(abs(req.Quantity)) * product.Cost),
+			Status:      models.StockAdjustmentPending,
+			RequestedBy: userID,
+		}
+		adjustmentID = adjustment.ID
+
+		if !user.IsManager() && s.requiresApproval(adjustment) {
+			if err := s.stockAdjustmentRepo.Create(ctx, adjustment); err != nil {
+				return fmt.Errorf("failed to create stock adjustment: %w", err)
+			}
+			return nil
+		}
+
+		if err := s.apply(ctx, user, product, adjustment); err != nil {
+			return err
+		}
+		if err := s.stockAdjustmentRepo.Create(ctx, adjustment); err != nil {
+			return fmt.Errorf("failed to create stock adjustment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAdjustment(ctx, adjustmentID)
+}
+
+// ApproveAdjustment applies a pending adjustment on a manager's behalf
+func (s *InventoryService) ApproveAdjustment(ctx context.Context, managerID, id uuid.UUID, req *models.ReviewStockAdjustmentRequest) (*models.StockAdjustment, error) {
+	manager, err := s.getUser(ctx, managerID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager.IsManager() {
+		return nil, ErrInsufficientRole
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		adjustment, err := s.lockPending(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		product, err := s.lockProduct(ctx, adjustment.ProductID)
+		if err != nil {
+			return err
+		}
+
+		adjustment.ReviewNotes = req.Notes
+		if err := s.apply(ctx, manager, product, adjustment); err != nil {
+			return err
+		}
+
+		if err := s.stockAdjustmentRepo.Update(ctx, adjustment); err != nil {
+			return fmt.Errorf("failed to update stock adjustment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAdjustment(ctx, id)
+}
+
+// RejectAdjustment closes a pending adjustment without touching stock
+func (s *InventoryService) RejectAdjustment(ctx context.Context, managerID, id uuid.UUID, req *models.ReviewStockAdjustmentRequest) (*models.StockAdjustment, error) {
+	manager, err := s.getUser(ctx, managerID)
+	if err != nil {
+		return nil, err
+	}
+	if !manager.IsManager() {
+		return nil, ErrInsufficientRole
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		adjustment, err := s.lockPending(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		adjustment.Status = models.StockAdjustmentRejected
+		adjustment.ReviewedBy = &managerID
+		adjustment.ReviewedAt = &now
+		adjustment.ReviewNotes = req.Notes
+
+		if err := s.stockAdjustmentRepo.Update(ctx, adjustment); err != nil {
+			return fmt.Errorf("failed to update stock adjustment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAdjustment(ctx, id)
+}
+
+// requiresApproval reports whether adjustment crosses either threshold
+func (s *InventoryService) requiresApproval(adjustment *models.StockAdjustment) bool {
+	if s.approvalQuantity > 0 && abs(adjustment.Quantity) > s.approvalQuantity {
+		return true
+	}
+	return s.approvalValue > 0 && adjustment.Value > s.approvalValue
+}
+
+// apply changes the product's stock by the adjustment quantity, records the
+// stock movement and writes the UPDATE_STOCK audit entry. The product must
+// already be locked and reviewer is recorded as the approver.
+func (s *InventoryService) apply(ctx context.Context, reviewer *models.User, product *models.Product, adjustment *models.StockAdjustment) error {
+	oldStock := product.Stock
+	newStock := oldStock + adjustment.Quantity
+	if newStock < 0 {
+		return &StockError{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Available:   oldStock,
+			Requested:   -adjustment.Quantity,
+		}
+	}
+
+	product.Stock = newStock
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return fmt.Errorf("failed to update stock: %w", err)
+	}
+
+	movement := &models.StockMovement{
+		ProductID:   product.ID,
+		Type:        models.StockMovementAdjust,
+		Quantity:    adjustment.Quantity,
+		Reason:      string(adjustment.ReasonCode),
+		Reference:   adjustment.ID.String(),
+		Notes:       adjustment.Reason,
+		PerformedBy: adjustment.RequestedBy,
+	}
+	if err := s.stockMovementRepo.Create(ctx, movement); err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+
+	now := time.Now()
+	adjustment.Status = models.StockAdjustmentApplied
+	adjustment.ReviewedBy = &reviewer.ID
+	adjustment.ReviewedAt = &now
+	adjustment.StockBefore = &oldStock
+	adjustment.StockAfter = &newStock
+
+	auditLog := newAuditLog(ctx, reviewer, models.AuditActionUpdateStock, "product", product.ID.String(),
+		map[string]interface{}{
+			"stock": oldStock,
+		},
+		map[string]interface{}{
+			"stock":        newStock,
+			"quantity":     adjustment.Quantity,
+			"reasonCode":   adjustment.ReasonCode,
+			"reason":       adjustment.Reason,
+			"adjustmentId": adjustment.ID,
+			"requestedBy":  adjustment.RequestedBy,
+		},
+	)
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// lockPending locks an adjustment and checks it is still awaiting review
+func (s *InventoryService) lockPending(ctx context.Context, id uuid.UUID) (*models.StockAdjustment, error) {
+	adjustment, err := s.stockAdjustmentRepo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdjustmentNotFound
+		}
+		return nil, fmt.Errorf("failed to lock stock adjustment: %w", err)
+	}
+	if adjustment.Status != models.StockAdjustmentPending {
+		return nil, ErrAdjustmentNotPending
+	}
+	return adjustment, nil
+}
+
+// lockProduct locks a product row for a stock change
+func (s *InventoryService) lockProduct(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	product, err := s.productRepo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to lock product: %w", err)
+	}
+	return product, nil
+}
+
+func (s *InventoryService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+func TestRequiresApproval(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		value    float64
+		maxQty   int
+		maxValue float64
+		want     bool
+	}{
+		{name: "at the quantity limit", quantity: 10, value: 1, maxQty: 10, maxValue: 100, want: false},
+		{name: "one over the quantity limit", quantity: 11, value: 1, maxQty: 10, maxValue: 100, want: true},
+		{name: "removal at the quantity limit", quantity: -10, value: 1, maxQty: 10, maxValue: 100, want: false},
+		{name: "removal over the quantity limit", quantity: -11, value: 1, maxQty: 10, maxValue: 100, want: true},
+		{name: "at the value limit", quantity: 1, value: 100, maxQty: 10, maxValue: 100, want: false},
+		{name: "a cent over the value limit", quantity: 1, value: 100.01, maxQty: 10, maxValue: 100, want: true},
+		{name: "zero quantity limit is disabled", quantity: 1000, value: 1, maxValue: 100, want: false},
+		{name: "zero value limit is disabled", quantity: 1, value: 1e6, maxQty: 10, want: false},
+		{name: "both limits disabled", quantity: 1000, value: 1e6, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewInventoryService(nil, nil, nil, nil, nil, nil, tt.maxQty, tt.maxValue)
+			adjustment := &models.StockAdjustment{Quantity: tt.quantity, Value: tt.value}
+			if got := service.requiresApproval(adjustment); got != tt.want {
+				t.Errorf("Expected requiresApproval %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSubmitAdjustment(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        models.Role
+		quantity    int
+		wantStatus  models.StockAdjustmentStatus
+		wantStock   int
+		wantApplied bool
+	}{
+		{name: "cashier under the limit", role: models.RoleCashier, quantity: -2, wantStatus: models.StockAdjustmentApplied, wantStock: 8, wantApplied: true},
+		{name: "cashier over the limit waits", role: models.RoleCashier, quantity: -6, wantStatus: models.StockAdjustmentPending, wantStock: 10},
+		{name: "manager over the limit applies", role: models.RoleManager, quantity: -6, wantStatus: models.StockAdjustmentApplied, wantStock: 4, wantApplied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{ID: uuid.New(), Name: "Sam", Role: tt.role, IsActive: true}
+			product := newTestProduct("mug", 8.00, 3.00, 10)
+			products := newFakeProductRepo(product)
+			adjustments := newFakeStockAdjustmentRepo()
+			movements := &fakeStockMovementRepo{}
+			audit := &fakeAuditRepo{}
+			service := NewInventoryService(products, adjustments, movements, newFakeUserRepo(user), audit, nil, 5, 0)
+
+			adjustment, err := service.SubmitAdjustment(txContext(), user.ID, &models.StockAdjustmentRequest{
+				ProductID:  product.ID,
+				Quantity:   tt.quantity,
+				ReasonCode: models.StockAdjustmentDamage,
+				Reason:     "dropped",
+			})
+			if err != nil {
+				t.Fatalf("SubmitAdjustment failed: %v", err)
+			}
+			if adjustment.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, adjustment.Status)
+			}
+			if want := roundMoney(float64(abs(tt.quantity)) * 3.00); adjustment.Value != want {
+				t.Errorf("Expected a value of %.2f at cost, got %.2f", want, adjustment.Value)
+			}
+			if stock := products.products[product.ID].Stock; stock != tt.wantStock {
+				t.Errorf("Expected stock %d, got %d", tt.wantStock, stock)
+			}
+
+			if !tt.wantApplied {
+				if len(movements.movements) != 0 || len(audit.logs) != 0 {
+					t.Error("Expected no stock movement or audit entry for a pending adjustment")
+				}
+				return
+			}
+			if len(movements.movements) != 1 || movements.movements[0].Quantity != tt.quantity {
+				t.Errorf("Expected one movement of %d, got %+v", tt.quantity, movements.movements)
+			}
+			if len(audit.logs) != 1 {
+				t.Fatalf("Expected one audit entry, got %d", len(audit.logs))
+			}
+			for _, key := range []string{"stock", "quantity", "reasonCode", "adjustmentId", "requestedBy"} {
+				if _, ok := audit.logs[0].NewValues[key]; !ok {
+					t.Errorf("Expected %q in the audit new values, got %v", key, audit.logs[0].NewValues)
+				}
+			}
+		})
+	}
+}
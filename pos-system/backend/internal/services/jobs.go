@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// heldCartExpiryInterval is how often stale held carts are swept
+	heldCartExpiryInterval = 15 * time.Minute
+
+	// exportPurgeInterval is how often expired export files are deleted
+	exportPurgeInterval = 15 * time.Minute
+
+	// emailDeliveryInterval is how often queued emails are checked for
+	// delivery, which also bounds how long a checkout receipt waits
+	emailDeliveryInterval = 10 * time.Second
+
+	// tokenRevocationSyncInterval is how often access token revocations made
+	// by other instances are picked up
+	tokenRevocationSyncInterval = 15 * time.Second
+
+	// loginThrottlePurgeInterval is how often stale failed login counters
+	// are deleted
+	loginThrottlePurgeInterval = 15 * time.Minute
+
+	// twoFactorChallengePurgeInterval is how often abandoned two-factor
+	// login challenges are deleted
+	twoFactorChallengePurgeInterval = 15 * time.Minute
+)
+
+// StartBackgroundJobs launches the periodic maintenance jobs. They stop when
+// ctx is cancelled.
+func (s *Services) StartBackgroundJobs(ctx context.Context) {
+	go runEvery(ctx, "expire held carts", heldCartExpiryInterval, func(ctx context.Context) error {
+		deleted, err := s.Cart.ExpireHeldCarts(ctx)
+		if err == nil && deleted > 0 {
+			log.Printf("expired %d held cart(s)", deleted)
+		}
+		return err
+	})
+
+	go runEvery(ctx, "purge expired exports", exportPurgeInterval, func(ctx context.Context) error {
+		removed, err := s.Export.PurgeExpired(ctx)
+		if err == nil && removed > 0 {
+			log.Printf("purged %d expired export(s)", removed)
+		}
+		return err
+	})
+
+	go runEvery(ctx, "deliver queued emails", emailDeliveryInterval, func(ctx context.Context) error {
+		sent, failed, err := s.Receipt.DeliverPendingEmails(ctx)
+		if sent+failed > 0 {
+			log.Printf("emails: %d sent, %d failed", sent, failed)
+		}
+		return err
+	})
+
+	go runEvery(ctx, "sync token revocations", tokenRevocationSyncInterval, func(ctx context.Context) error {
+		_, err := s.revoker.Sync(ctx)
+		return err
+	})
+
+	go runEvery(ctx, "purge login throttles", loginThrottlePurgeInterval, func(ctx context.Context) error {
+		_, err := s.loginGuard.PurgeStale(ctx)
+		return err
+	})
+
+	go runEvery(ctx, "purge two-factor challenges", twoFactorChallengePurgeInterval, func(ctx context.Context) error {
+		_, err := s.TwoFactor.PurgeExpiredChallenges(ctx)
+		return err
+	})
+
+	if s.recommendationInterval > 0 {
+		go runEvery(ctx, "generate stock recommendations", s.recommendationInterval, func(ctx context.Context) error {
+			enabled, err := s.Recommendation.AutoGenerateEnabled(ctx)
+			if err != nil || !enabled {
+				return err
+			}
+			result, err := s.Recommendation.GenerateRecommendations(ctx)
+			if err == nil && result.Created+result.Updated+result.Expired > 0 {
+				log.Printf("stock recommendations: %d created, %d refreshed, %d expired", result.Created, result.Updated, result.Expired)
+			}
+			return err
+		})
+	}
+}
+
+// runEvery calls fn immediately and then every interval until ctx is
+// cancelled, logging failures
+func runEvery(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := fn(ctx); err != nil {
+			log.Printf("background job %q failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError reports how long a client must wait before trying to
+// sign in again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // locked out, rather than slowed down
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyLoginAttempts, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// Details returns the fields reported to API clients
+func (e *LoginThrottledError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"retryAfter": e.RetryAfterSeconds(),
+		"locked":     e.Locked,
+	}
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds for the Retry-After
+// header
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// LoginGuard slows down and then blocks repeated failed sign-ins. Each
+// failure on an account doubles the wait before the next attempt, starting
+// at baseDelay, and maxFailures failures lock it for lockout. Client IPs are
+// only locked, after maxIPFailures, because a store's tills usually share
+// one address. Failures are forgotten after lockout passes without another.
+type LoginGuard struct {
+	repo          repository.LoginThrottleRepository
+	db            *gorm.DB
+	maxFailures   int
+	maxIPFailures int
+	baseDelay     time.Duration
+	lockout       time.Duration
+}
+
+// NewLoginGuard creates a login guard
+func NewLoginGuard(
+	repo repository.LoginThrottleRepository,
+	db *gorm.DB,
+	maxFailures int,
+	maxIPFailures int,
+	baseDelay time.Duration,
+	lockout time.Duration,
+) *LoginGuard {
+	return &LoginGuard{
+		repo:          repo,
+		db:            db,
+		maxFailures:   maxFailures,
+		maxIPFailures: maxIPFailures,
+		baseDelay:     baseDelay,
+		lockout:       lockout,
+	}
+}
+
+// Check returns a *LoginThrottledError when the account or client IP may
+// not attempt to sign in yet
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	locked := false
+
+	account, err := g.get(ctx, models.LoginThrottleAccount, accountKey(email))
+	if err != nil {
+		return err
+	}
+	if account != nil {
+		if until, isLocked := g.blockedUntil(account, now); until.After(now) {
+			wait, locked = until.Sub(now), isLocked
+		}
+	}
+
+	if ip != "" {
+		client, err := g.get(ctx, models.LoginThrottleIP, ip)
+		if err != nil {
+			return err
+		}
+		if client != nil && client.LockedUntil != nil && client.LockedUntil.After(now) {
+			if w := client.LockedUntil.Sub(now); w > wait {
+				wait, locked = w, true
+			}
+		}
+	}
+
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait, Locked: locked}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed sign-in against the account and client IP.
+// When this failure locks the account, the time it unlocks is returned.
+func (g *LoginGuard) RecordFailure(ctx context.Context, email, ip string) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := repository.RunInTx(ctx, g.db, func(ctx context.Context) error {
+		var err error
+		lockedUntil, err = g.recordFailure(ctx, models.LoginThrottleAccount, accountKey(email), g.maxFailures)
+		if err != nil || ip == "" {
+			return err
+		}
+		_, err = g.recordFailure(ctx, models.LoginThrottleIP, ip, g.maxIPFailures)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return lockedUntil, nil
+}
+
+// RecordSuccess forgets the account's failed sign-ins
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string) error {
+	return g.Unlock(ctx, email)
+}
+
+// Unlock clears the account's failed sign-ins and any lockout
+func (g *LoginGuard) Unlock(ctx context.Context, email string) error {
+	if err := g.repo.Delete(ctx, models.LoginThrottleAccount, accountKey(email)); err != nil {
+		return fmt.Errorf("failed to clear login failures: %w", err)
+	}
+	return nil
+}
+
+// PurgeStale deletes throttles that no longer hold anything back
+func (g *LoginGuard) PurgeStale(ctx context.Context) (int64, error) {
+	deleted, err := g.repo.DeleteStale(ctx, time.Now().Add(-g.lockout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge login throttles: %w", err)
+	}
+	return deleted, nil
+}
+
+func (g *LoginGuard) get(ctx context.Context, scope, identifier string) (*models.LoginThrottle, error) {
+	throttle, err := g.repo.Get(ctx, scope, identifier)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login throttle: %w", err)
+	}
+	return throttle, nil
+}
+
+// recordFailure adds one failure to a stored throttle, locking it once max
+// is reached, and returns the lockout end if this failure started it
+func (g *LoginGuard) recordFailure(ctx context.Context, scope, identifier string, max int) (*time.Time, error) {
+	throttle, err := g.repo.Lock(ctx, scope, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := g.addFailure(throttle, time.Now(), max)
+	if err := g.repo.Update(ctx, throttle); err != nil {
+		return nil, err
+	}
+	return lockedUntil, nil
+}
+
+// addFailure counts a failure at now on throttle, starting afresh if its
+// earlier failures have expired, and returns the lockout end if this failure
+// reached max and started one
+func (g *LoginGuard) addFailure(throttle *models.LoginThrottle, now time.Time, max int) *time.Time {
+	if g.expired(throttle, now) {
+		throttle.Failures = 0
+		throttle.LockedUntil = nil
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+
+	if throttle.Failures >= max && throttle.LockedUntil == nil {
+		until := now.Add(g.lockout)
+		throttle.LockedUntil = &until
+		return &until
+	}
+	return nil
+}
+
+// expired reports whether a throttle's failures should be forgotten: its
+// lockout has ended, or no failure has happened for the lockout period
+func (g *LoginGuard) expired(throttle *models.LoginThrottle, now time.Time) bool {
+	if throttle.LockedUntil != nil {
+		return !now.Before(*throttle.LockedUntil)
+	}
+	return now.Sub(throttle.LastFailureAt) > g.lockout
+}
+
+// blockedUntil returns when an account may next try to sign in and whether
+// that is due to a lockout rather than back-off
+func (g *LoginGuard) blockedUntil(throttle *models.LoginThrottle, now time.Time) (time.Time, bool) {
+	if g.expired(throttle, now) || throttle.Failures == 0 {
+		return time.Time{}, false
+	}
+	if throttle.LockedUntil != nil {
+		return *throttle.LockedUntil, true
+	}
+
+	delay := g.baseDelay << min(throttle.Failures-1, 20)
+	if delay > g.lockout {
+		delay = g.lockout
+	}
+	return throttle.LastFailureAt.Add(delay), false
+}
+
+// accountKey normalises an email address so differently cased attempts
+// count against the same account
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+const (
+	testMaxFailures = 5
+	testBaseDelay   = time.Minute
+	testLockout     = 15 * time.Minute
+)
+
+func newTestLoginGuard() *LoginGuard {
+	return NewLoginGuard(nil, nil, testMaxFailures, 20, testBaseDelay, testLockout)
+}
+
+func timeAt(t time.Time) *time.Time {
+	return &t
+}
+
+func TestLoginGuardBlockedUntil(t *testing.T) {
+	guard := newTestLoginGuard()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		throttle   models.LoginThrottle
+		wantUntil  time.Time
+		wantLocked bool
+	}{
+		{
+			name:     "no failures",
+			throttle: models.LoginThrottle{LastFailureAt: now},
+		},
+		{
+			name:      "first failure waits the base delay",
+			throttle:  models.LoginThrottle{Failures: 1, LastFailureAt: now},
+			wantUntil: now.Add(testBaseDelay),
+		},
+		{
+			name:      "second failure doubles the delay",
+			throttle:  models.LoginThrottle{Failures: 2, LastFailureAt: now},
+			wantUntil: now.Add(2 * testBaseDelay),
+		},
+		{
+			name:      "fourth failure waits eight times the base",
+			throttle:  models.LoginThrottle{Failures: 4, LastFailureAt: now},
+			wantUntil: now.Add(8 * testBaseDelay),
+		},
+		{
+			name:      "delay is capped at the lockout",
+			throttle:  models.LoginThrottle{Failures: 5, LastFailureAt: now},
+			wantUntil: now.Add(testLockout),
+		},
+		{
+			name:      "a large failure count does not overflow the delay",
+			throttle:  models.LoginThrottle{Failures: 100, LastFailureAt: now},
+			wantUntil: now.Add(testLockout),
+		},
+		{
+			name:      "delay runs from the last failure",
+			throttle:  models.LoginThrottle{Failures: 3, LastFailureAt: now.Add(-time.Minute)},
+			wantUntil: now.Add(3 * testBaseDelay),
+		},
+		{
+			name: "locked account waits for the lockout",
+			throttle: models.LoginThrottle{
+				Failures:      testMaxFailures,
+				LastFailureAt: now.Add(-time.Minute),
+				LockedUntil:   timeAt(now.Add(14 * time.Minute)),
+			},
+			wantUntil:  now.Add(14 * time.Minute),
+			wantLocked: true,
+		},
+		{
+			name: "ended lockout no longer blocks",
+			throttle: models.LoginThrottle{
+				Failures:      testMaxFailures,
+				LastFailureAt: now.Add(-testLockout),
+				LockedUntil:   timeAt(now),
+			},
+		},
+		{
+			name:     "failures are forgotten after a quiet lockout period",
+			throttle: models.LoginThrottle{Failures: 4, LastFailureAt: now.Add(-testLockout - time.Second)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, locked := guard.blockedUntil(&tt.throttle, now)
+			if !until.Equal(tt.wantUntil) {
+				t.Errorf("Expected blocked until %v, got %v", tt.wantUntil, until)
+			}
+			if locked != tt.wantLocked {
+				t.Errorf("Expected locked %v, got %v", tt.wantLocked, locked)
+			}
+		})
+	}
+}
+
+func TestLoginGuardExpired(t *testing.T) {
+	guard := newTestLoginGuard()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		throttle models.LoginThrottle
+		want     bool
+	}{
+		{
+			name:     "recent failure",
+			throttle: models.LoginThrottle{Failures: 2, LastFailureAt: now.Add(-time.Minute)},
+			want:     false,
+		},
+		{
+			name:     "last failure exactly one lockout ago",
+			throttle: models.LoginThrottle{Failures: 2, LastFailureAt: now.Add(-testLockout)},
+			want:     false,
+		},
+		{
+			name:     "last failure over one lockout ago",
+			throttle: models.LoginThrottle{Failures: 2, LastFailureAt: now.Add(-testLockout - time.Second)},
+			want:     true,
+		},
+		{
+			name:     "lockout still running",
+			throttle: models.LoginThrottle{Failures: 5, LastFailureAt: now.Add(-time.Hour), LockedUntil: timeAt(now.Add(time.Second))},
+			want:     false,
+		},
+		{
+			name:     "lockout ends now",
+			throttle: models.LoginThrottle{Failures: 5, LastFailureAt: now, LockedUntil: timeAt(now)},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guard.expired(&tt.throttle, now); got != tt.want {
+				t.Errorf("Expected expired %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoginGuardAddFailure(t *testing.T) {
+	guard := newTestLoginGuard()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(-time.Minute).Add(testLockout)
+
+	tests := []struct {
+		name            string
+		throttle        models.LoginThrottle
+		wantFailures    int
+		wantLockedUntil *time.Time // the throttle's lockout after the failure
+		wantStarted     bool       // whether this failure started the lockout
+	}{
+		{
+			name:         "first failure",
+			throttle:     models.LoginThrottle{},
+			wantFailures: 1,
+		},
+		{
+			name:         "failure below the limit",
+			throttle:     models.LoginThrottle{Failures: 3, LastFailureAt: now.Add(-time.Minute)},
+			wantFailures: 4,
+		},
+		{
+			name:            "failure reaching the limit locks",
+			throttle:        models.LoginThrottle{Failures: testMaxFailures - 1, LastFailureAt: now.Add(-time.Minute)},
+			wantFailures:    testMaxFailures,
+			wantLockedUntil: timeAt(now.Add(testLockout)),
+			wantStarted:     true,
+		},
+		{
+			name: "failure while locked keeps the lockout",
+			throttle: models.LoginThrottle{
+				Failures:      testMaxFailures,
+				LastFailureAt: now.Add(-time.Minute),
+				LockedUntil:   &lockedUntil,
+			},
+			wantFailures:    testMaxFailures + 1,
+			wantLockedUntil: &lockedUntil,
+		},
+		{
+			name: "failure after the lockout starts afresh",
+			throttle: models.LoginThrottle{
+				Failures:      testMaxFailures,
+				LastFailureAt: now.Add(-testLockout - time.Minute),
+				LockedUntil:   timeAt(now.Add(-time.Minute)),
+			},
+			wantFailures: 1,
+		},
+		{
+			name:         "failure after a quiet lockout period starts afresh",
+			throttle:     models.LoginThrottle{Failures: testMaxFailures - 1, LastFailureAt: now.Add(-testLockout - time.Second)},
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttle := tt.throttle
+			started := guard.addFailure(&throttle, now, testMaxFailures)
+
+			if throttle.Failures != tt.wantFailures {
+				t.Errorf("Expected %d failures, got %d", tt.wantFailures, throttle.Failures)
+			}
+			if !throttle.LastFailureAt.Equal(now) {
+				t.Errorf("Expected last failure at %v, got %v", now, throttle.LastFailureAt)
+			}
+			switch {
+			case tt.wantLockedUntil == nil && throttle.LockedUntil != nil:
+				t.Errorf("Expected no lockout, got one until %v", *throttle.LockedUntil)
+			case tt.wantLockedUntil != nil && (throttle.LockedUntil == nil || !throttle.LockedUntil.Equal(*tt.wantLockedUntil)):
+				t.Errorf("Expected a lockout until %v, got %v", *tt.wantLockedUntil, throttle.LockedUntil)
+			}
+			if (started != nil) != tt.wantStarted {
+				t.Errorf("Expected lockout started %v, got %v", tt.wantStarted, started)
+			}
+		})
+	}
+}
+
+func TestLoginGuardFailureSequence(t *testing.T) {
+	guard := newTestLoginGuard()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := &models.LoginThrottle{}
+
+	// Each failure is made as soon as the previous delay allows
+	now := start
+	wantDelay := testBaseDelay
+	for i := 1; i < testMaxFailures; i++ {
+		if started := guard.addFailure(throttle, now, testMaxFailures); started != nil {
+			t.Fatalf("Failure %d locked the account early", i)
+		}
+		until, locked := guard.blockedUntil(throttle, now)
+		if locked || until.Sub(now) != wantDelay {
+			t.Fatalf("After failure %d expected a %v back-off, got %v locked %v", i, wantDelay, until.Sub(now), locked)
+		}
+		now = until
+		wantDelay *= 2
+	}
+
+	started := guard.addFailure(throttle, now, testMaxFailures)
+	if started == nil || !started.Equal(now.Add(testLockout)) {
+		t.Fatalf("Expected failure %d to lock until %v, got %v", testMaxFailures, now.Add(testLockout), started)
+	}
+	if until, locked := guard.blockedUntil(throttle, now); !locked || !until.Equal(*started) {
+		t.Errorf("Expected a lockout until %v, got %v locked %v", *started, until, locked)
+	}
+
+	// Once the lockout passes the account may sign in and starts over
+	now = started.Add(time.Second)
+	if until, _ := guard.blockedUntil(throttle, now); !until.IsZero() {
+		t.Errorf("Expected no block after the lockout, got one until %v", until)
+	}
+	guard.addFailure(throttle, now, testMaxFailures)
+	if until, locked := guard.blockedUntil(throttle, now); throttle.Failures != 1 || locked || until.Sub(now) != testBaseDelay {
+		t.Errorf("Expected the first failure after a lockout to wait the base delay, got %d failures, %v locked %v", throttle.Failures, until.Sub(now), locked)
+	}
+}
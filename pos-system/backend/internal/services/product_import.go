@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/export"
+)
+
+var (
+	ErrUnsupportedImportFile = errors.New("import file must be a .csv or .xlsx file")
+	ErrImportMissingSKU      = errors.New("import file must have a sku column")
+	ErrImportEmpty           = errors.New("import file has no product rows")
+	ErrImportTooManyRows     = errors.New("import file has too many rows")
+)
+
+// importColumns maps normalised header names, including common spreadsheet
+// spellings, to the product field they fill
+var importColumns = map[string]string{
+	"sku":           "sku",
+	"name":          "name",
+	"product_name":  "name",
+	"description":   "description",
+	"barcode":       "barcode",
+	"upc":           "barcode",
+	"ean":           "barcode",
+	"category":      "category",
+	"category_name": "category",
+	"price":         "price",
+	"unit_price":    "price",
+	"selling_price": "price",
+	"cost":          "cost",
+	"cost_price":    "cost",
+	"unit_cost":     "cost",
+	"stock":         "stock",
+	"quantity":      "stock",
+	"qty":           "stock",
+	"min_stock":     "min_stock",
+	"reorder_level": "min_stock",
+	"max_stock":     "max_stock",
+	"status":        "status",
+	"image_url":     "image_url",
+	"weight":        "weight",
+	"dimensions":    "dimensions",
+	"supplier":      "supplier",
+	"notes":         "notes",
+	"is_active":     "is_active",
+	"active":        "is_active",
+}
+
+// importRow is one parsed spreadsheet row. Only non-blank cells are set, so
+// updates leave the fields of blank cells unchanged.
+type importRow struct {
+	index    int
+	sku      string
+	category string
+	fields   models.UpdateProductRequest
+	existing *models.Product
+	errors   []string
+}
+
+// ImportProducts reads products from a CSV or XLSX file and upserts them by
+// SKU. Rows for new products need a name, price, cost and category;
+// categories are matched by name and created when missing. Each row is
+// saved on its own, so a bad row is reported without stopping the others.
+// With dryRun set nothing is written and the result previews the import.
+func (s *ProductService) ImportProducts(ctx context.Context, userID uuid.UUID, fileName string, size int64, file io.ReaderAt, dryRun bool) (*models.ProductImportResult, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var format export.Format
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		format = export.FormatCSV
+	case ".xlsx":
+		format = export.FormatXLSX
+	default:
+		return nil, ErrUnsupportedImportFile
+	}
+	if size > s.maxImportSize {
+		return nil, ErrFileTooLarge
+	}
+
+	sheet, err := export.ReadAll(format, file, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImportFile, err)
+	}
+	if len(sheet) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	columns := make(map[int]string, len(sheet[0]))
+	hasSKU := false
+	for i, header := range sheet[0] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if field, ok := importColumns[name]; ok {
+			columns[i] = field
+			hasSKU = hasSKU || field == "sku"
+		}
+	}
+	if !hasSKU {
+		return nil, ErrImportMissingSKU
+	}
+
+	var rows []*importRow
+	for i, cells := range sheet[1:] {
+		if blankRow(cells) {
+			continue
+		}
+		rows = append(rows, parseImportRow(i+2, cells, columns))
+	}
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if len(rows) > s.maxImportRows {
+		return nil, ErrImportTooManyRows
+	}
+
+	categories, err := s.planImport(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductImportResult{
+		BulkOperationResult: models.BulkOperationResult{
+			Operation:      "import",
+			TotalRequested: len(rows),
+		},
+		DryRun: dryRun,
+		Rows:   make([]models.ProductImportRow, 0, len(rows)),
+	}
+	for name, id := range categories {
+		if id == nil {
+			result.CategoriesCreated = append(result.CategoriesCreated, name)
+		}
+	}
+	sort.Strings(result.CategoriesCreated)
+
+	for _, row := range rows {
+		if len(row.errors) == 0 && !dryRun {
+			if err := s.applyImportRow(ctx, userID, row, categories); err != nil {
+				row.errors = append(row.errors, importErrorMessage(err))
+			}
+		}
+
+		action := models.ImportActionCreate
+		if row.existing != nil {
+			action = models.ImportActionUpdate
+		}
+		if len(row.errors) > 0 {
+			action = models.ImportActionFailed
+			result.Failed++
+			result.Errors = append(result.Errors, models.BulkOperationError{
+				Index:   row.index,
+				ID:      row.sku,
+				Message: strings.Join(row.errors, "; "),
+			})
+		} else {
+			result.Successful++
+			if action == models.ImportActionCreate {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+		result.Rows = append(result.Rows, models.ProductImportRow{Index: row.index, SKU: row.sku, Action: action})
+	}
+
+	return result, nil
+}
+
+// parseImportRow reads a row's cells into product fields, collecting a
+// message for every cell that cannot be used
+func parseImportRow(index int, cells []string, columns map[int]string) *importRow {
+	row := &importRow{index: index}
+	fields := &row.fields
+
+	for i, cell := range cells {
+		field, ok := columns[i]
+		value := strings.TrimSpace(cell)
+		if !ok || value == "" {
+			continue
+		}
+
+		switch field {
+		case "sku":
+			row.sku = value
+		case "name":
+			fields.Name = &value
+		case "description":
+			fields.Description = &value
+		case "barcode":
+			fields.Barcode = &value
+		case "category":
+			row.category = value
+		case "price":
+			fields.Price = row.amount(field, value)
+		case "cost":
+			fields.Cost = row.amount(field, value)
+		case "weight":
+			fields.Weight = row.amount(field, value)
+		case "stock":
+			fields.Stock = row.count(field, value)
+		case "min_stock":
+			fields.MinStock = row.count(field, value)
+		case "max_stock":
+			fields.MaxStock = row.count(field, value)
+		case "status":
+			status := models.ProductStatus(strings.ToLower(value))
+			if validProductStatus(status) {
+				fields.Status = &status
+			} else {
+				row.errors = append(row.errors, "status must be active, inactive or discontinued")
+			}
+		case "image_url":
+			fields.ImageURL = &value
+		case "dimensions":
+			fields.Dimensions = &value
+		case "supplier":
+			fields.Supplier = &value
+		case "notes":
+			fields.Notes = &value
+		case "is_active":
+			switch strings.ToLower(value) {
+			case "true", "yes", "y", "1":
+				active := true
+				fields.IsActive = &active
+			case "false", "no", "n", "0":
+				active := false
+				fields.IsActive = &active
+			default:
+				row.errors = append(row.errors, "is_active must be yes or no")
+			}
+		}
+	}
+
+	if row.sku == "" {
+		row.errors = append(row.errors, "sku is required")
+	}
+	return row
+}
+
+func (r *importRow) amount(field, value string) *float64 {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount < 0 {
+		r.errors = append(r.errors, field+" must be a number of 0 or more")
+		return nil
+	}
+	amount = roundMoney(amount)
+	return &amount
+}
+
+// count accepts whole numbers, including spreadsheet values such as "12.0"
+func (r *importRow) count(field, value string) *int {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || number != float64(int(number)) {
+		r.errors = append(r.errors, field+" must be a whole number of 0 or more")
+		return nil
+	}
+	count := int(number)
+	return &count
+}
+
+// planImport checks each row against the catalogue and the rest of the
+// file without writing anything. It returns the categories the rows name,
+// keyed by the name as written, with nil IDs for ones that do not exist.
+func (s *ProductService) planImport(ctx context.Context, rows []*importRow) (map[string]*uuid.UUID, error) {
+	categories := make(map[string]*uuid.UUID)
+	byName := make(map[string]string) // lower-cased name to name as first written
+	skus := make(map[string]int)
+	barcodes := make(map[string]int)
+
+	for _, row := range rows {
+		if row.sku != "" {
+			if first, ok := skus[row.sku]; ok {
+				row.errors = append(row.errors, fmt.Sprintf("SKU also appears on row %d", first))
+			} else {
+				skus[row.sku] = row.index
+			}
+		}
+		if barcode := row.fields.Barcode; barcode != nil {
+			if first, ok := barcodes[*barcode]; ok {
+				row.errors = append(row.errors, fmt.Sprintf("barcode also appears on row %d", first))
+			} else {
+				barcodes[*barcode] = row.index
+			}
+		}
+		if len(row.errors) > 0 {
+			continue
+		}
+
+		existing, err := s.productRepo.GetBySKU(ctx, row.sku)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up SKU: %w", err)
+		}
+		if err == nil {
+			row.existing = existing
+		}
+
+		if row.existing == nil {
+			if row.fields.Name == nil {
+				row.errors = append(row.errors, "name is required for new products")
+			}
+			if row.fields.Price == nil {
+				row.errors = append(row.errors, "price is required for new products")
+			}
+			if row.fields.Cost == nil {
+				row.errors = append(row.errors, "cost is required for new products")
+			}
+			if row.category == "" {
+				row.errors = append(row.errors, "category is required for new products")
+			}
+		}
+
+		minStock, maxStock := row.stockLevels()
+		if maxStock < minStock {
+			row.errors = append(row.errors, ErrInvalidStockLevels.Error())
+		}
+
+		selfID := uuid.Nil
+		if row.existing != nil {
+			selfID = row.existing.ID
+		}
+		if err := s.ensureUnique(ctx, row.sku, row.fields.Barcode, selfID); err != nil {
+			if !errors.Is(err, ErrProductExists) {
+				return nil, err
+			}
+			row.errors = append(row.errors, "barcode is already used by another product")
+		}
+
+		if row.category != "" {
+			key := strings.ToLower(row.category)
+			if name, ok := byName[key]; ok {
+				row.category = name
+				continue
+			}
+			byName[key] = row.category
+
+			category, err := s.categoryRepo.GetByName(ctx, row.category)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("failed to look up category: %w", err)
+			}
+			categories[row.category] = nil
+			if err == nil {
+				categories[row.category] = &category.ID
+			}
+		}
+	}
+
+	return categories, nil
+}
+
+// stockLevels returns the row's min and max stock, taking missing values
+// from the existing product. New products without a max stock get their min.
+func (r *importRow) stockLevels() (int, int) {
+	minStock, maxStock := 0, 0
+	if r.existing != nil {
+		minStock, maxStock = r.existing.MinStock, r.existing.MaxStock
+	}
+	if r.fields.MinStock != nil {
+		minStock = *r.fields.MinStock
+	}
+	if r.fields.MaxStock != nil {
+		maxStock = *r.fields.MaxStock
+	} else if r.existing == nil {
+		maxStock = minStock
+	}
+	return minStock, maxStock
+}
+
+// applyImportRow creates or updates the row's product, creating its
+// category first if needed
+func (s *ProductService) applyImportRow(ctx context.Context, userID uuid.UUID, row *importRow, categories map[string]*uuid.UUID) error {
+	if row.category != "" {
+		categoryID, err := s.importCategory(ctx, row.category, categories)
+		if err != nil {
+			return err
+		}
+		row.fields.CategoryID = &categoryID
+	}
+
+	if row.existing != nil {
+		_, err := s.UpdateProduct(ctx, userID, row.existing.ID, &row.fields)
+		return err
+	}
+
+	minStock, maxStock := row.stockLevels()
+	req := &models.CreateProductRequest{
+		Name:       *row.fields.Name,
+		SKU:        row.sku,
+		CategoryID: *row.fields.CategoryID,
+		Price:      *row.fields.Price,
+		Cost:       *row.fields.Cost,
+		MinStock:   minStock,
+		MaxStock:   maxStock,
+	}
+	fields := &row.fields
+	if fields.Description != nil {
+		req.Description = *fields.Description
+	}
+	if fields.Barcode != nil {
+		req.Barcode = *fields.Barcode
+	}
+	if fields.Stock != nil {
+		req.Stock = *fields.Stock
+	}
+	if fields.Status != nil {
+		req.Status = *fields.Status
+	}
+	if fields.ImageURL != nil {
+		req.ImageURL = *fields.ImageURL
+	}
+	if fields.Weight != nil {
+		req.Weight = *fields.Weight
+	}
+	if fields.Dimensions != nil {
+		req.Dimensions = *fields.Dimensions
+	}
+	if fields.Supplier != nil {
+		req.Supplier = *fields.Supplier
+	}
+	if fields.Notes != nil {
+		req.Notes = *fields.Notes
+	}
+	if fields.IsActive != nil {
+		req.IsActive = *fields.IsActive
+	}
+
+	_, err := s.CreateProduct(ctx, userID, req)
+	return err
+}
+
+// importCategory returns the ID of the named category, creating it on first
+// use. Created categories are remembered so later rows reuse them.
+func (s *ProductService) importCategory(ctx context.Context, name string, categories map[string]*uuid.UUID) (uuid.UUID, error) {
+	if id := categories[name]; id != nil {
+		return *id, nil
+	}
+
+	category := &models.Category{
+		ID:       uuid.New(),
+		Name:     name,
+		IsActive: true,
+	}
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return uuid.Nil, fmt.Errorf("failed to create category: %w", err)
+		}
+		// Created concurrently since the import was planned
+		existing, err := s.categoryRepo.GetByName(ctx, name)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to get category: %w", err)
+		}
+		category = existing
+	}
+
+	categories[name] = &category.ID
+	return category.ID, nil
+}
+
+// importErrorMessage describes a failed row. Service errors are shown as
+// they are; anything else is logged and reported generically.
+func importErrorMessage(err error) string {
+	for _, known := range []error{ErrProductExists, ErrInvalidStockLevels, ErrInvalidStatus, ErrCategoryNotFound, ErrProductNotFound} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	log.Printf("product import: %v", err)
+	return "product could not be saved"
+}
+
+func blankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
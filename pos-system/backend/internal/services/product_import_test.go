@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type importFixture struct {
+	service    *ProductService
+	user       *models.User
+	kitchen    *models.Category
+	mug        *models.Product
+	products   *fakeProductRepo
+	categories *fakeCategoryRepo
+	movements  *fakeStockMovementRepo
+	audit      *fakeAuditRepo
+}
+
+// newImportFixture has one product, a mug in the Kitchen category
+func newImportFixture() *importFixture {
+	f := &importFixture{
+		user:      &models.User{ID: uuid.New(), Name: "Morgan", Role: models.RoleManager, IsActive: true},
+		kitchen:   &models.Category{ID: uuid.New(), Name: "Kitchen", IsActive: true},
+		mug:       newTestProduct("mug", 8.00, 3.00, 4),
+		movements: &fakeStockMovementRepo{},
+		audit:     &fakeAuditRepo{},
+	}
+	f.mug.CategoryID = f.kitchen.ID
+	f.mug.MinStock, f.mug.MaxStock = 2, 20
+	f.products = newFakeProductRepo(f.mug)
+	f.categories = newFakeCategoryRepo(f.kitchen)
+	f.service = NewProductService(f.products, f.categories, f.movements, newFakeUserRepo(f.user), f.audit, nil, 1<<20, 100, 100)
+	return f
+}
+
+func (f *importFixture) importCSV(t *testing.T, csv string, dryRun bool) *models.ProductImportResult {
+	t.Helper()
+	result, err := f.service.ImportProducts(txContext(), f.user.ID, "products.csv", int64(len(csv)), strings.NewReader(csv), dryRun)
+	if err != nil {
+		t.Fatalf("ImportProducts failed: %v", err)
+	}
+	return result
+}
+
+const importFile = `SKU,Product Name,Price,Cost,Qty,Category,Barcode
+SKU-mug,,9.50,,10,,
+NEW-1,Teapot,25,10,3,kitchen,123
+NEW-2,Tea cosy,6,2,0,Textiles,
+BAD-1,Broken,abc,1,1,Kitchen,
+NEW-1,Teapot again,1,1,1,Kitchen,
+`
+
+func importActions(result *models.ProductImportResult) []string {
+	actions := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		actions = append(actions, row.SKU+":"+row.Action)
+	}
+	return actions
+}
+
+func TestImportProductsDryRun(t *testing.T) {
+	f := newImportFixture()
+
+	preview := f.importCSV(t, importFile, true)
+
+	if !preview.DryRun || preview.Created != 2 || preview.Updated != 1 || preview.Failed != 2 {
+		t.Errorf("Expected a dry run of 2 created, 1 updated and 2 failed, got %+v", preview)
+	}
+	if !reflect.DeepEqual(preview.CategoriesCreated, []string{"Textiles"}) {
+		t.Errorf("Expected Textiles to be created, got %v", preview.CategoriesCreated)
+	}
+	if len(f.products.products) != 1 || len(f.categories.categories) != 1 {
+		t.Errorf("Expected no rows written, got %d products and %d categories", len(f.products.products), len(f.categories.categories))
+	}
+	if mug := f.products.products[f.mug.ID]; mug.Price != 8.00 || mug.Stock != 4 {
+		t.Errorf("Expected the mug unchanged, got price %.2f and stock %d", mug.Price, mug.Stock)
+	}
+	if len(f.movements.movements) != 0 || len(f.audit.logs) != 0 {
+		t.Error("Expected no stock movements or audit entries from a dry run")
+	}
+
+	// The real import does what the preview said
+	result := f.importCSV(t, importFile, false)
+	if !reflect.DeepEqual(importActions(result), importActions(preview)) {
+		t.Errorf("Expected the import to match its preview %v, got %v", importActions(preview), importActions(result))
+	}
+	if !reflect.DeepEqual(result.Errors, preview.Errors) {
+		t.Errorf("Expected the same errors as the preview %v, got %v", preview.Errors, result.Errors)
+	}
+	if len(f.products.products) != 3 || len(f.categories.categories) != 2 {
+		t.Errorf("Expected 3 products in 2 categories, got %d and %d", len(f.products.products), len(f.categories.categories))
+	}
+}
+
+func TestImportProductsRowErrors(t *testing.T) {
+	f := newImportFixture()
+	result := f.importCSV(t, importFile, true)
+
+	want := map[int]string{
+		5: "price must be a number of 0 or more",
+		6: "SKU also appears on row 3",
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("Expected %d row errors, got %v", len(want), result.Errors)
+	}
+	for _, rowErr := range result.Errors {
+		if want[rowErr.Index] != rowErr.Message {
+			t.Errorf("Expected row %d to fail with %q, got %q", rowErr.Index, want[rowErr.Index], rowErr.Message)
+		}
+	}
+}
+
+func TestImportProductsUpsertBySKU(t *testing.T) {
+	f := newImportFixture()
+
+	result := f.importCSV(t, importFile, false)
+	if result.Created != 2 || result.Updated != 1 {
+		t.Fatalf("Expected 2 created and 1 updated, got %+v", result)
+	}
+
+	// Blank cells leave the existing product's fields alone
+	mug := f.products.products[f.mug.ID]
+	if mug.Name != "mug" || mug.Price != 9.50 || mug.Cost != 3.00 || mug.Stock != 10 || mug.CategoryID != f.kitchen.ID {
+		t.Errorf("Expected only the mug's price and stock updated, got %+v", mug)
+	}
+
+	teapot, err := f.products.GetBySKU(context.Background(), "NEW-1")
+	if err != nil {
+		t.Fatalf("Expected NEW-1 to be created: %v", err)
+	}
+	if teapot.Name != "Teapot" || teapot.CategoryID != f.kitchen.ID || teapot.Barcode == nil || *teapot.Barcode != "123" {
+		t.Errorf("Expected the teapot in Kitchen with its barcode, got %+v", teapot)
+	}
+	cosy, err := f.products.GetBySKU(context.Background(), "NEW-2")
+	if err != nil {
+		t.Fatalf("Expected NEW-2 to be created: %v", err)
+	}
+	textiles, err := f.categories.GetByName(context.Background(), "Textiles")
+	if err != nil || cosy.CategoryID != textiles.ID {
+		t.Errorf("Expected the tea cosy in a new Textiles category, got %v", err)
+	}
+
+	// Importing the same SKU again updates it instead of adding another
+	again := f.importCSV(t, "sku,name,price\nNEW-1,Teapot,27.50\n", false)
+	if again.Created != 0 || again.Updated != 1 {
+		t.Errorf("Expected the existing SKU to be updated, got %+v", again)
+	}
+	teapot, _ = f.products.GetBySKU(context.Background(), "NEW-1")
+	if len(f.products.products) != 3 || teapot.Price != 27.50 {
+		t.Errorf("Expected 3 products with the teapot at 27.50, got %d at %.2f", len(f.products.products), teapot.Price)
+	}
+}
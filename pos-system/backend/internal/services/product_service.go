@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrProductExists      = errors.New("a product with this SKU or barcode already exists")
+	ErrInvalidStockLevels = errors.New("max stock must not be below min stock")
+	ErrInvalidStatus      = errors.New("invalid product status")
+)
+
+// ProductService handles product catalog management
+type ProductService struct {
+	productRepo       repository.ProductRepository
+	categoryRepo      repository.CategoryRepository
+	stockMovementRepo repository.StockMovementRepository
+	userRepo          repository.UserRepository
+	auditRepo         repository.AuditLogRepository
+	db                *gorm.DB
+	maxImportSize     int64
+	maxImportRows     int
+	maxBulkItems      int
+}
+
+// NewProductService creates a new product service. Import files are limited
+// to maxImportSize bytes and maxImportRows product rows, and bulk operations
+// to maxBulkItems records.
+func NewProductService(
+	productRepo repository.ProductRepository,
+	categoryRepo repository.CategoryRepository,
+	stockMovementRepo repository.StockMovementRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	maxImportSize int64,
+	maxImportRows int,
+	maxBulkItems int,
+) *ProductService {
+	return &ProductService{
+		productRepo:       productRepo,
+		categoryRepo:      categoryRepo,
+		stockMovementRepo: stockMovementRepo,
+		userRepo:          userRepo,
+		auditRepo:         auditRepo,
+		db:                db,
+		maxImportSize:     maxImportSize,
+		maxImportRows:     maxImportRows,
+		maxBulkItems:      maxBulkItems,
+	}
+}
+
+// ListProducts retrieves products matching the filters
+func (s *ProductService) ListProducts(ctx context.Context, filters *models.ProductFilters, pagination *models.PaginationQuery) ([]models.Product, int64, error) {
+	products, total, err := s.productRepo.List(ctx, filters, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list products: %w", err)
+	}
+	return products, total, nil
+}
+
+// GetProduct retrieves a product by ID
+func (s *ProductService) GetProduct(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	return s.lookup(s.productRepo.GetByID(ctx, id))
+}
+
+// GetProductBySKU retrieves a product by its SKU
+func (s *ProductService) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	return s.lookup(s.productRepo.GetBySKU(ctx, sku))
+}
+
+// GetProductByBarcode retrieves a product by its barcode
+func (s *ProductService) GetProductByBarcode(ctx context.Context, barcode string) (*models.Product, error) {
+	return s.lookup(s.productRepo.GetByBarcode(ctx, barcode))
+}
+
+// GetLowStockProducts returns active products at or below threshold or
+// their own minimum stock level
+func (s *ProductService) GetLowStockProducts(ctx context.Context, threshold int) ([]models.Product, error) {
+	products, err := s.productRepo.GetLowStock(ctx, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get low stock products: %w", err)
+	}
+	return products, nil
+}
+
+// GetOutOfStockProducts returns active products with no stock
+func (s *ProductService) GetOutOfStockProducts(ctx context.Context) ([]models.Product, error) {
+	products, err := s.productRepo.GetOutOfStock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get out of stock products: %w", err)
+	}
+	return products, nil
+}
+
+// CreateProduct adds a product to the catalog. Opening stock is recorded as
+// a stock movement.
+func (s *ProductService) CreateProduct(ctx context.Context, userID uuid.UUID, req *models.CreateProductRequest) (*models.Product, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = models.ProductStatusActive
+	}
+	if !validProductStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	if req.MaxStock < req.MinStock {
+		return nil, ErrInvalidStockLevels
+	}
+
+	product := &models.Product{
+		ID:          uuid.New(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		SKU:         strings.TrimSpace(req.SKU),
+		Barcode:     optionalString(req.Barcode),
+		CategoryID:  req.CategoryID,
+		Price:       req.Price,
+		Cost:        req.Cost,
+		Stock:       req.Stock,
+		MinStock:    req.MinStock,
+		MaxStock:    req.MaxStock,
+		Status:      status,
+		ImageURL:    req.ImageURL,
+		Weight:      req.Weight,
+		Dimensions:  req.Dimensions,
+		Supplier:    req.Supplier,
+		Notes:       req.Notes,
+		// Products are sellable unless created in a non-active status
+		IsActive: req.IsActive || status == models.ProductStatusActive,
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.ensureCategory(ctx, product.CategoryID); err != nil {
+			return err
+		}
+		if err := s.ensureUnique(ctx, product.SKU, product.Barcode, uuid.Nil); err != nil {
+			return err
+		}
+
+		if err := s.productRepo.Create(ctx, product); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrProductExists
+			}
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+
+		if product.Stock > 0 {
+			movement := &models.StockMovement{
+				ProductID:   product.ID,
+				Type:        models.StockMovementIn,
+				Quantity:    product.Stock,
+				Reason:      "initial stock",
+				PerformedBy: userID,
+			}
+			if err := s.stockMovementRepo.Create(ctx, movement); err != nil {
+				return fmt.Errorf("failed to record stock movement: %w", err)
+			}
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionCreateProduct, "product", product.ID.String(), nil, productAuditValues(product))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProduct(ctx, product.ID)
+}
+
+// UpdateProduct applies the non-nil fields of req to a product. A stock
+// change is recorded as a stock movement for the difference.
+func (s *ProductService) UpdateProduct(ctx context.Context, userID, id uuid.UUID, req *models.UpdateProductRequest) (*models.Product, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		product, err := s.productRepo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return fmt.Errorf("failed to lock product: %w", err)
+		}
+		oldValues := productAuditValues(product)
+		oldStock := product.Stock
+
+		if req.Name != nil {
+			product.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			product.Description = *req.Description
+		}
+		if req.SKU != nil {
+			product.SKU = strings.TrimSpace(*req.SKU)
+		}
+		if req.Barcode != nil {
+			product.Barcode = optionalString(*req.Barcode)
+		}
+		if req.CategoryID != nil {
+			if err := s.ensureCategory(ctx, *req.CategoryID); err != nil {
+				return err
+			}
+			product.CategoryID = *req.CategoryID
+		}
+		if req.Price != nil {
+			product.Price = *req.Price
+		}
+		if req.Cost != nil {
+			product.Cost = *req.Cost
+		}
+		if req.Stock != nil {
+			product.Stock = *req.Stock
+		}
+		if req.MinStock != nil {
+			product.MinStock = *req.MinStock
+		}
+		if req.MaxStock != nil {
+			product.MaxStock = *req.MaxStock
+		}
+		if req.Status != nil {
+			if !validProductStatus(*req.Status) {
+				return ErrInvalidStatus
+			}
+			product.Status = *req.Status
+		}
+		if req.ImageURL != nil {
+			product.ImageURL = *req.ImageURL
+		}
+		if req.Weight != nil {
+			product.Weight = *req.Weight
+		}
+		if req.Dimensions != nil {
+			product.Dimensions = *req.Dimensions
+		}
+		if req.Supplier != nil {
+			product.Supplier = *req.Supplier
+		}
+		if req.Notes != nil {
+			product.Notes = *req.Notes
+		}
+		if req.IsActive != nil {
+			product.IsActive = *req.IsActive
+		}
+
+		if product.MaxStock < product.MinStock {
+			return ErrInvalidStockLevels
+		}
+		if req.SKU != nil || req.Barcode != nil {
+			if err := s.ensureUnique(ctx, product.SKU, product.Barcode, product.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.productRepo.Update(ctx, product); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrProductExists
+			}
+			return fmt.Errorf("failed to update product: %w", err)
+		}
+
+		if delta := product.Stock - oldStock; delta != 0 {
+			movement := &models.StockMovement{
+				ProductID:   product.ID,
+				Type:        models.StockMovementIn,
+				Quantity:    delta,
+				Reason:      "product update",
+				PerformedBy: userID,
+			}
+			if delta < 0 {
+				movement.Type = models.StockMovementOut
+				movement.Quantity = -delta
+			}
+			if err := s.stockMovementRepo.Create(ctx, movement); err != nil {
+				return fmt.Errorf("failed to record stock movement: %w", err)
+			}
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionUpdateProduct, "product", product.ID.String(), oldValues, productAuditValues(product))
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProduct(ctx, id)
+}
+
+// DeleteProduct removes a product from the catalog. Products are soft
+// deleted so past transactions keep their references.
+func (s *ProductService) DeleteProduct(ctx context.Context, userID, id uuid.UUID) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		product, err := s.GetProduct(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.productRepo.Delete(ctx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProductNotFound
+			}
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, user, models.AuditActionDeleteProduct, "product", id.String(), productAuditValues(product), nil)
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+}
+
+// BulkProducts creates, updates or deletes many products at once. Each
+// record goes through the single-product path, so it is validated and
+// audited the same way.
+func (s *ProductService) BulkProducts(ctx context.Context, userID uuid.UUID, op *models.BulkOperation) (*models.BulkOperationResult, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := planBulk(op, s.maxBulkItems, bulkActions{
+		create: func(payload json.RawMessage) bulkItem {
+			var req models.CreateProductRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{err: err}
+			}
+			return bulkItem{id: req.SKU, apply: func(ctx context.Context) error {
+				_, err := s.CreateProduct(ctx, userID, &req)
+				return err
+			}}
+		},
+		update: func(id uuid.UUID, payload json.RawMessage) bulkItem {
+			var req models.UpdateProductRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{id: id.String(), err: err}
+			}
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				_, err := s.UpdateProduct(ctx, userID, id, &req)
+				return err
+			}}
+		},
+		delete: func(id uuid.UUID) bulkItem {
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				return s.DeleteProduct(ctx, userID, id)
+			}}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return runBulk(ctx, s.db, op, items)
+}
+
+// lookup maps a repository product lookup onto service errors
+func (s *ProductService) lookup(product *models.Product, err error) (*models.Product, error) {
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return product, nil
+}
+
+func (s *ProductService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// ensureCategory checks that the category exists
+func (s *ProductService) ensureCategory(ctx context.Context, categoryID uuid.UUID) error {
+	if _, err := s.categoryRepo.GetByID(ctx, categoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return fmt.Errorf("failed to get category: %w", err)
+	}
+	return nil
+}
+
+// ensureUnique checks that no other product uses the SKU or barcode
+func (s *ProductService) ensureUnique(ctx context.Context, sku string, barcode *string, selfID uuid.UUID) error {
+	existing, err := s.productRepo.GetBySKU(ctx, sku)
+	if err == nil && existing.ID != selfID {
+		return ErrProductExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check SKU: %w", err)
+	}
+
+	if barcode == nil {
+		return nil
+	}
+	existing, err = s.productRepo.GetByBarcode(ctx, *barcode)
+	if err == nil && existing.ID != selfID {
+		return ErrProductExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check barcode: %w", err)
+	}
+	return nil
+}
+
+// productAuditValues captures the fields tracked in product audit entries
+func productAuditValues(product *models.Product) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       product.Name,
+		"sku":        product.SKU,
+		"barcode":    product.Barcode,
+		"categoryId": product.CategoryID,
+		"price":      product.Price,
+		"cost":       product.Cost,
+		"stock":      product.Stock,
+		"minStock":   product.MinStock,
+		"maxStock":   product.MaxStock,
+		"status":     product.Status,
+		"isActive":   product.IsActive,
+	}
+}
+
+// validProductStatus checks a product status value
+func validProductStatus(status models.ProductStatus) bool {
+	switch status {
+	case models.ProductStatusActive, models.ProductStatusInactive, models.ProductStatusDiscontinued:
+		return true
+	default:
+		return false
+	}
+}
+
+// optionalString trims value and returns nil when it is empty
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
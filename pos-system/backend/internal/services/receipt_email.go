@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/mailer"
+	"github.com/pos-system/backend/pkg/receipt"
+)
+
+var ErrNoCustomerEmail = errors.New("transaction has no customer email")
+
+const (
+	// emailClaimLease keeps a delivery from being picked up again while an
+	// attempt is in flight; it must outlast emailSendTimeout
+	emailClaimLease = 2 * time.Minute
+
+	// emailSendTimeout bounds a single delivery attempt
+	emailSendTimeout = 30 * time.Second
+
+	// emailBatchSize is how many due deliveries are claimed at a time
+	emailBatchSize = 20
+)
+
+// emailRetryDelays are the waits after each failed attempt; the last one
+// repeats until the attempt limit is reached
+var emailRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, time.Hour, 4 * time.Hour}
+
+// newReceiptDelivery queues a receipt email for the background sender
+func newReceiptDelivery(transactionID uuid.UUID, recipient string, requestedBy *uuid.UUID) *models.EmailDelivery {
+	now := time.Now()
+	return &models.EmailDelivery{
+		Kind:          models.EmailKindReceipt,
+		TransactionID: &transactionID,
+		Recipient:     recipient,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: &now,
+		RequestedBy:   requestedBy,
+	}
+}
+
+// EmailReceipt sends the receipt to email, or to the customer email recorded
+// at checkout when email is nil. The first attempt is made straight away;
+// if it fails the delivery is retried in the background and the returned
+// record shows it as pending.
+func (s *ReceiptService) EmailReceipt(ctx context.Context, userID uuid.UUID, r *models.Receipt, email *string) (*models.EmailDelivery, error) {
+	recipient := stringValue(email)
+	if recipient == "" {
+		recipient = stringValue(r.Transaction.CustomerEmail)
+	}
+	if recipient == "" {
+		return nil, ErrNoCustomerEmail
+	}
+
+	delivery := newReceiptDelivery(r.Transaction.ID, recipient, &userID)
+	// Hold the claim so the background sender leaves it alone
+	leaseUntil := time.Now().Add(emailClaimLease)
+	delivery.NextAttemptAt = &leaseUntil
+	if err := s.emailRepo.Create(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to queue receipt email: %w", err)
+	}
+
+	if err := s.attemptDelivery(ctx, delivery, r); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ListReceiptEmails returns the emails sent or queued for a transaction,
+// newest first
+func (s *ReceiptService) ListReceiptEmails(ctx context.Context, transactionID uuid.UUID) ([]models.EmailDelivery, error) {
+	deliveries, err := s.emailRepo.ListByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list receipt emails: %w", err)
+	}
+	return deliveries, nil
+}
+
+// DeliverPendingEmails sends every queued email whose next attempt is due,
+// returning how many were sent and how many failed this round
+func (s *ReceiptService) DeliverPendingEmails(ctx context.Context) (int, int, error) {
+	sent, failed := 0, 0
+	for {
+		deliveries, err := s.emailRepo.ClaimDue(ctx, time.Now(), emailClaimLease, emailBatchSize)
+		if err != nil {
+			return sent, failed, fmt.Errorf("failed to claim emails: %w", err)
+		}
+
+		for i := range deliveries {
+			delivery := &deliveries[i]
+			if err := s.attemptDelivery(ctx, delivery, nil); err != nil {
+				return sent, failed, err
+			}
+			if delivery.Status == models.EmailStatusSent {
+				sent++
+			} else {
+				failed++
+			}
+		}
+
+		if len(deliveries) < emailBatchSize {
+			return sent, failed, nil
+		}
+	}
+}
+
+// attemptDelivery makes one attempt at sending a delivery and records the
+// outcome. r may be passed when the receipt is already loaded. Only a
+// failure to record the outcome is returned as an error.
+func (s *ReceiptService) attemptDelivery(ctx context.Context, delivery *models.EmailDelivery, r *models.Receipt) error {
+	sendErr := s.sendDelivery(ctx, delivery, r)
+
+	now := time.Now()
+	delivery.Attempts++
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.EmailStatusSent
+		delivery.SentAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+	case mailer.IsPermanent(sendErr) || delivery.Attempts >= s.emailMaxAttempts:
+		message := sendErr.Error()
+		delivery.Status = models.EmailStatusFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = &message
+		log.Printf("giving up on email %s to %s after %d attempt(s): %v", delivery.ID, delivery.Recipient, delivery.Attempts, sendErr)
+	default:
+		message := sendErr.Error()
+		next := now.Add(emailRetryDelays[min(delivery.Attempts, len(emailRetryDelays))-1])
+		delivery.NextAttemptAt = &next
+		delivery.LastError = &message
+	}
+
+	if err := s.emailRepo.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to record email delivery: %w", err)
+	}
+	return nil
+}
+
+// sendDelivery renders and sends the message for a delivery
+func (s *ReceiptService) sendDelivery(ctx context.Context, delivery *models.EmailDelivery, r *models.Receipt) error {
+	if delivery.Kind != models.EmailKindReceipt || delivery.TransactionID == nil {
+		return &mailer.PermanentError{Err: fmt.Errorf("unsupported email kind %q", delivery.Kind)}
+	}
+
+	if r == nil {
+		var err error
+		r, err = s.GetReceipt(ctx, *delivery.TransactionID)
+		if errors.Is(err, ErrTransactionNotFound) {
+			return &mailer.PermanentError{Err: err}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	msg, err := s.receiptMessage(r, delivery.Recipient)
+	if err != nil {
+		return &mailer.PermanentError{Err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, msg)
+}
+
+// receiptMessage builds the email for a sale receipt with HTML and plain
+// text versions
+func (s *ReceiptService) receiptMessage(r *models.Receipt, recipient string) (*mailer.Message, error) {
+	html, err := s.RenderReceipt(r, receipt.FormatHTML, receipt.Paper80mm, false)
+	if err != nil {
+		return nil, err
+	}
+	text, err := s.RenderReceipt(r, receipt.FormatText, receipt.Paper80mm, false)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := "Your receipt " + r.Transaction.ReceiptID
+	if r.CompanyInfo.Name != "" {
+		subject += " from " + r.CompanyInfo.Name
+	}
+	return &mailer.Message{
+		To:      []string{recipient},
+		Subject: subject,
+		HTML:    string(html),
+		Text:    string(text),
+	}, nil
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/mailer"
+	"github.com/pos-system/backend/pkg/receipt"
+)
+
+var (
+	ErrInvalidReceiptFormat = errors.New("receipt format must be json, text, html, escpos or pdf")
+	ErrInvalidPaperWidth    = errors.New("paper width must be 58 or 80")
+	ErrNoRefundReceipt      = errors.New("transaction has no refunds")
+)
+
+// paymentMethodLabels are the tender names printed on receipts
+var paymentMethodLabels = map[models.PaymentMethod]string{
+	models.PaymentMethodCash:         "Cash",
+	models.PaymentMethodCard:         "Card",
+	models.PaymentMethodDigital:      "Digital",
+	models.PaymentMethodBankTransfer: "Bank transfer",
+	models.PaymentMethodCredit:       "Credit",
+}
+
+// ReceiptService builds, renders and emails receipts for completed
+// transactions
+type ReceiptService struct {
+	transactionRepo  repository.TransactionRepository
+	systemConfigRepo repository.SystemConfigRepository
+	emailRepo        repository.EmailDeliveryRepository
+	mailer           mailer.Mailer
+	companyName      string
+	currency         string
+	emailMaxAttempts int
+}
+
+// NewReceiptService creates a new receipt service. companyName and currency
+// are used until the system configuration has been saved.
+func NewReceiptService(
+	transactionRepo repository.TransactionRepository,
+	systemConfigRepo repository.SystemConfigRepository,
+	emailRepo repository.EmailDeliveryRepository,
+	mailer mailer.Mailer,
+	companyName string,
+	currency string,
+	emailMaxAttempts int,
+) *ReceiptService {
+	return &ReceiptService{
+		transactionRepo:  transactionRepo,
+		systemConfigRepo: systemConfigRepo,
+		emailRepo:        emailRepo,
+		mailer:           mailer,
+		companyName:      companyName,
+		currency:         currency,
+		emailMaxAttempts: max(emailMaxAttempts, 1),
+	}
+}
+
+// GetReceipt returns the receipt for a transaction with the company details,
+// receipt header and footer from the system configuration
+func (s *ReceiptService) GetReceipt(ctx context.Context, transactionID uuid.UUID) (*models.Receipt, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	result := &models.Receipt{
+		Transaction:   *transaction,
+		CompanyInfo:   models.CompanyInfo{Name: s.companyName},
+		Currency:      s.currency,
+		FormattedDate: transaction.CreatedAt.Format("2006-01-02"),
+		FormattedTime: transaction.CreatedAt.Format("15:04"),
+		QRCode:        &transaction.ReceiptID,
+	}
+
+	config, err := s.systemConfigRepo.Get(ctx)
+	switch {
+	case err == nil:
+		result.CompanyInfo = models.CompanyInfo{
+			Name:    config.CompanyName,
+			Address: config.CompanyAddress,
+			Phone:   config.CompanyPhone,
+			Email:   config.CompanyEmail,
+			Website: config.CompanyWebsite,
+			TaxID:   config.CompanyTaxID,
+		}
+		result.Header = config.ReceiptHeader
+		result.Footer = config.ReceiptFooter
+		result.Currency = config.DefaultCurrency
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to get system config: %w", err)
+	}
+
+	return result, nil
+}
+
+// RenderReceipt renders a receipt for a thermal printer of the given paper
+// width, or as text, HTML or PDF. Refund receipts list what has been returned so
+// far and the tenders it was refunded to.
+func (s *ReceiptService) RenderReceipt(r *models.Receipt, format receipt.Format, paper receipt.Paper, refund bool) ([]byte, error) {
+	if !format.IsValid() {
+		return nil, ErrInvalidReceiptFormat
+	}
+	if !paper.IsValid() {
+		return nil, ErrInvalidPaperWidth
+	}
+
+	var doc *receipt.Receipt
+	if refund {
+		if r.Transaction.GetRefundedAmount() == 0 {
+			return nil, ErrNoRefundReceipt
+		}
+		doc = refundReceipt(r)
+	} else {
+		doc = saleReceipt(r)
+	}
+
+	return receipt.Render(doc, format, paper)
+}
+
+// receiptDocument fills in the parts shared by sale and refund receipts
+func receiptDocument(r *models.Receipt) *receipt.Receipt {
+	t := &r.Transaction
+	return &receipt.Receipt{
+		Company: receipt.Company{
+			Name:    r.CompanyInfo.Name,
+			Address: r.CompanyInfo.Address,
+			Phone:   r.CompanyInfo.Phone,
+			Email:   r.CompanyInfo.Email,
+			Website: stringValue(r.CompanyInfo.Website),
+			TaxID:   stringValue(r.CompanyInfo.TaxID),
+		},
+		Header:    stringValue(r.Header),
+		Footer:    stringValue(r.Footer),
+		ReceiptID: t.ReceiptID,
+		Date:      r.FormattedDate,
+		Time:      r.FormattedTime,
+		Cashier:   t.Cashier.Name,
+		Customer:  stringValue(t.CustomerName),
+		Currency:  r.Currency,
+		QRData:    stringValue(r.QRCode),
+	}
+}
+
+func saleReceipt(r *models.Receipt) *receipt.Receipt {
+	t := &r.Transaction
+	doc := receiptDocument(r)
+
+	for _, item := range t.Items {
+		doc.Items = append(doc.Items, receipt.Item{
+			Name:      item.ProductName,
+			SKU:       item.ProductSKU,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Discount:  item.Discount,
+			Amount:    item.Subtotal,
+		})
+	}
+	doc.Subtotal = t.Subtotal
+	doc.Discount = t.DiscountAmount
+	doc.Tax = t.TaxAmount
+	doc.Total = t.Total
+	doc.AmountPaid = t.AmountPaid
+	doc.Change = t.Change
+
+	for _, payment := range t.Payments {
+		if payment.Amount > 0 {
+			doc.Payments = append(doc.Payments, receiptPayment(payment.Method, payment.Reference, payment.Amount))
+		}
+	}
+	// Sales recorded before split tenders have no payment rows
+	if len(doc.Payments) == 0 {
+		doc.Payments = append(doc.Payments, receiptPayment(t.PaymentMethod, t.PaymentRef, t.AmountPaid-t.Change))
+	}
+
+	return doc
+}
+
+func refundReceipt(r *models.Receipt) *receipt.Receipt {
+	t := &r.Transaction
+	doc := receiptDocument(r)
+	doc.Refund = true
+	doc.RefundReason = stringValue(t.RefundReason)
+	if t.RefundedAt != nil {
+		doc.Date = t.RefundedAt.Format("2006-01-02")
+		doc.Time = t.RefundedAt.Format("15:04")
+	}
+
+	for _, item := range t.Items {
+		if item.RefundedQuantity == 0 {
+			continue
+		}
+		share := float64(item.RefundedQuantity) / float64(item.Quantity)
+		doc.Items = append(doc.Items, receipt.Item{
+			Name:      item.ProductName,
+			SKU:       item.ProductSKU,
+			Quantity:  item.RefundedQuantity,
+			UnitPrice: item.UnitPrice,
+			Discount:  roundMoney(item.Discount * share),
+			Amount:    roundMoney(item.Subtotal * share),
+		})
+	}
+	doc.Total = roundMoney(t.GetRefundedAmount())
+
+	for _, payment := range t.Payments {
+		if payment.Amount < 0 {
+			doc.Payments = append(doc.Payments, receiptPayment(payment.Method, payment.Reference, -payment.Amount))
+		}
+	}
+
+	return doc
+}
+
+func receiptPayment(method models.PaymentMethod, reference *string, amount float64) receipt.Payment {
+	label, ok := paymentMethodLabels[method]
+	if !ok {
+		label = string(method)
+	}
+	return receipt.Payment{
+		Method:    label,
+		Reference: stringValue(reference),
+		Amount:    roundMoney(amount),
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrRecommendationNotFound      = errors.New("stock recommendation not found")
+	ErrInvalidRecommendationAction = errors.New("action is not allowed for the recommendation's current status")
+)
+
+// RecommendationService generates reorder recommendations from recent sales
+// and records the actions managers take on them
+type RecommendationService struct {
+	recommendationRepo repository.StockRecommendationRepository
+	productRepo        repository.ProductRepository
+	transactionRepo    repository.TransactionRepository
+	systemConfigRepo   repository.SystemConfigRepository
+	db                 *gorm.DB
+	windowDays         int
+	leadTimeDays       int
+}
+
+// GenerationResult summarises one recommendation run
+type GenerationResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Expired int `json:"expired"`
+}
+
+// NewRecommendationService creates a new recommendation service. Sales
+// velocity is averaged over the last windowDays, and products projected to
+// run out within leadTimeDays are recommended for reorder.
+func NewRecommendationService(
+	recommendationRepo repository.StockRecommendationRepository,
+	productRepo repository.ProductRepository,
+	transactionRepo repository.TransactionRepository,
+	systemConfigRepo repository.SystemConfigRepository,
+	db *gorm.DB,
+	windowDays int,
+	leadTimeDays int,
+) *RecommendationService {
+	if windowDays <= 0 {
+		windowDays = 30
+	}
+	if leadTimeDays < 0 {
+		leadTimeDays = 0
+	}
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		productRepo:        productRepo,
+		transactionRepo:    transactionRepo,
+		systemConfigRepo:   systemConfigRepo,
+		db:                 db,
+		windowDays:         windowDays,
+		leadTimeDays:       leadTimeDays,
+	}
+}
+
+// ListRecommendations returns a page of recommendations
+func (s *RecommendationService) ListRecommendations(ctx context.Context, filters map[string]interface{}, pagination *models.PaginationQuery) ([]models.StockRecommendation, int64, error) {
+	recommendations, total, err := s.recommendationRepo.List(ctx, filters, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list recommendations: %w", err)
+	}
+	return recommendations, total, nil
+}
+
+// GetPendingRecommendations returns pending recommendations, most urgent first
+func (s *RecommendationService) GetPendingRecommendations(ctx context.Context) ([]models.StockRecommendation, error) {
+	recommendations, err := s.recommendationRepo.GetPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending recommendations: %w", err)
+	}
+	return recommendations, nil
+}
+
+// GetRecommendation retrieves a recommendation by ID
+func (s *RecommendationService) GetRecommendation(ctx context.Context, id uuid.UUID) (*models.StockRecommendation, error) {
+	recommendation, err := s.recommendationRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecommendationNotFound
+		}
+		return nil, fmt.Errorf("failed to get recommendation: %w", err)
+	}
+	return recommendation, nil
+}
+
+// TakeAction accepts, rejects or processes a recommendation
+func (s *RecommendationService) TakeAction(ctx context.Context, userID, id uuid.UUID, req *models.ActionRecommendationRequest) (*models.StockRecommendation, error) {
+	if err := s.recommendationRepo.TakeAction(ctx, id, req.Action, req.Notes, userID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrRecommendationNotFound
+		case errors.Is(err, repository.ErrInvalidAction):
+			return nil, ErrInvalidRecommendationAction
+		}
+		return nil, fmt.Errorf("failed to update recommendation: %w", err)
+	}
+	return s.GetRecommendation(ctx, id)
+}
+
+// AutoGenerateEnabled reports whether the system configuration allows the
+// background job to generate recommendations. It defaults to true when no
+// configuration has been saved yet.
+func (s *RecommendationService) AutoGenerateEnabled(ctx context.Context) (bool, error) {
+	config, err := s.systemConfigRepo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get system config: %w", err)
+	}
+	return config.AutoGenerateRecommendations, nil
+}
+
+// GenerateRecommendations recomputes reorder recommendations for every active
+// product. A product that already has a pending recommendation gets it
+// refreshed with current figures instead of a duplicate, and one with an
+// accepted recommendation gets nothing new while that reorder is on its way.
+// Pending recommendations that are no longer needed are expired.
+func (s *RecommendationService) GenerateRecommendations(ctx context.Context) (*GenerationResult, error) {
+	now := time.Now()
+	sold, err := s.transactionRepo.GetUnitsSold(ctx, now.AddDate(0, 0, -s.windowDays), now)
+	if err != nil {
+		return nil, err
+	}
+	unitsSold := make(map[uuid.UUID]int, len(sold))
+	for _, row := range sold {
+		unitsSold[row.ProductID] = row.TotalQuantity
+	}
+
+	result := &GenerationResult{}
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		products, err := s.productRepo.GetActive(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get products: %w", err)
+		}
+
+		open, err := s.recommendationRepo.GetOpen(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get open recommendations: %w", err)
+		}
+		pending := make(map[uuid.UUID]*models.StockRecommendation, len(open))
+		accepted := make(map[uuid.UUID]bool)
+		for i := range open {
+			switch open[i].Status {
+			case models.RecommendationStatusPending:
+				pending[open[i].ProductID] = &open[i]
+			case models.RecommendationStatusAccepted:
+				accepted[open[i].ProductID] = true
+			}
+		}
+
+		for i := range products {
+			recommendation := s.recommend(&products[i], unitsSold[products[i].ID])
+			current, isPending := pending[products[i].ID]
+			delete(pending, products[i].ID)
+
+			switch {
+			case recommendation == nil || accepted[products[i].ID]:
+				// Nothing to reorder, or a reorder is already on its way
+				if isPending {
+					if err := s.expire(ctx, current); err != nil {
+						return err
+					}
+					result.Expired++
+				}
+			case isPending:
+				recommendation.ID = current.ID
+				recommendation.CreatedAt = current.CreatedAt
+				if err := s.recommendationRepo.Update(ctx, recommendation); err != nil {
+					return fmt.Errorf("failed to update recommendation: %w", err)
+				}
+				result.Updated++
+			default:
+				if err := s.recommendationRepo.Create(ctx, recommendation); err != nil {
+					return fmt.Errorf("failed to create recommendation: %w", err)
+				}
+				result.Created++
+			}
+		}
+
+		// Whatever is left belongs to products that are no longer active
+		for _, current := range pending {
+			if err := s.expire(ctx, current); err != nil {
+				return err
+			}
+			result.Expired++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// expire closes a pending recommendation nobody acted on
+func (s *RecommendationService) expire(ctx context.Context, recommendation *models.StockRecommendation) error {
+	recommendation.Status = models.RecommendationStatusExpired
+	if err := s.recommendationRepo.Update(ctx, recommendation); err != nil {
+		return fmt.Errorf("failed to expire recommendation: %w", err)
+	}
+	return nil
+}
+
+// recommend builds a pending recommendation for product, or returns nil
+// when it does not need reordering. The reorder brings stock back up to
+// MaxStock.
+func (s *RecommendationService) recommend(product *models.Product, unitsSold int) *models.StockRecommendation {
+	velocity := float64(unitsSold) / float64(s.windowDays)
+
+	var daysUntilStockout *int
+	if product.Stock <= 0 {
+		days := 0
+		daysUntilStockout = &days
+	} else if velocity > 0 {
+		days := int(math.Floor(float64(product.Stock) / velocity))
+		daysUntilStockout = &days
+	}
+
+	belowMinimum := product.Stock <= product.MinStock
+	runningOut := daysUntilStockout != nil && *daysUntilStockout <= s.leadTimeDays
+	if !belowMinimum && !runningOut {
+		return nil
+	}
+
+	quantity := product.MaxStock - product.Stock
+	if quantity <= 0 {
+		return nil
+	}
+
+	recommendation := &models.StockRecommendation{
+		ProductID:           product.ID,
+		ProductName:         product.Name,
+		ProductSKU:          product.SKU,
+		CurrentStock:        product.Stock,
+		MinStock:            product.MinStock,
+		RecommendedQuantity: quantity,
+		EstimatedCost:       roundMoney(float64(quantity) * product.Cost),
+		SalesVelocity:       math.Round(velocity*100) / 100,
+		DaysUntilStockout:   daysUntilStockout,
+		Status:              models.RecommendationStatusPending,
+	}
+
+	switch {
+	case product.Stock <= 0 || recommendation.IsUrgent():
+		recommendation.Priority = models.RecommendationPriorityUrgent
+	case runningOut:
+		recommendation.Priority = models.RecommendationPriorityHigh
+	case velocity > 0:
+		recommendation.Priority = models.RecommendationPriorityMedium
+	default:
+		recommendation.Priority = models.RecommendationPriorityLow
+	}
+
+	switch {
+	case product.Stock <= 0:
+		recommendation.Reason = "Out of stock"
+	case runningOut:
+		recommendation.Reason = fmt.Sprintf("Selling %.2f/day, projected to run out in %d day(s)", velocity, *daysUntilStockout)
+	default:
+		recommendation.Reason = fmt.Sprintf("Stock %d is at or below the minimum of %d", product.Stock, product.MinStock)
+	}
+
+	return recommendation
+}
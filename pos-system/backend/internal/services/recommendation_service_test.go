@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+func TestRecommend(t *testing.T) {
+	// Velocity is averaged over 30 days and anything running out within a
+	// week is reordered
+	service := NewRecommendationService(nil, nil, nil, nil, nil, 30, 7)
+
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name         string
+		stock        int
+		unitsSold    int
+		wantNil      bool
+		wantVelocity float64
+		wantDays     *int // nil when the product is not selling
+		wantQuantity int
+		wantPriority models.StockRecommendationPriority
+	}{
+		{
+			name: "plenty of stock", stock: 40, unitsSold: 30,
+			wantNil: true,
+		},
+		{
+			name: "runs out exactly at the lead time", stock: 7, unitsSold: 30,
+			wantVelocity: 1, wantDays: days(7), wantQuantity: 43, wantPriority: models.RecommendationPriorityHigh,
+		},
+		{
+			name: "one day past the lead time", stock: 8, unitsSold: 30,
+			wantNil: true,
+		},
+		{
+			name: "partial days round down", stock: 10, unitsSold: 45,
+			wantVelocity: 1.5, wantDays: days(6), wantQuantity: 40, wantPriority: models.RecommendationPriorityHigh,
+		},
+		{
+			name: "three days left is urgent", stock: 7, unitsSold: 60,
+			wantVelocity: 2, wantDays: days(3), wantQuantity: 43, wantPriority: models.RecommendationPriorityUrgent,
+		},
+		{
+			name: "out of stock", stock: 0, unitsSold: 0,
+			wantDays: days(0), wantQuantity: 50, wantPriority: models.RecommendationPriorityUrgent,
+		},
+		{
+			name: "at the minimum without sales", stock: 5, unitsSold: 0,
+			wantQuantity: 45, wantPriority: models.RecommendationPriorityLow,
+		},
+		{
+			name: "at the minimum with slow sales", stock: 5, unitsSold: 10,
+			wantVelocity: 0.33, wantDays: days(15), wantQuantity: 45, wantPriority: models.RecommendationPriorityMedium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := newTestProduct("tea", 4.00, 1.50, tt.stock)
+			product.MinStock = 5
+			product.MaxStock = 50
+
+			got := service.recommend(product, tt.unitsSold)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("Expected no recommendation, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("Expected a recommendation, got none")
+			}
+			if got.SalesVelocity != tt.wantVelocity {
+				t.Errorf("Expected velocity %.2f, got %.2f", tt.wantVelocity, got.SalesVelocity)
+			}
+			switch {
+			case tt.wantDays == nil && got.DaysUntilStockout != nil:
+				t.Errorf("Expected no stockout estimate, got %d days", *got.DaysUntilStockout)
+			case tt.wantDays != nil && (got.DaysUntilStockout == nil || *got.DaysUntilStockout != *tt.wantDays):
+				t.Errorf("Expected %d days until stockout, got %v", *tt.wantDays, got.DaysUntilStockout)
+			}
+			if got.RecommendedQuantity != tt.wantQuantity {
+				t.Errorf("Expected a reorder of %d, got %d", tt.wantQuantity, got.RecommendedQuantity)
+			}
+			if want := roundMoney(float64(tt.wantQuantity) * 1.50); got.EstimatedCost != want {
+				t.Errorf("Expected an estimated cost of %.2f, got %.2f", want, got.EstimatedCost)
+			}
+			if got.Priority != tt.wantPriority {
+				t.Errorf("Expected priority %s, got %s", tt.wantPriority, got.Priority)
+			}
+		})
+	}
+}
+
+func TestRecommendNothingToReorder(t *testing.T) {
+	service := NewRecommendationService(nil, nil, nil, nil, nil, 30, 7)
+	product := newTestProduct("tea", 4.00, 1.50, 5)
+	product.MinStock = 5
+	product.MaxStock = 5
+
+	if got := service.recommend(product, 60); got != nil {
+		t.Errorf("Expected no recommendation when stock is already at the maximum, got %+v", got)
+	}
+}
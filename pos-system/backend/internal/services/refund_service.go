@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrTransactionNotRefundable = errors.New("transaction cannot be refunded")
+	ErrRefundItemsRequired      = errors.New("partial refund requires items")
+	ErrRefundItemNotFound       = errors.New("item was not part of the transaction")
+	ErrRefundQuantityExceeded   = errors.New("refund quantity exceeds quantity remaining")
+)
+
+// RefundQuantityError reports a refund line asking for more units than are
+// left to refund
+type RefundQuantityError struct {
+	ProductID   uuid.UUID
+	ProductName string
+	Refundable  int
+	Requested   int
+}
+
+func (e *RefundQuantityError) Error() string {
+	return fmt.Sprintf("cannot refund %d of %s: %d refundable", e.Requested, e.ProductName, e.Refundable)
+}
+
+func (e *RefundQuantityError) Unwrap() error {
+	return ErrRefundQuantityExceeded
+}
+
+// Details returns the fields reported to API clients
+func (e *RefundQuantityError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"productId":   e.ProductID,
+		"productName": e.ProductName,
+		"refundable":  e.Refundable,
+		"requested":   e.Requested,
+	}
+}
+
+// Refund returns items from a completed transaction. Without items every
+// remaining unit is refunded; with items only those quantities are, and a
+// transaction can be partially refunded any number of times until nothing is
+// left. Refunded units are restocked and the money is returned as negative
+// payments spread over the original tenders.
+func (s *TransactionService) Refund(ctx context.Context, transactionID, userID uuid.UUID, req *models.RefundTransactionRequest) (*models.RefundResult, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var refundAmount float64
+	var refundPayments []models.Payment
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		transaction, err := s.transactionRepo.GetByIDForUpdate(ctx, transactionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransactionNotFound
+			}
+			return fmt.Errorf("failed to lock transaction: %w", err)
+		}
+		if !transaction.IsRefundable() {
+			return ErrTransactionNotRefundable
+		}
+
+		quantities, err := refundQuantities(transaction, req)
+		if err != nil {
+			return err
+		}
+		refundAmount = refundValue(transaction, quantities)
+
+		fullyRefunded := true
+		for i := range transaction.Items {
+			item := &transaction.Items[i]
+			quantity := quantities[item.ID]
+			if quantity == 0 {
+				if item.GetRefundableQuantity() > 0 {
+					fullyRefunded = false
+				}
+				continue
+			}
+
+			item.RefundedQuantity += quantity
+			if item.GetRefundableQuantity() > 0 {
+				fullyRefunded = false
+			}
+			if err := s.transactionRepo.UpdateItem(ctx, item); err != nil {
+				return fmt.Errorf("failed to update transaction item: %w", err)
+			}
+			if err := s.restock(ctx, item.ProductID, quantity, transaction.ReceiptID, req.Reason, userID); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		refundPayments = allocateRefund(transaction, refundAmount)
+		for i := range refundPayments {
+			refundPayments[i].ProcessedAt = &now
+			if err := s.transactionRepo.CreatePayment(ctx, &refundPayments[i]); err != nil {
+				return fmt.Errorf("failed to record refund payment: %w", err)
+			}
+		}
+
+		transaction.RefundedAt = &now
+		transaction.RefundedBy = &userID
+		transaction.RefundReason = &req.Reason
+		if fullyRefunded {
+			transaction.Status = models.TransactionStatusRefunded
+		}
+		if err := s.transactionRepo.Update(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+
+		refundedItems := make(map[string]interface{}, len(quantities))
+		for _, item := range transaction.Items {
+			if quantity := quantities[item.ID]; quantity > 0 {
+				refundedItems[item.ProductID.String()] = quantity
+			}
+		}
+		auditLog := newAuditLog(ctx, user, models.AuditActionRefundTransaction, "transaction", transaction.ID.String(), nil,
+			map[string]interface{}{
+				"receiptId":    transaction.ReceiptID,
+				"reason":       req.Reason,
+				"refundAmount": refundAmount,
+				"items":        refundedItems,
+				"status":       transaction.Status,
+			})
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RefundResult{
+		Transaction:      transaction,
+		RefundAmount:     refundAmount,
+		RemainingBalance: roundMoney(transaction.GetRefundableBalance()),
+		Payments:         refundPayments,
+	}, nil
+}
+
+// restock puts refunded units back on the shelf. Products deleted since the
+// sale are skipped.
+func (s *TransactionService) restock(ctx context.Context, productID uuid.UUID, quantity int, receiptID, reason string, userID uuid.UUID) error {
+	product, err := s.productRepo.GetByIDForUpdate(ctx, productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	product.Stock += quantity
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		return fmt.Errorf("failed to update stock: %w", err)
+	}
+
+	movement := &models.StockMovement{
+		ProductID:   productID,
+		Type:        models.StockMovementIn,
+		Quantity:    quantity,
+		Reason:      "refund",
+		Reference:   receiptID,
+		Notes:       reason,
+		PerformedBy: userID,
+	}
+	if err := s.stockMovementRepo.Create(ctx, movement); err != nil {
+		return fmt.Errorf("failed to record stock movement: %w", err)
+	}
+	return nil
+}
+
+// refundQuantities resolves the request into a quantity per transaction item
+func refundQuantities(transaction *models.Transaction, req *models.RefundTransactionRequest) (map[uuid.UUID]int, error) {
+	quantities := make(map[uuid.UUID]int, len(transaction.Items))
+
+	if len(req.Items) == 0 {
+		if req.PartialRefund {
+			return nil, ErrRefundItemsRequired
+		}
+		for _, item := range transaction.Items {
+			if remaining := item.GetRefundableQuantity(); remaining > 0 {
+				quantities[item.ID] = remaining
+			}
+		}
+		if len(quantities) == 0 {
+			return nil, ErrTransactionNotRefundable
+		}
+		return quantities, nil
+	}
+
+	byProduct := make(map[uuid.UUID]*models.TransactionItem, len(transaction.Items))
+	for i := range transaction.Items {
+		byProduct[transaction.Items[i].ProductID] = &transaction.Items[i]
+	}
+
+	for _, line := range req.Items {
+		item, ok := byProduct[line.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRefundItemNotFound, line.ProductID)
+		}
+		quantities[item.ID] += line.Quantity
+		if quantities[item.ID] > item.GetRefundableQuantity() {
+			return nil, &RefundQuantityError{
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				Refundable:  item.GetRefundableQuantity(),
+				Requested:   quantities[item.ID],
+			}
+		}
+	}
+
+	return quantities, nil
+}
+
+// refundValue prices the refunded units at what the customer actually paid
+// for them: the line's net price scaled by the order-level discount and tax.
+// A refund that returns everything left gets the exact remaining balance so
+// rounding never leaves cents behind.
+func refundValue(transaction *models.Transaction, quantities map[uuid.UUID]int) float64 {
+	balance := roundMoney(transaction.GetRefundableBalance())
+
+	itemsNet := 0.0
+	returnsAll := true
+	for _, item := range transaction.Items {
+		itemsNet += item.GetTotalWithDiscount()
+		if quantities[item.ID] != item.GetRefundableQuantity() {
+			returnsAll = false
+		}
+	}
+	if returnsAll || itemsNet <= 0 {
+		return balance
+	}
+
+	ratio := transaction.Total / itemsNet
+	amount := 0.0
+	for _, item := range transaction.Items {
+		quantity := quantities[item.ID]
+		if quantity == 0 {
+			continue
+		}
+		unitNet := item.GetTotalWithDiscount() / float64(item.Quantity)
+		amount += unitNet * float64(quantity) * ratio
+	}
+
+	return math.Min(roundMoney(amount), balance)
+}
+
+// allocateRefund spreads a refund over the original tenders in proportion to
+// what each still has unrefunded, returning one negative payment per tender
+func allocateRefund(transaction *models.Transaction, amount float64) []models.Payment {
+	type tender struct {
+		method    models.PaymentMethod
+		reference *string
+		remaining float64
+	}
+
+	key := func(method models.PaymentMethod, reference *string) string {
+		if reference == nil {
+			return string(method)
+		}
+		return string(method) + "|" + *reference
+	}
+
+	var tenders []*tender
+	byKey := make(map[string]*tender)
+	for _, payment := range transaction.Payments {
+		if payment.Amount <= 0 {
+			continue
+		}
+		k := key(payment.Method, payment.Reference)
+		if t, ok := byKey[k]; ok {
+			t.remaining += payment.Amount
+			continue
+		}
+		t := &tender{method: payment.Method, reference: payment.Reference, remaining: payment.Amount}
+		byKey[k] = t
+		tenders = append(tenders, t)
+	}
+	for _, payment := range transaction.Payments {
+		if payment.Amount >= 0 {
+			continue
+		}
+		if t, ok := byKey[key(payment.Method, payment.Reference)]; ok {
+			t.remaining += payment.Amount
+		}
+	}
+
+	// Sales recorded before split tenders have no payment rows
+	if len(tenders) == 0 {
+		tenders = append(tenders, &tender{
+			method:    transaction.PaymentMethod,
+			reference: transaction.PaymentRef,
+			remaining: transaction.GetRefundableBalance(),
+		})
+	}
+
+	totalRemaining := 0.0
+	for _, t := range tenders {
+		totalRemaining += math.Max(t.remaining, 0)
+	}
+
+	var payments []models.Payment
+	left := roundMoney(amount)
+	for i, t := range tenders {
+		if left <= 0 {
+			break
+		}
+		share := left
+		if i < len(tenders)-1 && totalRemaining > 0 {
+			share = roundMoney(amount * math.Max(t.remaining, 0) / totalRemaining)
+		}
+		share = math.Min(share, left)
+		if share <= 0 {
+			continue
+		}
+		left = roundMoney(left - share)
+		payments = append(payments, models.Payment{
+			TransactionID: transaction.ID,
+			Amount:        -share,
+			Method:        t.method,
+			Reference:     t.reference,
+			Status:        models.PaymentStatusRefunded,
+		})
+	}
+
+	return payments
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+// newRefundFixture records a completed sale of 3 x 10.00 and 1 x 5.00 with
+// 10% tax, a total of 38.50 paid 20.00 by card and 18.50 in cash
+func newRefundFixture(t *testing.T) (*checkoutFixture, *models.Transaction, *models.Product, *models.Product) {
+	t.Helper()
+	shirt := newTestProduct("shirt", 10.00, 4.00, 7)
+	socks := newTestProduct("socks", 5.00, 1.00, 9)
+	f := newCheckoutFixture(shirt, socks)
+
+	_, err := f.service.Checkout(txContext(), f.cashier.ID, &models.CreateTransactionRequest{
+		Items: []models.CreateTransactionItem{
+			{ProductID: shirt.ID, Quantity: 3},
+			{ProductID: socks.ID, Quantity: 1},
+		},
+		Payments: []models.CreateTransactionPayment{
+			{Method: models.PaymentMethodCard, Amount: 20.00},
+			{Method: models.PaymentMethodCash, Amount: 18.50},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	var transaction *models.Transaction
+	for _, stored := range f.transactions.transactions {
+		transaction = stored
+	}
+	if transaction.Total != 38.50 {
+		t.Fatalf("Expected a total of 38.50, got %.2f", transaction.Total)
+	}
+	return f, transaction, shirt, socks
+}
+
+// refundedBy totals the refund payments by method
+func refundedBy(payments []models.Payment) map[models.PaymentMethod]float64 {
+	byMethod := make(map[models.PaymentMethod]float64)
+	for _, payment := range payments {
+		if payment.Amount >= 0 {
+			continue
+		}
+		byMethod[payment.Method] = roundMoney(byMethod[payment.Method] - payment.Amount)
+	}
+	return byMethod
+}
+
+func TestPartialRefunds(t *testing.T) {
+	f, transaction, shirt, socks := newRefundFixture(t)
+
+	// One shirt: 10.00 scaled by tax is 11.00, split over card and cash by
+	// what each paid
+	result, err := f.service.Refund(txContext(), transaction.ID, f.cashier.ID, &models.RefundTransactionRequest{
+		Reason:        "wrong size",
+		PartialRefund: true,
+		Items:         []models.RefundTransactionItem{{ProductID: shirt.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Partial refund failed: %v", err)
+	}
+	if result.RefundAmount != 11.00 || result.RemainingBalance != 27.50 {
+		t.Errorf("Expected 11.00 refunded with 27.50 left, got %.2f and %.2f", result.RefundAmount, result.RemainingBalance)
+	}
+	refunded := refundedBy(result.Payments)
+	if refunded[models.PaymentMethodCard] != 5.71 || refunded[models.PaymentMethodCash] != 5.29 {
+		t.Errorf("Expected 5.71 back to card and 5.29 in cash, got %v", refunded)
+	}
+	if result.Transaction.Status != models.TransactionStatusCompleted {
+		t.Errorf("Expected a partly refunded sale to stay completed, got %s", result.Transaction.Status)
+	}
+	if stock := f.products.products[shirt.ID].Stock; stock != 5 {
+		t.Errorf("Expected the shirt restocked to 5, got %d", stock)
+	}
+
+	// Refunding the rest returns exactly what is left, so no cents are lost
+	result, err = f.service.Refund(txContext(), transaction.ID, f.cashier.ID, &models.RefundTransactionRequest{Reason: "changed mind"})
+	if err != nil {
+		t.Fatalf("Final refund failed: %v", err)
+	}
+	if result.RefundAmount != 27.50 || result.RemainingBalance != 0 {
+		t.Errorf("Expected the remaining 27.50 refunded, got %.2f with %.2f left", result.RefundAmount, result.RemainingBalance)
+	}
+	if result.Transaction.Status != models.TransactionStatusRefunded {
+		t.Errorf("Expected the sale to be refunded, got %s", result.Transaction.Status)
+	}
+	refunded = refundedBy(result.Transaction.Payments)
+	if refunded[models.PaymentMethodCard] != 20.00 || refunded[models.PaymentMethodCash] != 18.50 {
+		t.Errorf("Expected each tender refunded in full, got %v", refunded)
+	}
+	if stock := f.products.products[shirt.ID].Stock; stock != 7 {
+		t.Errorf("Expected shirt stock back at 7, got %d", stock)
+	}
+	if stock := f.products.products[socks.ID].Stock; stock != 9 {
+		t.Errorf("Expected socks stock back at 9, got %d", stock)
+	}
+
+	_, err = f.service.Refund(txContext(), transaction.ID, f.cashier.ID, &models.RefundTransactionRequest{Reason: "again"})
+	if !errors.Is(err, ErrTransactionNotRefundable) {
+		t.Errorf("Expected ErrTransactionNotRefundable once fully refunded, got %v", err)
+	}
+}
+
+func TestRefundRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		first   []models.RefundTransactionItem // refunded before the request under test
+		items   func(shirt, socks uuid.UUID) []models.RefundTransactionItem
+		wantErr error
+	}{
+		{
+			name: "more than were sold",
+			items: func(shirt, socks uuid.UUID) []models.RefundTransactionItem {
+				return []models.RefundTransactionItem{{ProductID: shirt, Quantity: 4}}
+			},
+			wantErr: ErrRefundQuantityExceeded,
+		},
+		{
+			name: "more than are left after an earlier refund",
+			first: []models.RefundTransactionItem{
+				{Quantity: 2},
+			},
+			items: func(shirt, socks uuid.UUID) []models.RefundTransactionItem {
+				return []models.RefundTransactionItem{{ProductID: shirt, Quantity: 2}}
+			},
+			wantErr: ErrRefundQuantityExceeded,
+		},
+		{
+			name: "split lines adding up to too many",
+			items: func(shirt, socks uuid.UUID) []models.RefundTransactionItem {
+				return []models.RefundTransactionItem{{ProductID: socks, Quantity: 1}, {ProductID: socks, Quantity: 1}}
+			},
+			wantErr: ErrRefundQuantityExceeded,
+		},
+		{
+			name: "product not in the sale",
+			items: func(shirt, socks uuid.UUID) []models.RefundTransactionItem {
+				return []models.RefundTransactionItem{{ProductID: uuid.New(), Quantity: 1}}
+			},
+			wantErr: ErrRefundItemNotFound,
+		},
+		{
+			name:    "partial refund without items",
+			items:   func(shirt, socks uuid.UUID) []models.RefundTransactionItem { return nil },
+			wantErr: ErrRefundItemsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, transaction, shirt, socks := newRefundFixture(t)
+			for _, line := range tt.first {
+				line.ProductID = shirt.ID
+				if _, err := f.service.Refund(txContext(), transaction.ID, f.cashier.ID, &models.RefundTransactionRequest{
+					Reason: "first", PartialRefund: true, Items: []models.RefundTransactionItem{line},
+				}); err != nil {
+					t.Fatalf("Earlier refund failed: %v", err)
+				}
+			}
+			paymentsBefore := len(transaction.Payments)
+			stockBefore := f.products.products[shirt.ID].Stock
+
+			_, err := f.service.Refund(txContext(), transaction.ID, f.cashier.ID, &models.RefundTransactionRequest{
+				Reason:        "test",
+				PartialRefund: true,
+				Items:         tt.items(shirt.ID, socks.ID),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if len(transaction.Payments) != paymentsBefore {
+				t.Error("Expected no refund payments for a rejected refund")
+			}
+			if stock := f.products.products[shirt.ID].Stock; stock != stockBefore {
+				t.Errorf("Expected stock left at %d, got %d", stockBefore, stock)
+			}
+		})
+	}
+}
+
+func TestRefundValueRounding(t *testing.T) {
+	itemID := uuid.New()
+	// Three units sold for 10.00 in all: a third does not divide into cents
+	transaction := &models.Transaction{
+		Total: 10.00,
+		Items: []models.TransactionItem{{ID: itemID, Quantity: 3, UnitPrice: 3.3333, Subtotal: 10.00}},
+	}
+
+	tests := []struct {
+		name     string
+		refunded int // units refunded before
+		paid     float64
+		quantity int
+		want     float64
+	}{
+		{name: "one of three", quantity: 1, want: 3.33},
+		{name: "two of three", quantity: 2, want: 6.67},
+		{name: "the last two after one", refunded: 1, paid: 3.33, quantity: 2, want: 6.67},
+		{name: "the last one after two", refunded: 2, paid: 6.67, quantity: 1, want: 3.33},
+		{name: "all three", quantity: 3, want: 10.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sale := *transaction
+			sale.Items = []models.TransactionItem{transaction.Items[0]}
+			sale.Items[0].RefundedQuantity = tt.refunded
+			if tt.paid > 0 {
+				sale.Payments = []models.Payment{{Amount: -tt.paid}}
+			}
+
+			got := refundValue(&sale, map[uuid.UUID]int{itemID: tt.quantity})
+			if got != tt.want {
+				t.Errorf("Expected %.2f, got %.2f", tt.want, got)
+			}
+			if tt.refunded+tt.quantity == 3 && roundMoney(tt.paid+got) != transaction.Total {
+				t.Errorf("Expected refunds to add up to the total, got %.2f", tt.paid+got)
+			}
+		})
+	}
+}
+
+func TestAllocateRefundRounding(t *testing.T) {
+	cash := models.PaymentMethodCash
+	card := models.PaymentMethodCard
+	ref := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		payments []models.Payment
+		amount   float64
+		want     []float64 // refunded per tender, in payment order
+	}{
+		{
+			name: "a third each across three cards",
+			payments: []models.Payment{
+				{Method: card, Reference: ref("a"), Amount: 10},
+				{Method: card, Reference: ref("b"), Amount: 10},
+				{Method: card, Reference: ref("c"), Amount: 10},
+			},
+			amount: 10.00,
+			want:   []float64{3.33, 3.33, 3.34},
+		},
+		{
+			name:     "in proportion to what each paid",
+			payments: []models.Payment{{Method: card, Amount: 20}, {Method: cash, Amount: 18.50}},
+			amount:   11.00,
+			want:     []float64{5.71, 5.29},
+		},
+		{
+			name: "what each has left after an earlier refund",
+			payments: []models.Payment{
+				{Method: card, Amount: 20},
+				{Method: cash, Amount: 20},
+				{Method: card, Amount: -20},
+			},
+			amount: 5.00,
+			want:   []float64{0, 5.00},
+		},
+		{
+			name:     "one cent",
+			payments: []models.Payment{{Method: card, Amount: 10}, {Method: cash, Amount: 10}},
+			amount:   0.01,
+			want:     []float64{0.01, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{ID: uuid.New(), Payments: tt.payments}
+			refunds := allocateRefund(transaction, tt.amount)
+
+			got := make([]float64, len(tt.want))
+			sum := 0.0
+			for _, refund := range refunds {
+				if refund.Amount >= 0 || refund.Status != models.PaymentStatusRefunded {
+					t.Errorf("Expected a negative refunded payment, got %+v", refund)
+				}
+				for i, payment := range tt.payments {
+					if payment.Amount > 0 && payment.Method == refund.Method && samePaymentRef(payment.Reference, refund.Reference) {
+						got[i] = roundMoney(got[i] - refund.Amount)
+						break
+					}
+				}
+				sum -= refund.Amount
+			}
+			if roundMoney(sum) != tt.amount {
+				t.Errorf("Expected refunds to sum to %.2f, got %.2f", tt.amount, sum)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v refunded per tender, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func samePaymentRef(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
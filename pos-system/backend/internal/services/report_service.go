@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrInvalidDateRange = errors.New("start date must not be after end date")
+	ErrInvalidGroupBy   = errors.New("group by must be day, week or month")
+)
+
+// ReportService produces sales reports over arbitrary periods
+type ReportService struct {
+	transactionRepo repository.TransactionRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(transactionRepo repository.TransactionRepository) *ReportService {
+	return &ReportService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+// GetSalesReport returns the sales report for the two dates with its series
+// grouped by groupBy, defaulting to days. Figures are net of refunds and the
+// gross margin is worked out from current product costs.
+func (s *ReportService) GetSalesReport(ctx context.Context, startDate, endDate time.Time, groupBy models.ReportGroupBy) (*models.SalesReport, error) {
+	if startDate.After(endDate) {
+		return nil, ErrInvalidDateRange
+	}
+	if groupBy == "" {
+		groupBy = models.ReportGroupByDay
+	}
+	if !groupBy.IsValid() {
+		return nil, ErrInvalidGroupBy
+	}
+
+	report, err := s.transactionRepo.GetSalesReport(ctx, startDate, endDate, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales report: %w", err)
+	}
+
+	roundSalesReport(report)
+	return report, nil
+}
+
+// roundSalesReport rounds every amount in the report to cents. Margins are
+// percentages and get the same two decimal places.
+func roundSalesReport(report *models.SalesReport) {
+	for _, amount := range []*float64{
+		&report.GrossSales, &report.TotalRefunds, &report.TotalSales, &report.TotalTax,
+		&report.TotalDiscount, &report.NetRevenue, &report.TotalCost, &report.GrossProfit,
+		&report.GrossMargin, &report.AverageOrder,
+	} {
+		*amount = roundMoney(*amount)
+	}
+	for i := range report.Series {
+		period := &report.Series[i]
+		for _, amount := range []*float64{
+			&period.GrossSales, &period.Refunds, &period.TotalSales, &period.TotalTax,
+			&period.TotalDiscount, &period.NetRevenue, &period.TotalCost, &period.GrossProfit,
+			&period.GrossMargin,
+		} {
+			*amount = roundMoney(*amount)
+		}
+		roundAmounts(period.PaymentMethods)
+	}
+	for i := range report.TopProducts {
+		product := &report.TopProducts[i]
+		product.TotalRevenue = roundMoney(product.TotalRevenue)
+		product.TotalCost = roundMoney(product.TotalCost)
+		product.GrossProfit = roundMoney(product.GrossProfit)
+	}
+	for i := range report.CategoryBreakdown {
+		report.CategoryBreakdown[i].Value = roundMoney(report.CategoryBreakdown[i].Value)
+	}
+	for i := range report.CashierPerformance {
+		cashier := &report.CashierPerformance[i]
+		cashier.TotalSales = roundMoney(cashier.TotalSales)
+		cashier.AverageTransaction = roundMoney(cashier.AverageTransaction)
+	}
+	roundAmounts(report.PaymentMethods)
+}
+
+func roundAmounts(amounts map[string]float64) {
+	for key, amount := range amounts {
+		amounts[key] = roundMoney(amount)
+	}
+}
@@ -1,28 +1,104 @@
 package services
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/pos-system/backend/internal/repository"
 	"github.com/pos-system/backend/pkg/auth"
+	"github.com/pos-system/backend/pkg/config"
+	"github.com/pos-system/backend/pkg/mailer"
+	"github.com/pos-system/backend/pkg/storage"
 )
 
 // Services holds all service instances
 type Services struct {
-	Auth *AuthService
-	User *UserService
+	Auth           *AuthService
+	User           *UserService
+	Transaction    *TransactionService
+	Receipt        *ReceiptService
+	Cart           *CartService
+	Product        *ProductService
+	Category       *CategoryService
+	Inventory      *InventoryService
+	Recommendation *RecommendationService
+	Expense        *ExpenseService
+	Analytics      *AnalyticsService
+	Report         *ReportService
+	Export         *ExportService
+	TwoFactor      *TwoFactorService
+
+	revoker                *TokenRevoker
+	loginGuard             *LoginGuard
+	recommendationInterval time.Duration
 }
 
 // NewServices creates all service instances
-func NewServices(repos *repository.Repositories, jwtManager *auth.JWTManager) *Services {
+func NewServices(repos *repository.Repositories, jwtManager *auth.JWTManager, cfg *config.Config) *Services {
+	transactionService := NewTransactionService(
+		repos.Transaction,
+		repos.Product,
+		repos.StockMovement,
+		repos.SystemConfig,
+		repos.User,
+		repos.AuditLog,
+		repos.EmailDelivery,
+		repos.DB,
+		cfg.TaxRate,
+	)
+
+	emailSender := newMailer(cfg)
+	revoker := NewTokenRevoker(repos.RevokedToken, jwtManager)
+	loginGuard := NewLoginGuard(
+		repos.LoginThrottle,
+		repos.DB,
+		cfg.LoginMaxFailures,
+		cfg.LoginMaxFailuresPerIP,
+		time.Duration(cfg.LoginBackoffBaseSeconds)*time.Second,
+		time.Duration(cfg.LoginLockoutMinutes)*time.Minute,
+	)
+
+	twoFactorIssuer := cfg.TwoFactorIssuer
+	if twoFactorIssuer == "" {
+		twoFactorIssuer = cfg.CompanyName
+	}
+	twoFactorService := NewTwoFactorService(
+		repos.TwoFactor,
+		repos.User,
+		repos.AuditLog,
+		repos.DB,
+		twoFactorIssuer,
+		time.Duration(cfg.TwoFactorChallengeTTLMinutes)*time.Minute,
+	)
+
+	exportSigningKey := cfg.ExportSigningKey
+	if exportSigningKey == "" {
+		exportSigningKey = randomExportSigningKey()
+	}
+
 	return &Services{
 		Auth: NewAuthService(
 			repos.User,
 			repos.Account,
 			repos.Session,
 			repos.Password,
+			repos.AuditLog,
 			jwtManager,
+			revoker,
+			loginGuard,
+			twoFactorService,
+			auth.NewPasswordManager(cfg.PasswordSaltRounds),
+			emailSender,
 			repos.DB,
+			cfg.FrontendURL,
+			time.Duration(cfg.PasswordResetTTLMinutes)*time.Minute,
+			cfg.RequireEmailVerification,
+			time.Duration(cfg.EmailVerificationTTLHours)*time.Hour,
+			time.Duration(cfg.EmailVerificationResendSeconds)*time.Second,
 		),
 		User: NewUserService(
 			repos.User,
@@ -30,9 +106,114 @@ func NewServices(repos *repository.Repositories, jwtManager *auth.JWTManager) *S
 			repos.Session,
 			repos.Password,
 			repos.AuditLog,
+			revoker,
+			loginGuard,
+			repos.DB,
+			cfg.BulkMaxItems,
+		),
+		Transaction: transactionService,
+		Receipt: NewReceiptService(
+			repos.Transaction,
+			repos.SystemConfig,
+			repos.EmailDelivery,
+			emailSender,
+			cfg.CompanyName,
+			cfg.DefaultCurrency,
+			cfg.EmailMaxAttempts,
+		),
+		Cart: NewCartService(
+			repos.Cart,
+			repos.Product,
+			transactionService,
+			repos.DB,
+			time.Duration(cfg.HeldCartTTLHours)*time.Hour,
+		),
+		Product: NewProductService(
+			repos.Product,
+			repos.Category,
+			repos.StockMovement,
+			repos.User,
+			repos.AuditLog,
+			repos.DB,
+			cfg.MaxFileSize,
+			cfg.ImportMaxRows,
+			cfg.BulkMaxItems,
+		),
+		Category: NewCategoryService(
+			repos.Category,
+			repos.User,
+			repos.AuditLog,
 			repos.DB,
+			cfg.BulkMaxItems,
 		),
+		Inventory: NewInventoryService(
+			repos.Product,
+			repos.StockAdjustment,
+			repos.StockMovement,
+			repos.User,
+			repos.AuditLog,
+			repos.DB,
+			cfg.AdjustmentApprovalQuantity,
+			cfg.AdjustmentApprovalValue,
+		),
+		Recommendation: NewRecommendationService(
+			repos.StockRecommendation,
+			repos.Product,
+			repos.Transaction,
+			repos.SystemConfig,
+			repos.DB,
+			cfg.RecommendationWindowDays,
+			cfg.RecommendationLeadTimeDays,
+		),
+		Expense: NewExpenseService(
+			repos.Expense,
+			repos.User,
+			repos.AuditLog,
+			repos.DB,
+			storage.NewLocalStore(cfg.UploadPath),
+			cfg.MaxFileSize,
+			cfg.AllowedTypes,
+		),
+		Analytics: NewAnalyticsService(
+			repos.Transaction,
+			repos.StockRecommendation,
+			time.Duration(cfg.DashboardCacheSeconds)*time.Second,
+		),
+		Report: NewReportService(repos.Transaction),
+		Export: NewExportService(
+			repos.Export,
+			repos.User,
+			storage.NewLocalStore(cfg.ExportPath),
+			exportSigningKey,
+			time.Duration(cfg.ExportLinkTTLMinutes)*time.Minute,
+			cfg.ExportWorkers,
+		),
+		TwoFactor:              twoFactorService,
+		revoker:                revoker,
+		loginGuard:             loginGuard,
+		recommendationInterval: time.Duration(cfg.RecommendationIntervalHours) * time.Hour,
+	}
+}
+
+// randomExportSigningKey returns a key for signing export links when none is
+// configured. Links signed with it stop working when the process restarts
+// and are not accepted by other instances, which is fine for development
+// only; production requires EXPORT_SIGNING_KEY.
+func randomExportSigningKey() string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("failed to generate export signing key: %v", err)
+	}
+	log.Println("EXPORT_SIGNING_KEY is not set, export links will not survive a restart")
+	return hex.EncodeToString(key)
+}
+
+// newMailer returns the mailer selected by the email provider setting
+func newMailer(cfg *config.Config) mailer.Mailer {
+	if cfg.EmailProvider == "file" {
+		return mailer.NewFileMailer(cfg.EmailOutboxPath, cfg.EmailFromName, cfg.EmailFromEmail)
 	}
+	return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.EmailFromName, cfg.EmailFromEmail)
 }
 
 // ServiceDependencies holds external dependencies needed by services
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+// TokenRevoker revokes access tokens before they expire. A revocation takes
+// effect immediately on this instance and is stored so other instances pick
+// it up on their next Sync.
+type TokenRevoker struct {
+	repo        repository.RevokedTokenRepository
+	revocations *auth.RevocationList
+}
+
+// NewTokenRevoker creates a token revoker feeding the revocation list that
+// jwtManager checks when validating access tokens
+func NewTokenRevoker(repo repository.RevokedTokenRepository, jwtManager *auth.JWTManager) *TokenRevoker {
+	return &TokenRevoker{
+		repo:        repo,
+		revocations: jwtManager.Revocations(),
+	}
+}
+
+// RevokeToken revokes the single access token carrying claims
+func (r *TokenRevoker) RevokeToken(ctx context.Context, claims *auth.Claims) error {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	jti := claims.ID
+	expiresAt := claims.ExpiresAt.Time
+	revoked := &models.RevokedToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		JTI:       &jti,
+		ExpiresAt: expiresAt,
+	}
+	if err := r.repo.Create(ctx, revoked); err != nil {
+		return fmt.Errorf("failed to store token revocation: %w", err)
+	}
+
+	repository.AfterCommit(ctx, func() {
+		r.revocations.RevokeToken(jti, expiresAt)
+	})
+	return nil
+}
+
+// RevokeUser revokes every access token issued to userID before the current
+// second. Inside a
+// transaction the revocation takes effect when it commits, so a change that
+// rolls back leaves the user signed in.
+func (r *TokenRevoker) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	revoked := &models.RevokedToken{
+		ID:           uuid.New(),
+		UserID:       userID,
+		IssuedBefore: &now,
+		ExpiresAt:    r.revocations.UserEntryExpiry(now),
+	}
+	if err := r.repo.Create(ctx, revoked); err != nil {
+		return fmt.Errorf("failed to store token revocation: %w", err)
+	}
+
+	repository.AfterCommit(ctx, func() {
+		r.revocations.RevokeUser(userID.String(), now)
+	})
+	return nil
+}
+
+// Sync loads revocations recorded by other instances, forgets those whose
+// tokens have all expired and deletes their rows, returning how many rows
+// were deleted
+func (r *TokenRevoker) Sync(ctx context.Context) (int64, error) {
+	now := time.Now()
+	revoked, err := r.repo.ListActive(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load token revocations: %w", err)
+	}
+	for _, entry := range revoked {
+		switch {
+		case entry.JTI != nil:
+			r.revocations.RevokeToken(*entry.JTI, entry.ExpiresAt)
+		case entry.IssuedBefore != nil:
+			r.revocations.RevokeUser(entry.UserID.String(), *entry.IssuedBefore)
+		}
+	}
+	r.revocations.Prune(now)
+
+	deleted, err := r.repo.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired token revocations: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrProductNotFound     = errors.New("product not found")
+	ErrProductUnavailable  = errors.New("product is not available for sale")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrInsufficientPayment = errors.New("amount paid is less than the total")
+	ErrInvalidDiscount     = errors.New("discount exceeds the amount it applies to")
+	ErrInvalidPayment      = errors.New("invalid payment method")
+	ErrOverpayment         = errors.New("non-cash tenders exceed the amount due")
+)
+
+// receiptIDAttempts bounds the retries when a generated receipt ID collides
+const receiptIDAttempts = 5
+
+// StockError reports a line that cannot be fulfilled from current stock
+type StockError struct {
+	ProductID   uuid.UUID
+	ProductName string
+	Available   int
+	Requested   int
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("insufficient stock for %s: %d available, %d requested", e.ProductName, e.Available, e.Requested)
+}
+
+func (e *StockError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// Details returns the fields reported to API clients
+func (e *StockError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"productId":   e.ProductID,
+		"productName": e.ProductName,
+		"available":   e.Available,
+		"requested":   e.Requested,
+	}
+}
+
+// TransactionService handles sales transactions
+type TransactionService struct {
+	transactionRepo   repository.TransactionRepository
+	productRepo       repository.ProductRepository
+	stockMovementRepo repository.StockMovementRepository
+	systemConfigRepo  repository.SystemConfigRepository
+	userRepo          repository.UserRepository
+	auditRepo         repository.AuditLogRepository
+	emailRepo         repository.EmailDeliveryRepository
+	db                *gorm.DB
+	defaultTaxRate    float64
+}
+
+// NewTransactionService creates a new transaction service. defaultTaxRate is
+// used until a system configuration row exists.
+func NewTransactionService(
+	transactionRepo repository.TransactionRepository,
+	productRepo repository.ProductRepository,
+	stockMovementRepo repository.StockMovementRepository,
+	systemConfigRepo repository.SystemConfigRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	emailRepo repository.EmailDeliveryRepository,
+	db *gorm.DB,
+	defaultTaxRate float64,
+) *TransactionService {
+	return &TransactionService{
+		transactionRepo:   transactionRepo,
+		productRepo:       productRepo,
+		stockMovementRepo: stockMovementRepo,
+		systemConfigRepo:  systemConfigRepo,
+		userRepo:          userRepo,
+		auditRepo:         auditRepo,
+		emailRepo:         emailRepo,
+		db:                db,
+		defaultTaxRate:    defaultTaxRate,
+	}
+}
+
+// checkoutLine is a request line merged by product
+type checkoutLine struct {
+	productID uuid.UUID
+	quantity  int
+	discount  float64
+}
+
+// Checkout records a completed sale. Product rows are locked for the duration
+// of the database transaction so concurrent checkouts cannot oversell; prices
+// and names are snapshotted from the locked rows rather than trusted from the
+// client.
+func (s *TransactionService) Checkout(ctx context.Context, cashierID uuid.UUID, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	tenders := checkoutTenders(req)
+	for _, tender := range tenders {
+		if !models.ValidatePaymentMethod(string(tender.Method)) {
+			return nil, ErrInvalidPayment
+		}
+	}
+
+	cashier, err := s.userRepo.GetByID(ctx, cashierID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get cashier: %w", err)
+	}
+	if !cashier.IsActive {
+		return nil, ErrUserNotActive
+	}
+
+	lines := mergeCheckoutLines(req.Items)
+
+	var transaction *models.Transaction
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		taxRate, err := s.taxRate(ctx)
+		if err != nil {
+			return err
+		}
+
+		transaction = &models.Transaction{
+			ID:            uuid.New(),
+			CashierID:     cashierID,
+			CustomerName:  req.CustomerName,
+			CustomerEmail: req.CustomerEmail,
+			CustomerPhone: req.CustomerPhone,
+			Status:        models.TransactionStatusCompleted,
+			Notes:         req.Notes,
+		}
+
+		products := make(map[uuid.UUID]*models.Product, len(lines))
+		itemDiscounts := 0.0
+		for _, line := range lines {
+			product, err := s.productRepo.GetByIDForUpdate(ctx, line.productID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: %s", ErrProductNotFound, line.productID)
+				}
+				return fmt.Errorf("failed to lock product: %w", err)
+			}
+			if !product.IsActive || product.Status != models.ProductStatusActive {
+				return fmt.Errorf("%w: %s", ErrProductUnavailable, product.Name)
+			}
+			if product.Stock < line.quantity {
+				return &StockError{
+					ProductID:   product.ID,
+					ProductName: product.Name,
+					Available:   product.Stock,
+					Requested:   line.quantity,
+				}
+			}
+
+			subtotal := roundMoney(product.Price * float64(line.quantity))
+			if line.discount > subtotal {
+				return fmt.Errorf("%w: %s", ErrInvalidDiscount, product.Name)
+			}
+
+			transaction.Items = append(transaction.Items, models.TransactionItem{
+				TransactionID: transaction.ID,
+				ProductID:     product.ID,
+				ProductName:   product.Name,
+				ProductSKU:    product.SKU,
+				Quantity:      line.quantity,
+				UnitPrice:     product.Price,
+				Discount:      line.discount,
+				Subtotal:      subtotal,
+				UnitCost:      &product.Cost,
+			})
+			transaction.Subtotal += subtotal
+			itemDiscounts += line.discount
+			products[product.ID] = product
+		}
+
+		transaction.Subtotal = roundMoney(transaction.Subtotal)
+		transaction.DiscountAmount = roundMoney(itemDiscounts)
+		if req.DiscountAmount != nil {
+			transaction.DiscountAmount = roundMoney(transaction.DiscountAmount + *req.DiscountAmount)
+		}
+		if transaction.DiscountAmount > transaction.Subtotal {
+			return ErrInvalidDiscount
+		}
+
+		taxable := transaction.Subtotal - transaction.DiscountAmount
+		transaction.TaxAmount = roundMoney(taxable * taxRate)
+		transaction.Total = roundMoney(taxable + transaction.TaxAmount)
+
+		if err := applyTenders(transaction, tenders); err != nil {
+			return err
+		}
+		if transaction.PaymentRef == nil {
+			transaction.PaymentRef = req.PaymentRef
+		}
+
+		receiptID, err := s.newReceiptID(ctx)
+		if err != nil {
+			return err
+		}
+		transaction.ReceiptID = receiptID
+
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		for _, line := range lines {
+			product := products[line.productID]
+			product.Stock -= line.quantity
+			if err := s.productRepo.Update(ctx, product); err != nil {
+				return fmt.Errorf("failed to update stock: %w", err)
+			}
+
+			movement := &models.StockMovement{
+				ProductID:   product.ID,
+				Type:        models.StockMovementOut,
+				Quantity:    line.quantity,
+				Reason:      "sale",
+				Reference:   transaction.ReceiptID,
+				PerformedBy: cashierID,
+			}
+			if err := s.stockMovementRepo.Create(ctx, movement); err != nil {
+				return fmt.Errorf("failed to record stock movement: %w", err)
+			}
+		}
+
+		auditLog := newAuditLog(ctx, cashier, models.AuditActionCreateTransaction, "transaction", transaction.ID.String(), nil,
+			map[string]interface{}{
+				"receiptId":     transaction.ReceiptID,
+				"itemCount":     transaction.GetItemCount(),
+				"subtotal":      transaction.Subtotal,
+				"discount":      transaction.DiscountAmount,
+				"tax":           transaction.TaxAmount,
+				"total":         transaction.Total,
+				"paymentMethod": transaction.PaymentMethod,
+				"tenders":       len(transaction.Payments),
+			})
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+
+		// Queued with the sale so the email goes out only if the sale commits
+		if transaction.CustomerEmail != nil && (req.EmailReceipt == nil || *req.EmailReceipt) {
+			delivery := newReceiptDelivery(transaction.ID, *transaction.CustomerEmail, &cashierID)
+			if err := s.emailRepo.Create(ctx, delivery); err != nil {
+				return fmt.Errorf("failed to queue receipt email: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTransaction(ctx, transaction.ID)
+}
+
+// GetTransaction retrieves a transaction with its items and payments
+func (s *TransactionService) GetTransaction(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// GetTransactionByReceiptID retrieves a transaction by its receipt ID
+func (s *TransactionService) GetTransactionByReceiptID(ctx context.Context, receiptID string) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByReceiptID(ctx, receiptID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// ListTransactions retrieves transactions matching the filters
+func (s *TransactionService) ListTransactions(ctx context.Context, filters *models.TransactionFilters, pagination *models.PaginationQuery) ([]models.Transaction, int64, error) {
+	transactions, total, err := s.transactionRepo.List(ctx, filters, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return transactions, total, nil
+}
+
+// taxRate returns the configured tax rate, falling back to the default when
+// the system has not been configured yet
+func (s *TransactionService) taxRate(ctx context.Context) (float64, error) {
+	config, err := s.systemConfigRepo.Get(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.defaultTaxRate, nil
+		}
+		return 0, fmt.Errorf("failed to get system config: %w", err)
+	}
+	return config.TaxRate, nil
+}
+
+// newReceiptID generates a receipt ID of the form RCP-YYYYMMDD-XXXXXXXX that
+// is not yet in use
+func (s *TransactionService) newReceiptID(ctx context.Context) (string, error) {
+	for i := 0; i < receiptIDAttempts; i++ {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", fmt.Errorf("failed to generate receipt ID: %w", err)
+		}
+		receiptID := fmt.Sprintf("RCP-%s-%X", time.Now().Format("20060102"), suffix)
+
+		_, err := s.transactionRepo.GetByReceiptID(ctx, receiptID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return receiptID, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check receipt ID: %w", err)
+		}
+	}
+	return "", errors.New("failed to generate a unique receipt ID")
+}
+
+// mergeCheckoutLines combines repeated products into one line each, sorted
+// by product ID so row locks are always taken in the same order
+func mergeCheckoutLines(items []models.CreateTransactionItem) []checkoutLine {
+	index := make(map[uuid.UUID]int, len(items))
+	var lines []checkoutLine
+	for _, item := range items {
+		discount := 0.0
+		if item.Discount != nil {
+			discount = *item.Discount
+		}
+		if i, ok := index[item.ProductID]; ok {
+			lines[i].quantity += item.Quantity
+			lines[i].discount += discount
+			continue
+		}
+		index[item.ProductID] = len(lines)
+		lines = append(lines, checkoutLine{productID: item.ProductID, quantity: item.Quantity, discount: discount})
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].productID.String() < lines[j].productID.String()
+	})
+	return lines
+}
+
+// checkoutTenders returns the request's split tenders, or a single tender
+// built from the legacy PaymentMethod/AmountPaid fields
+func checkoutTenders(req *models.CreateTransactionRequest) []models.CreateTransactionPayment {
+	if len(req.Payments) > 0 {
+		return req.Payments
+	}
+	return []models.CreateTransactionPayment{{
+		Method:    req.PaymentMethod,
+		Amount:    req.AmountPaid,
+		Reference: req.PaymentRef,
+	}}
+}
+
+// applyTenders records the tenders as payments on a priced transaction. Only
+// cash can be overpaid: change is taken from the cash tenders, so each
+// stored payment holds the amount actually applied to the sale and the
+// payments always sum to the total. The header carries the total tendered
+// and the method that covered the largest share.
+func applyTenders(transaction *models.Transaction, tenders []models.CreateTransactionPayment) error {
+	tendered, nonCash := 0.0, 0.0
+	for _, tender := range tenders {
+		tendered += tender.Amount
+		if tender.Method != models.PaymentMethodCash {
+			nonCash += tender.Amount
+		}
+	}
+	tendered, nonCash = roundMoney(tendered), roundMoney(nonCash)
+
+	if tendered < transaction.Total {
+		return ErrInsufficientPayment
+	}
+	if nonCash > transaction.Total {
+		return ErrOverpayment
+	}
+
+	transaction.AmountPaid = tendered
+	transaction.Change = roundMoney(tendered - transaction.Total)
+
+	now := time.Now()
+	payments := make([]models.Payment, len(tenders))
+	change := transaction.Change
+	// Walk backwards so change comes out of the last cash tendered
+	for i := len(tenders) - 1; i >= 0; i-- {
+		amount := roundMoney(tenders[i].Amount)
+		if tenders[i].Method == models.PaymentMethodCash && change > 0 {
+			returned := math.Min(amount, change)
+			amount = roundMoney(amount - returned)
+			change = roundMoney(change - returned)
+		}
+		payments[i] = models.Payment{
+			TransactionID: transaction.ID,
+			Amount:        amount,
+			Method:        tenders[i].Method,
+			Reference:     tenders[i].Reference,
+			Status:        models.PaymentStatusCompleted,
+			ProcessedAt:   &now,
+		}
+	}
+
+	// A cash tender that was handed straight back as change is not a payment
+	primary := -1
+	for i := range payments {
+		if payments[i].Amount <= 0 {
+			continue
+		}
+		transaction.Payments = append(transaction.Payments, payments[i])
+		last := len(transaction.Payments) - 1
+		if primary < 0 || payments[i].Amount > transaction.Payments[primary].Amount {
+			primary = last
+		}
+	}
+
+	if primary >= 0 {
+		transaction.PaymentMethod = transaction.Payments[primary].Method
+		transaction.PaymentRef = transaction.Payments[primary].Reference
+	} else {
+		// Zero total: nothing was applied, keep the first tender's method
+		transaction.PaymentMethod = tenders[0].Method
+	}
+
+	return nil
+}
+
+// roundMoney rounds an amount to cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
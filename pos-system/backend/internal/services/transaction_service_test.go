@@ -0,0 +1,443 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+)
+
+type checkoutFixture struct {
+	service      *TransactionService
+	cashier      *models.User
+	products     *fakeProductRepo
+	transactions *fakeTransactionRepo
+	movements    *fakeStockMovementRepo
+	audit        *fakeAuditRepo
+}
+
+func newCheckoutFixture(products ...*models.Product) *checkoutFixture {
+	f := &checkoutFixture{
+		cashier:      &models.User{ID: uuid.New(), Name: "Casey", Role: models.RoleCashier, IsActive: true},
+		products:     newFakeProductRepo(products...),
+		transactions: newFakeTransactionRepo(),
+		movements:    &fakeStockMovementRepo{},
+		audit:        &fakeAuditRepo{},
+	}
+	f.service = NewTransactionService(
+		f.transactions,
+		f.products,
+		f.movements,
+		&fakeSystemConfigRepo{},
+		newFakeUserRepo(f.cashier),
+		f.audit,
+		&fakeEmailRepo{},
+		nil,
+		0.10,
+	)
+	return f
+}
+
+func newTestProduct(name string, price, cost float64, stock int) *models.Product {
+	return &models.Product{
+		ID:       uuid.New(),
+		Name:     name,
+		SKU:      "SKU-" + name,
+		Price:    price,
+		Cost:     cost,
+		Stock:    stock,
+		Status:   models.ProductStatusActive,
+		IsActive: true,
+	}
+}
+
+func cashPayment(amount float64) []models.CreateTransactionPayment {
+	return []models.CreateTransactionPayment{{Method: models.PaymentMethodCash, Amount: amount}}
+}
+
+func TestCheckout(t *testing.T) {
+	coffee := newTestProduct("coffee", 2.50, 1.00, 10)
+	bagel := newTestProduct("bagel", 3.00, 1.20, 5)
+	f := newCheckoutFixture(coffee, bagel)
+
+	discount := 0.50
+	req := &models.CreateTransactionRequest{
+		Items: []models.CreateTransactionItem{
+			{ProductID: coffee.ID, Quantity: 1},
+			{ProductID: bagel.ID, Quantity: 2, Discount: &discount},
+			{ProductID: coffee.ID, Quantity: 1},
+		},
+		Payments: cashPayment(20),
+	}
+
+	transaction, err := f.service.Checkout(txContext(), f.cashier.ID, req)
+	if err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+
+	// 2 x 2.50 + 2 x 3.00 - 0.50 = 10.50, plus 10% tax
+	if transaction.Subtotal != 11.00 || transaction.DiscountAmount != 0.50 {
+		t.Errorf("Expected subtotal 11.00 and discount 0.50, got %.2f and %.2f", transaction.Subtotal, transaction.DiscountAmount)
+	}
+	if transaction.TaxAmount != 1.05 || transaction.Total != 11.55 {
+		t.Errorf("Expected tax 1.05 and total 11.55, got %.2f and %.2f", transaction.TaxAmount, transaction.Total)
+	}
+	if transaction.Change != 8.45 {
+		t.Errorf("Expected change 8.45, got %.2f", transaction.Change)
+	}
+	if len(transaction.Items) != 2 {
+		t.Fatalf("Expected repeated products merged into 2 lines, got %d", len(transaction.Items))
+	}
+	for _, item := range transaction.Items {
+		product := map[uuid.UUID]*models.Product{coffee.ID: coffee, bagel.ID: bagel}[item.ProductID]
+		if item.UnitPrice != product.Price {
+			t.Errorf("Expected %s priced from the product at %.2f, got %.2f", product.Name, product.Price, item.UnitPrice)
+		}
+		if item.UnitCost == nil || *item.UnitCost != product.Cost {
+			t.Errorf("Expected %s unit cost captured as %.2f, got %v", product.Name, product.Cost, item.UnitCost)
+		}
+	}
+	if transaction.ReceiptID == "" {
+		t.Error("Expected a receipt ID")
+	}
+
+	// Each product is locked once, in ID order, so concurrent sales cannot deadlock
+	if len(f.products.locked) != 2 {
+		t.Fatalf("Expected 2 product locks, got %d", len(f.products.locked))
+	}
+	if !sort.SliceIsSorted(f.products.locked, func(i, j int) bool {
+		return f.products.locked[i].String() < f.products.locked[j].String()
+	}) {
+		t.Errorf("Expected products locked in ID order, got %v", f.products.locked)
+	}
+
+	if stock := f.products.products[coffee.ID].Stock; stock != 8 {
+		t.Errorf("Expected coffee stock 8, got %d", stock)
+	}
+	if stock := f.products.products[bagel.ID].Stock; stock != 3 {
+		t.Errorf("Expected bagel stock 3, got %d", stock)
+	}
+	if len(f.movements.movements) != 2 {
+		t.Errorf("Expected 2 stock movements, got %d", len(f.movements.movements))
+	}
+	for _, movement := range f.movements.movements {
+		if movement.Type != models.StockMovementOut || movement.Reference != transaction.ReceiptID {
+			t.Errorf("Expected an outgoing movement referencing %s, got %+v", transaction.ReceiptID, movement)
+		}
+	}
+	if len(f.audit.logs) != 1 || f.audit.logs[0].Action != models.AuditActionCreateTransaction {
+		t.Errorf("Expected one transaction audit log, got %+v", f.audit.logs)
+	}
+}
+
+func TestCheckoutRejected(t *testing.T) {
+	discount := 5.00
+	tests := []struct {
+		name    string
+		stock   int
+		status  models.ProductStatus
+		req     func(productID uuid.UUID) *models.CreateTransactionRequest
+		wantErr error
+	}{
+		{
+			name:  "more than in stock",
+			stock: 2,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: productID, Quantity: 3}},
+					Payments: cashPayment(100),
+				}
+			},
+			wantErr: ErrInsufficientStock,
+		},
+		{
+			name:  "stock exceeded only once lines are merged",
+			stock: 2,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items: []models.CreateTransactionItem{
+						{ProductID: productID, Quantity: 2},
+						{ProductID: productID, Quantity: 1},
+					},
+					Payments: cashPayment(100),
+				}
+			},
+			wantErr: ErrInsufficientStock,
+		},
+		{
+			name:   "discontinued product",
+			stock:  10,
+			status: models.ProductStatusDiscontinued,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: productID, Quantity: 1}},
+					Payments: cashPayment(100),
+				}
+			},
+			wantErr: ErrProductUnavailable,
+		},
+		{
+			name:  "unknown product",
+			stock: 10,
+			req: func(uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: uuid.New(), Quantity: 1}},
+					Payments: cashPayment(100),
+				}
+			},
+			wantErr: ErrProductNotFound,
+		},
+		{
+			name:  "line discount above the line",
+			stock: 10,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: productID, Quantity: 1, Discount: &discount}},
+					Payments: cashPayment(100),
+				}
+			},
+			wantErr: ErrInvalidDiscount,
+		},
+		{
+			name:  "unknown payment method",
+			stock: 10,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: productID, Quantity: 1}},
+					Payments: []models.CreateTransactionPayment{{Method: "CHEQUE", Amount: 100}},
+				}
+			},
+			wantErr: ErrInvalidPayment,
+		},
+		{
+			name:  "underpaid",
+			stock: 10,
+			req: func(productID uuid.UUID) *models.CreateTransactionRequest {
+				return &models.CreateTransactionRequest{
+					Items:    []models.CreateTransactionItem{{ProductID: productID, Quantity: 1}},
+					Payments: cashPayment(1),
+				}
+			},
+			wantErr: ErrInsufficientPayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := newTestProduct("coffee", 2.50, 1.00, tt.stock)
+			if tt.status != "" {
+				product.Status = tt.status
+			}
+			f := newCheckoutFixture(product)
+
+			_, err := f.service.Checkout(txContext(), f.cashier.ID, tt.req(product.ID))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if len(f.transactions.transactions) != 0 {
+				t.Error("Expected no transaction to be stored")
+			}
+			if stock := f.products.products[product.ID].Stock; stock != tt.stock {
+				t.Errorf("Expected stock left at %d, got %d", tt.stock, stock)
+			}
+			if len(f.movements.movements) != 0 {
+				t.Errorf("Expected no stock movements, got %d", len(f.movements.movements))
+			}
+		})
+	}
+}
+
+func TestCheckoutStockErrorDetails(t *testing.T) {
+	product := newTestProduct("coffee", 2.50, 1.00, 1)
+	f := newCheckoutFixture(product)
+
+	_, err := f.service.Checkout(txContext(), f.cashier.ID, &models.CreateTransactionRequest{
+		Items:    []models.CreateTransactionItem{{ProductID: product.ID, Quantity: 4}},
+		Payments: cashPayment(100),
+	})
+
+	var stockErr *StockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("Expected a *StockError, got %v", err)
+	}
+	if stockErr.Available != 1 || stockErr.Requested != 4 || stockErr.ProductID != product.ID {
+		t.Errorf("Unexpected stock error details %+v", stockErr)
+	}
+}
+
+func TestCheckoutInactiveCashier(t *testing.T) {
+	product := newTestProduct("coffee", 2.50, 1.00, 10)
+	f := newCheckoutFixture(product)
+	f.cashier.IsActive = false
+
+	_, err := f.service.Checkout(txContext(), f.cashier.ID, &models.CreateTransactionRequest{
+		Items:    []models.CreateTransactionItem{{ProductID: product.ID, Quantity: 1}},
+		Payments: cashPayment(10),
+	})
+	if !errors.Is(err, ErrUserNotActive) {
+		t.Errorf("Expected ErrUserNotActive, got %v", err)
+	}
+	if len(f.products.locked) != 0 {
+		t.Error("Expected no products to be locked for an inactive cashier")
+	}
+}
+
+func TestApplyTenders(t *testing.T) {
+	cash := models.PaymentMethodCash
+	card := models.PaymentMethodCard
+	digital := models.PaymentMethodDigital
+
+	type payment struct {
+		method models.PaymentMethod
+		amount float64
+	}
+	tests := []struct {
+		name        string
+		total       float64
+		tenders     []payment
+		wantErr     error
+		wantChange  float64
+		wantPaid    float64
+		wantPrimary models.PaymentMethod
+		wantStored  []payment // payments recorded against the sale
+	}{
+		{
+			name:        "exact cash",
+			total:       12.00,
+			tenders:     []payment{{cash, 12.00}},
+			wantPaid:    12.00,
+			wantPrimary: cash,
+			wantStored:  []payment{{cash, 12.00}},
+		},
+		{
+			name:        "cash overpaid gives change",
+			total:       12.00,
+			tenders:     []payment{{cash, 20.00}},
+			wantChange:  8.00,
+			wantPaid:    20.00,
+			wantPrimary: cash,
+			wantStored:  []payment{{cash, 12.00}},
+		},
+		{
+			name:        "change comes from cash, not card",
+			total:       12.00,
+			tenders:     []payment{{card, 10.00}, {cash, 5.00}},
+			wantChange:  3.00,
+			wantPaid:    15.00,
+			wantPrimary: card,
+			wantStored:  []payment{{card, 10.00}, {cash, 2.00}},
+		},
+		{
+			name:        "change comes from the last cash tendered",
+			total:       12.00,
+			tenders:     []payment{{cash, 10.00}, {cash, 5.00}},
+			wantChange:  3.00,
+			wantPaid:    15.00,
+			wantPrimary: cash,
+			wantStored:  []payment{{cash, 10.00}, {cash, 2.00}},
+		},
+		{
+			name:        "cash handed straight back is not a payment",
+			total:       10.00,
+			tenders:     []payment{{card, 10.00}, {cash, 5.00}},
+			wantChange:  5.00,
+			wantPaid:    15.00,
+			wantPrimary: card,
+			wantStored:  []payment{{card, 10.00}},
+		},
+		{
+			name:        "exact split across cards",
+			total:       30.00,
+			tenders:     []payment{{card, 10.00}, {digital, 20.00}},
+			wantPaid:    30.00,
+			wantPrimary: digital,
+			wantStored:  []payment{{card, 10.00}, {digital, 20.00}},
+		},
+		{
+			name:        "split cents sum without float drift",
+			total:       0.30,
+			tenders:     []payment{{card, 0.10}, {digital, 0.20}},
+			wantPaid:    0.30,
+			wantPrimary: digital,
+			wantStored:  []payment{{card, 0.10}, {digital, 0.20}},
+		},
+		{
+			name:    "card overpaid",
+			total:   12.00,
+			tenders: []payment{{card, 15.00}},
+			wantErr: ErrOverpayment,
+		},
+		{
+			name:    "non-cash split above the total",
+			total:   12.00,
+			tenders: []payment{{card, 8.00}, {digital, 6.00}},
+			wantErr: ErrOverpayment,
+		},
+		{
+			name:    "non-cash overpay with cash alongside",
+			total:   12.00,
+			tenders: []payment{{card, 13.00}, {cash, 1.00}},
+			wantErr: ErrOverpayment,
+		},
+		{
+			name:    "split below the total",
+			total:   12.00,
+			tenders: []payment{{card, 5.00}, {cash, 5.00}},
+			wantErr: ErrInsufficientPayment,
+		},
+		{
+			name:    "short by a cent",
+			total:   12.00,
+			tenders: []payment{{card, 6.00}, {digital, 5.99}},
+			wantErr: ErrInsufficientPayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{ID: uuid.New(), Total: tt.total}
+			tenders := make([]models.CreateTransactionPayment, len(tt.tenders))
+			for i, tender := range tt.tenders {
+				tenders[i] = models.CreateTransactionPayment{Method: tender.method, Amount: tender.amount}
+			}
+
+			err := applyTenders(transaction, tenders)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				if len(transaction.Payments) != 0 {
+					t.Errorf("Expected no payments on a rejected sale, got %d", len(transaction.Payments))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if transaction.Change != tt.wantChange || transaction.AmountPaid != tt.wantPaid {
+				t.Errorf("Expected paid %.2f change %.2f, got %.2f and %.2f", tt.wantPaid, tt.wantChange, transaction.AmountPaid, transaction.Change)
+			}
+			if transaction.PaymentMethod != tt.wantPrimary {
+				t.Errorf("Expected primary method %s, got %s", tt.wantPrimary, transaction.PaymentMethod)
+			}
+			if len(transaction.Payments) != len(tt.wantStored) {
+				t.Fatalf("Expected %d payments, got %d", len(tt.wantStored), len(transaction.Payments))
+			}
+			sum := 0.0
+			for i, want := range tt.wantStored {
+				got := transaction.Payments[i]
+				if got.Method != want.method || got.Amount != want.amount {
+					t.Errorf("Payment %d: expected %s %.2f, got %s %.2f", i, want.method, want.amount, got.Method, got.Amount)
+				}
+				sum += got.Amount
+			}
+			if roundMoney(sum) != tt.total {
+				t.Errorf("Expected payments to sum to the total %.2f, got %.2f", tt.total, sum)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+// BeginLogin decides whether a user whose password was accepted needs a
+// second step. It returns nil when tokens can be issued straight away, and
+// otherwise the response carrying a challenge token for VerifyTwoFactor.
+func (s *TwoFactorService) BeginLogin(ctx context.Context, user *models.User) (*models.AuthResponse, error) {
+	twoFactor, err := s.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := twoFactor != nil && twoFactor.Enabled
+	if !enabled {
+		required, err := s.Required(ctx, user.Role)
+		if err != nil || !required {
+			return nil, err
+		}
+	}
+
+	token, err := newChallengeToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	challenge := &models.TwoFactorChallenge{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(s.challengeTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateChallenge(ctx, challenge); err != nil {
+		return nil, fmt.Errorf("failed to create challenge: %w", err)
+	}
+
+	return &models.AuthResponse{
+		User:                   *user,
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: !enabled,
+		ChallengeToken:         token,
+		ChallengeExpiresIn:     int(s.challengeTTL.Seconds()),
+	}, nil
+}
+
+// ChallengeUser returns the user a live login challenge was issued to
+func (s *TwoFactorService) ChallengeUser(ctx context.Context, challengeToken string) (*models.User, error) {
+	challenge, err := s.getChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, challenge.UserID)
+}
+
+// SetupLogin starts enrollment for a user whose login challenge requires
+// them to set up an authenticator first
+func (s *TwoFactorService) SetupLogin(ctx context.Context, challengeToken string) (*models.TwoFactorEnrollment, error) {
+	user, err := s.ChallengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user)
+}
+
+// CompleteLogin checks the code for a login challenge and consumes the
+// challenge. For a user enrolling during login the code confirms the
+// enrollment, and their new recovery codes are returned.
+func (s *TwoFactorService) CompleteLogin(ctx context.Context, challengeToken, code string) ([]string, error) {
+	var codes []string
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		challenge, err := s.getChallenge(ctx, challengeToken)
+		if err != nil {
+			return err
+		}
+		user, err := s.getUser(ctx, challenge.UserID)
+		if err != nil {
+			return err
+		}
+
+		twoFactor, err := s.get(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if twoFactor == nil {
+			return ErrTwoFactorNotEnrolling
+		}
+
+		if twoFactor.Enabled {
+			err = s.checkCode(ctx, twoFactor, code)
+		} else {
+			codes, err = s.confirmEnrollment(ctx, user, code)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.DeleteChallenge(ctx, challenge.ID); err != nil {
+			return fmt.Errorf("failed to delete challenge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// PurgeExpiredChallenges deletes login challenges that were never completed
+func (s *TwoFactorService) PurgeExpiredChallenges(ctx context.Context) (int64, error) {
+	deleted, err := s.repo.DeleteExpiredChallenges(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge two-factor challenges: %w", err)
+	}
+	return deleted, nil
+}
+
+// checkCode accepts either a TOTP code or an unused recovery code, which is
+// used up. It must be called inside RunInTx.
+func (s *TwoFactorService) checkCode(ctx context.Context, twoFactor *models.TwoFactor, code string) error {
+	if totp := normalizeTOTPCode(code); isTOTPCode(totp) {
+		return s.checkTOTP(ctx, twoFactor, totp)
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, twoFactor.UserID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkTOTP accepts a TOTP code from a time step later than the last one
+// used, so an observed code cannot be replayed. It must be called inside
+// RunInTx.
+func (s *TwoFactorService) checkTOTP(ctx context.Context, twoFactor *models.TwoFactor, code string) error {
+	step, ok := auth.ValidateTOTP(twoFactor.Secret, normalizeTOTPCode(code), time.Now(), totpSkew)
+	if !ok || step <= twoFactor.LastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	twoFactor.LastUsedStep = step
+	if err := s.repo.Save(ctx, twoFactor); err != nil {
+		return fmt.Errorf("failed to save two-factor state: %w", err)
+	}
+	return nil
+}
+
+func (s *TwoFactorService) getChallenge(ctx context.Context, challengeToken string) (*models.TwoFactorChallenge, error) {
+	challenge, err := s.repo.GetChallenge(ctx, auth.HashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidTwoFactorChallenge
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+	if challenge.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+	return challenge, nil
+}
+
+// newChallengeToken returns a random login challenge token. Only its hash
+// is stored.
+func newChallengeToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// normalizeTOTPCode drops the spaces some apps show in the middle of a code
+func normalizeTOTPCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+// isTOTPCode reports whether code looks like a TOTP code rather than a
+// recovery code
+func isTOTPCode(code string) bool {
+	if len(code) != auth.TOTPDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
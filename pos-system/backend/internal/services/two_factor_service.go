@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+var (
+	ErrInvalidTwoFactorCode      = errors.New("two-factor code is invalid")
+	ErrInvalidTwoFactorChallenge = errors.New("two-factor challenge is invalid or has expired")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolling     = errors.New("two-factor enrollment has not been started")
+	ErrTwoFactorRequired         = errors.New("two-factor authentication is required for this role")
+)
+
+// totpSkew is how many 30 second steps either side of now a code is accepted
+// for, to allow for clock drift on the user's device
+const totpSkew = 1
+
+// TwoFactorService manages TOTP authenticators, recovery codes and the
+// admin policy saying which roles must use them. AuthService calls it for
+// the second step of a login.
+type TwoFactorService struct {
+	repo         repository.TwoFactorRepository
+	userRepo     repository.UserRepository
+	auditRepo    repository.AuditLogRepository
+	db           *gorm.DB
+	issuer       string
+	challengeTTL time.Duration
+}
+
+// NewTwoFactorService creates a new two-factor service. Authenticator apps
+// list accounts under issuer, and login challenges expire after
+// challengeTTL.
+func NewTwoFactorService(
+	repo repository.TwoFactorRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	db *gorm.DB,
+	issuer string,
+	challengeTTL time.Duration,
+) *TwoFactorService {
+	return &TwoFactorService{
+		repo:         repo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		db:           db,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+	}
+}
+
+// Status reports whether a user has two-factor authentication enabled and
+// whether their role requires it
+func (s *TwoFactorService) Status(ctx context.Context, userID uuid.UUID) (*models.TwoFactorStatus, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.Required(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.TwoFactorStatus{Required: required}
+
+	twoFactor, err := s.get(ctx, user.ID)
+	if err != nil || twoFactor == nil || !twoFactor.Enabled {
+		return status, err
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	status.RecoveryCodesRemaining, err = s.repo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return status, nil
+}
+
+// Enroll starts enrollment with a new secret for the user's authenticator
+// app. Starting again replaces a secret that was never confirmed.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (*models.TwoFactorEnrollment, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user)
+}
+
+// ConfirmEnrollment enables two-factor authentication once code shows the
+// authenticator app was set up, and returns the user's recovery codes
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		codes, err = s.confirmEnrollment(ctx, user, code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking a TOTP or
+// recovery code. Users whose role requires two factors cannot turn it off.
+func (s *TwoFactorService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.Required(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		twoFactor, err := s.getEnabled(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkCode(ctx, twoFactor, code); err != nil {
+			return err
+		}
+		return s.disable(ctx, user, user, twoFactor)
+	})
+}
+
+// Reset removes a user's authenticator and recovery codes so they can enroll
+// again, for when both are lost (admin only). If their role requires two
+// factors they are asked to enroll at their next login.
+func (s *TwoFactorService) Reset(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserProfileNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		twoFactor, err := s.get(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if twoFactor == nil {
+			return ErrTwoFactorNotEnabled
+		}
+		return s.disable(ctx, requestor, user, twoFactor)
+	})
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking a
+// TOTP code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		twoFactor, err := s.getEnabled(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkTOTP(ctx, twoFactor, code); err != nil {
+			return err
+		}
+		codes, err = s.newRecoveryCodes(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Required reports whether users with role must sign in with two factors
+func (s *TwoFactorService) Required(ctx context.Context, role models.Role) (bool, error) {
+	policy, err := s.repo.GetPolicy(ctx, role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get two-factor policy: %w", err)
+	}
+	return policy.Required, nil
+}
+
+// Policies returns the two-factor policy for every role
+func (s *TwoFactorService) Policies(ctx context.Context) ([]models.TwoFactorPolicy, error) {
+	stored, err := s.repo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list two-factor policies: %w", err)
+	}
+
+	byRole := make(map[models.Role]models.TwoFactorPolicy, len(stored))
+	for _, policy := range stored {
+		byRole[policy.Role] = policy
+	}
+
+	roles := []models.Role{models.RoleAdmin, models.RoleManager, models.RoleCashier}
+	policies := make([]models.TwoFactorPolicy, len(roles))
+	for i, role := range roles {
+		policy, ok := byRole[role]
+		if !ok {
+			policy = models.TwoFactorPolicy{Role: role}
+		}
+		policies[i] = policy
+	}
+	return policies, nil
+}
+
+// SetPolicy sets whether a role must use two-factor authentication (admin
+// only). Users of the role without an authenticator are asked to enroll at
+// their next login.
+func (s *TwoFactorService) SetPolicy(ctx context.Context, requestorID uuid.UUID, req *models.UpdateTwoFactorPolicyRequest) (*models.TwoFactorPolicy, error) {
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get requestor: %w", err)
+	}
+	if requestor.Role != models.RoleAdmin {
+		return nil, ErrInsufficientRole
+	}
+
+	wasRequired, err := s.Required(ctx, req.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &models.TwoFactorPolicy{
+		Role:      req.Role,
+		Required:  *req.Required,
+		UpdatedBy: requestor.ID,
+	}
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.repo.SavePolicy(ctx, policy); err != nil {
+			return fmt.Errorf("failed to save two-factor policy: %w", err)
+		}
+
+		auditLog := newAuditLog(ctx, requestor, models.AuditActionSystemConfig, "two_factor_policy", string(req.Role),
+			map[string]interface{}{"required": wasRequired},
+			map[string]interface{}{"required": policy.Required},
+		)
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *TwoFactorService) enroll(ctx context.Context, user *models.User) (*models.TwoFactorEnrollment, error) {
+	var enrollment *models.TwoFactorEnrollment
+	err := repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		twoFactor, err := s.get(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if twoFactor == nil {
+			twoFactor = &models.TwoFactor{
+				ID:        uuid.New(),
+				UserID:    user.ID,
+				CreatedAt: time.Now(),
+			}
+		}
+		if twoFactor.Enabled {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		secret, err := auth.GenerateTOTPSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate secret: %w", err)
+		}
+		twoFactor.Secret = secret
+		if err := s.repo.Save(ctx, twoFactor); err != nil {
+			return fmt.Errorf("failed to save two-factor secret: %w", err)
+		}
+
+		enrollment = &models.TwoFactorEnrollment{
+			Secret:          secret,
+			ProvisioningURI: auth.TOTPProvisioningURI(secret, s.issuer, user.Email),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// confirmEnrollment enables a pending authenticator. It must be called
+// inside RunInTx.
+func (s *TwoFactorService) confirmEnrollment(ctx context.Context, user *models.User, code string) ([]string, error) {
+	twoFactor, err := s.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotEnrolling
+	}
+	if twoFactor.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(twoFactor.Secret, normalizeTOTPCode(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	twoFactor.LastUsedStep = step
+	if err := s.repo.Save(ctx, twoFactor); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	codes, err := s.newRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog := newAuditLog(ctx, user, models.AuditActionTwoFactorEnroll, "user", user.ID.String(),
+		map[string]interface{}{"enabled": false},
+		map[string]interface{}{"enabled": true},
+	)
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return codes, nil
+}
+
+// disable removes a user's authenticator on behalf of actor, who is the user
+// themselves or an admin
+func (s *TwoFactorService) disable(ctx context.Context, actor, user *models.User, twoFactor *models.TwoFactor) error {
+	if err := s.repo.Delete(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	auditLog := newAuditLog(ctx, actor, models.AuditActionTwoFactorDisable, "user", user.ID.String(),
+		map[string]interface{}{"enabled": twoFactor.Enabled},
+		map[string]interface{}{"enabled": false},
+	)
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// newRecoveryCodes issues a fresh set of recovery codes, invalidating any
+// left from before, and stores their hashes
+func (s *TwoFactorService) newRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(code))
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// get returns a user's authenticator, or nil if they have none
+func (s *TwoFactorService) get(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	twoFactor, err := s.repo.GetByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor authentication: %w", err)
+	}
+	return twoFactor, nil
+}
+
+func (s *TwoFactorService) getEnabled(ctx context.Context, userID uuid.UUID) (*models.TwoFactor, error) {
+	twoFactor, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+func (s *TwoFactorService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+type twoFactorFixture struct {
+	service *TwoFactorService
+	repo    *fakeTwoFactorRepo
+	audit   *fakeAuditRepo
+	admin   *models.User
+	manager *models.User
+	cashier *models.User
+}
+
+func newTwoFactorFixture() *twoFactorFixture {
+	f := &twoFactorFixture{
+		repo:    newFakeTwoFactorRepo(),
+		audit:   &fakeAuditRepo{},
+		admin:   &models.User{ID: uuid.New(), Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin, IsActive: true},
+		manager: &models.User{ID: uuid.New(), Name: "Manager", Email: "manager@example.com", Role: models.RoleManager, IsActive: true},
+		cashier: &models.User{ID: uuid.New(), Name: "Cashier", Email: "cashier@example.com", Role: models.RoleCashier, IsActive: true},
+	}
+	users := newFakeUserRepo(f.admin, f.manager, f.cashier)
+	f.service = NewTwoFactorService(f.repo, users, f.audit, nil, "POS", 5*time.Minute)
+	return f
+}
+
+// enroll sets up an authenticator for user, confirming it with the current
+// code, and returns its secret and the recovery codes
+func (f *twoFactorFixture) enroll(t *testing.T, user *models.User) (string, []string) {
+	t.Helper()
+	enrollment, err := f.service.Enroll(txContext(), user.ID)
+	if err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	codes, err := f.service.ConfirmEnrollment(txContext(), user.ID, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment failed: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+// challenge starts a login for user and returns its challenge token
+func (f *twoFactorFixture) challenge(t *testing.T, user *models.User) string {
+	t.Helper()
+	response, err := f.service.BeginLogin(txContext(), user)
+	if err != nil {
+		t.Fatalf("BeginLogin failed: %v", err)
+	}
+	if response == nil || !response.TwoFactorRequired {
+		t.Fatal("Expected a two-factor challenge")
+	}
+	return response.ChallengeToken
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret, at)
+	if err != nil {
+		t.Fatalf("Failed to compute TOTP code: %v", err)
+	}
+	return code
+}
+
+func TestTwoFactorChallengeReplay(t *testing.T) {
+	f := newTwoFactorFixture()
+	secret, recoveryCodes := f.enroll(t, f.manager)
+
+	// Codes by time step, counted from the one that confirmed enrollment
+	confirmed := f.repo.factors[f.manager.ID].LastUsedStep
+	codeAt := func(step int64) string {
+		return totpCode(t, secret, time.Unix(step*int64(auth.TOTPPeriod.Seconds()), 0))
+	}
+
+	// The code that confirmed enrollment has been used
+	enrollCode := codeAt(confirmed)
+	if _, err := f.service.CompleteLogin(txContext(), f.challenge(t, f.manager), enrollCode); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected the enrollment code to be refused at login, got %v", err)
+	}
+
+	// The next code works once
+	nextCode := codeAt(confirmed + 1)
+	token := f.challenge(t, f.manager)
+	if _, err := f.service.CompleteLogin(txContext(), token, nextCode); err != nil {
+		t.Fatalf("Expected the next code to be accepted, got %v", err)
+	}
+	if _, err := f.service.CompleteLogin(txContext(), f.challenge(t, f.manager), nextCode); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected a replayed code to be refused, got %v", err)
+	}
+
+	// A completed challenge cannot be used again, even with a fresh code
+	laterCode := codeAt(confirmed + 2)
+	if _, err := f.service.CompleteLogin(txContext(), token, laterCode); !errors.Is(err, ErrInvalidTwoFactorChallenge) {
+		t.Errorf("Expected a completed challenge to be refused, got %v", err)
+	}
+
+	// Recovery codes are single use
+	if _, err := f.service.CompleteLogin(txContext(), f.challenge(t, f.manager), recoveryCodes[0]); err != nil {
+		t.Fatalf("Expected a recovery code to be accepted, got %v", err)
+	}
+	if _, err := f.service.CompleteLogin(txContext(), f.challenge(t, f.manager), recoveryCodes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected a used recovery code to be refused, got %v", err)
+	}
+
+	// An expired challenge is refused
+	expired := f.challenge(t, f.manager)
+	f.repo.challenges[auth.HashToken(expired)].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := f.service.CompleteLogin(txContext(), expired, recoveryCodes[1]); !errors.Is(err, ErrInvalidTwoFactorChallenge) {
+		t.Errorf("Expected an expired challenge to be refused, got %v", err)
+	}
+}
+
+func TestTwoFactorRolePolicy(t *testing.T) {
+	f := newTwoFactorFixture()
+	required := true
+
+	if _, err := f.service.SetPolicy(txContext(), f.manager.ID, &models.UpdateTwoFactorPolicyRequest{Role: models.RoleCashier, Required: &required}); !errors.Is(err, ErrInsufficientRole) {
+		t.Fatalf("Expected only admins to set the policy, got %v", err)
+	}
+	if _, err := f.service.SetPolicy(txContext(), f.admin.ID, &models.UpdateTwoFactorPolicyRequest{Role: models.RoleCashier, Required: &required}); err != nil {
+		t.Fatalf("SetPolicy failed: %v", err)
+	}
+	if len(f.audit.logs) != 1 || f.audit.logs[0].NewValues["required"] != true {
+		t.Errorf("Expected the policy change to be audited, got %+v", f.audit.logs)
+	}
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		enrolled  bool
+		wantLogin bool // whether a second step is asked for
+		wantSetup bool // whether the user must enroll first
+	}{
+		{name: "required role without an authenticator", user: f.cashier, wantLogin: true, wantSetup: true},
+		{name: "optional role without an authenticator", user: f.manager},
+		{name: "optional role with an authenticator", user: f.admin, enrolled: true, wantLogin: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.enrolled {
+				f.enroll(t, tt.user)
+			}
+			response, err := f.service.BeginLogin(txContext(), tt.user)
+			if err != nil {
+				t.Fatalf("BeginLogin failed: %v", err)
+			}
+			if (response != nil) != tt.wantLogin {
+				t.Fatalf("Expected a second step %v, got %+v", tt.wantLogin, response)
+			}
+			if response != nil && response.TwoFactorSetupRequired != tt.wantSetup {
+				t.Errorf("Expected setup required %v, got %v", tt.wantSetup, response.TwoFactorSetupRequired)
+			}
+		})
+	}
+
+	// A cashier enrolling at login cannot then turn two factors off
+	token := f.challenge(t, f.cashier)
+	enrollment, err := f.service.SetupLogin(txContext(), token)
+	if err != nil {
+		t.Fatalf("SetupLogin failed: %v", err)
+	}
+	codes, err := f.service.CompleteLogin(txContext(), token, totpCode(t, enrollment.Secret, time.Now()))
+	if err != nil || len(codes) == 0 {
+		t.Fatalf("Expected enrolling at login to return recovery codes, got %v", err)
+	}
+	if err := f.service.Disable(txContext(), f.cashier.ID, codes[0]); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Errorf("Expected ErrTwoFactorRequired, got %v", err)
+	}
+	if _, ok := f.repo.factors[f.cashier.ID]; !ok {
+		t.Error("Expected the cashier's authenticator to be kept")
+	}
+
+	// Where the role allows it, two factors can be turned off
+	_, managerCodes := f.enroll(t, f.manager)
+	if err := f.service.Disable(txContext(), f.manager.ID, managerCodes[0]); err != nil {
+		t.Errorf("Expected a manager to turn off two factors, got %v", err)
+	}
+	if _, ok := f.repo.factors[f.manager.ID]; ok {
+		t.Error("Expected the manager's authenticator to be removed")
+	}
+}
@@ -2,9 +2,10 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
+	"log"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -30,17 +31,24 @@ type UserService struct {
 	sessionRepo  repository.SessionRepository
 	passwordRepo repository.PasswordRepository
 	auditRepo    repository.AuditLogRepository
+	revoker      *TokenRevoker
+	loginGuard   *LoginGuard
 	db           *gorm.DB
+	maxBulkItems int
 }
 
-// NewUserService creates a new user management service
+// NewUserService creates a new user management service. Bulk operations
+// are limited to maxBulkItems records.
 func NewUserService(
 	userRepo repository.UserRepository,
 	accountRepo repository.AccountRepository,
 	sessionRepo repository.SessionRepository,
 	passwordRepo repository.PasswordRepository,
 	auditRepo repository.AuditLogRepository,
+	revoker *TokenRevoker,
+	loginGuard *LoginGuard,
 	db *gorm.DB,
+	maxBulkItems int,
 ) *UserService {
 	return &UserService{
 		userRepo:     userRepo,
@@ -48,7 +56,10 @@ func NewUserService(
 		sessionRepo:  sessionRepo,
 		passwordRepo: passwordRepo,
 		auditRepo:    auditRepo,
+		revoker:      revoker,
+		loginGuard:   loginGuard,
 		db:           db,
+		maxBulkItems: maxBulkItems,
 	}
 }
 
@@ -90,7 +101,9 @@ func (s *UserService) UpdateUserProfile(ctx context.Context, userID uuid.UUID, r
 	}
 
 	// Log the update
-	s.logUserAction(ctx, userID, "profile_updated", fmt.Sprintf("User %s updated their profile", user.Email))
+	if err := s.logUserAction(ctx, userID, "profile_updated", userID.String(), fmt.Sprintf("User %s updated their profile", user.Email)); err != nil {
+		return nil, err
+	}
 
 	return user, nil
 }
@@ -113,7 +126,9 @@ func (s *UserService) ListUsers(ctx context.Context, requestorID uuid.UUID, filt
 	}
 
 	// Log the action
-	s.logUserAction(ctx, requestorID, "users_listed", fmt.Sprintf("Admin %s listed users", requestor.Email))
+	if err := s.logUserAction(ctx, requestorID, "users_listed", "", fmt.Sprintf("Admin %s listed users", requestor.Email)); err != nil {
+		return nil, 0, err
+	}
 
 	return users, total, nil
 }
@@ -136,14 +151,6 @@ func (s *UserService) CreateUser(ctx context.Context, requestorID uuid.UUID, req
 		return nil, ErrEmailAlreadyExists
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
 	// Create user
 	user := &models.User{
 		ID:       uuid.New(),
@@ -158,52 +165,52 @@ func (s *UserService) CreateUser(ctx context.Context, requestorID uuid.UUID, req
 		user.Role = *req.Role
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		tx.Rollback()
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
-
-	// Create password if provided
-	if req.Password != nil && *req.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
-		if err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to hash password: %w", err)
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
 		}
 
-		password := &models.Password{
-			ID:             uuid.New(),
-			UserID:         user.ID,
-			HashedPassword: string(hashedPassword),
+		// Create password if provided
+		if req.Password != nil && *req.Password != "" {
+			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("failed to hash password: %w", err)
+			}
+
+			password := &models.Password{
+				ID:             uuid.New(),
+				UserID:         user.ID,
+				HashedPassword: string(hashedPassword),
+			}
+
+			if err := s.passwordRepo.Create(ctx, password); err != nil {
+				return fmt.Errorf("failed to create password: %w", err)
+			}
+
+			// Create email account record
+			account := &models.Account{
+				ID:                uuid.New(),
+				UserID:            user.ID,
+				Type:              "email",
+				Provider:          "email",
+				ProviderAccountID: req.Email,
+			}
+
+			if err := s.accountRepo.Create(ctx, account); err != nil {
+				return fmt.Errorf("failed to create account: %w", err)
+			}
 		}
 
-		if err := s.passwordRepo.Create(ctx, password); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to create password: %w", err)
-		}
-
-		// Create email account record
-		account := &models.Account{
-			ID:                uuid.New(),
-			UserID:            user.ID,
-			Type:              "email",
-			Provider:          "email",
-			ProviderAccountID: req.Email,
-		}
-
-		if err := s.accountRepo.Create(ctx, account); err != nil {
-			tx.Rollback()
-			return nil, fmt.Errorf("failed to create account: %w", err)
-		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Log the action
-	s.logUserAction(ctx, requestorID, "user_created", fmt.Sprintf("Admin %s created user %s with role %s", requestor.Email, user.Email, user.Role))
+	if err := s.logUserAction(ctx, requestorID, "user_created", user.ID.String(), fmt.Sprintf("Admin %s created user %s with role %s", requestor.Email, user.Email, user.Role)); err != nil {
+		return nil, err
+	}
 
 	return user, nil
 }
@@ -248,18 +255,27 @@ func (s *UserService) UpdateUser(ctx context.Context, requestorID uuid.UUID, tar
 	}
 
 	// Update user if changes were made
-	if len(changes) > 0 {
+	if len(changes) == 0 {
+		return user, nil
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
 		if err := s.userRepo.Update(ctx, user); err != nil {
-			return nil, fmt.Errorf("failed to update user: %w", err)
+			return fmt.Errorf("failed to update user: %w", err)
 		}
 
-		// If user was deactivated, revoke all their sessions
-		if req.IsActive != nil && !*req.IsActive {
-			s.sessionRepo.RevokeAllUserSessions(ctx, user.ID)
+		// If user was deactivated, revoke all their sessions and tokens
+		if !user.IsActive {
+			if err := s.revokeUserAccess(ctx, user.ID); err != nil {
+				return err
+			}
 		}
 
 		// Log the action
-		s.logUserAction(ctx, requestorID, "user_updated", fmt.Sprintf("Admin %s updated user %s: %v", requestor.Email, user.Email, changes))
+		return s.logUserAction(ctx, requestorID, "user_updated", user.ID.String(), fmt.Sprintf("Admin %s updated user %s: %v", requestor.Email, user.Email, changes))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -293,237 +309,61 @@ func (s *UserService) UpdateUserRole(ctx context.Context, requestorID uuid.UUID,
 	// Get updated user for logging
 	user, _ := s.userRepo.GetByID(ctx, targetUserID)
 	if user != nil {
-		s.logUserAction(ctx, requestorID, "role_updated", fmt.Sprintf("Admin %s updated role for user %s to %s", requestor.Email, user.Email, req.Role))
-	}
-
-	return nil
-}
-
-// DeactivateUser deactivates a user account (admin only)
-func (s *UserService) DeactivateUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
-	// Verify requestor has admin permissions
-	requestor, err := s.userRepo.GetByID(ctx, requestorID)
-	if err != nil {
-		return fmt.Errorf("failed to get requestor: %w", err)
-	}
-
-	if requestor.Role != models.RoleAdmin {
-		return ErrInsufficientRole
-	}
-
-	// Get target user
-	user, err := s.userRepo.GetByID(ctx, targetUserID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrUserProfileNotFound
-		}
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Prevent deactivating admin user
-	if user.Role == models.RoleAdmin {
-		return ErrCannotDeactivateAdmin
-	}
-
-	// Deactivate user
-	if err := s.userRepo.SetActiveStatus(ctx, targetUserID, false); err != nil {
-		return fmt.Errorf("failed to deactivate user: %w", err)
-	}
-
-	// Revoke all user sessions
-	s.sessionRepo.RevokeAllUserSessions(ctx, targetUserID)
-
-	// Log the action
-	s.logUserAction(ctx, requestorID, "user_deactivated", fmt.Sprintf("Admin %s deactivated user %s", requestor.Email, user.Email))
-
-	return nil
-}
-
-// ActivateUser activates a user account (admin only)
-func (s *UserService) ActivateUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
-	// Verify requestor has admin permissions
-	requestor, err := s.userRepo.GetByID(ctx, requestorID)
-	if err != nil {
-		return fmt.Errorf("failed to get requestor: %w", err)
-	}
-
-	if requestor.Role != models.RoleAdmin {
-		return ErrInsufficientRole
-	}
-
-	// Get target user
-	user, err := s.userRepo.GetByID(ctx, targetUserID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrUserProfileNotFound
-		}
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Activate user
-	if err := s.userRepo.SetActiveStatus(ctx, targetUserID, true); err != nil {
-		return fmt.Errorf("failed to activate user: %w", err)
-	}
-
-	// Log the action
-	s.logUserAction(ctx, requestorID, "user_activated", fmt.Sprintf("Admin %s activated user %s", requestor.Email, user.Email))
-
-	return nil
-}
-
-// DeleteUser soft deletes a user account (admin only)
-func (s *UserService) DeleteUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
-	// Verify requestor has admin permissions
-	requestor, err := s.userRepo.GetByID(ctx, requestorID)
-	if err != nil {
-		return fmt.Errorf("failed to get requestor: %w", err)
-	}
-
-	if requestor.Role != models.RoleAdmin {
-		return ErrInsufficientRole
-	}
-
-	// Prevent deleting own account
-	if requestorID == targetUserID {
-		return ErrCannotDeleteOwnAccount
-	}
-
-	// Get target user
-	user, err := s.userRepo.GetByID(ctx, targetUserID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrUserProfileNotFound
+		if err := s.logUserAction(ctx, requestorID, "role_updated", user.ID.String(), fmt.Sprintf("Admin %s updated role for user %s to %s", requestor.Email, user.Email, req.Role)); err != nil {
+			return err
 		}
-		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Prevent deleting admin user
-	if user.Role == models.RoleAdmin {
-		return ErrCannotDeactivateAdmin
-	}
-
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// Revoke all user sessions
-	if err := s.sessionRepo.RevokeAllUserSessions(ctx, targetUserID); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to revoke user sessions: %w", err)
-	}
-
-	// Soft delete user
-	if err := s.userRepo.Delete(ctx, targetUserID); err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	// Log the action
-	s.logUserAction(ctx, requestorID, "user_deleted", fmt.Sprintf("Admin %s deleted user %s", requestor.Email, user.Email))
-
 	return nil
 }
 
-// GetUserSessions retrieves active sessions for a user
-func (s *UserService) GetUserSessions(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) ([]models.Session, error) {
-	// Users can view their own sessions, admins can view any user's sessions
-	if requestorID != targetUserID {
-		requestor, err := s.userRepo.GetByID(ctx, requestorID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get requestor: %w", err)
-		}
-
-		if requestor.Role != models.RoleAdmin {
-			return nil, ErrInsufficientRole
-		}
-	}
-
-	sessions, err := s.sessionRepo.GetByUserID(ctx, targetUserID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user sessions: %w", err)
-	}
-
-	return sessions, nil
-}
-
-// RevokeUserSession revokes a specific user session
-func (s *UserService) RevokeUserSession(ctx context.Context, requestorID uuid.UUID, sessionID uuid.UUID) error {
-	// Only admins can revoke specific sessions by ID
-	// Regular users should use RevokeAllUserSessions for their own sessions
+// BulkUsers creates, updates or deletes many users at once (admin only).
+// Each record goes through the single-user path, so it is validated and
+// audited the same way.
+func (s *UserService) BulkUsers(ctx context.Context, requestorID uuid.UUID, op *models.BulkOperation) (*models.BulkOperationResult, error) {
 	requestor, err := s.userRepo.GetByID(ctx, requestorID)
 	if err != nil {
-		return fmt.Errorf("failed to get requestor: %w", err)
+		return nil, fmt.Errorf("failed to get requestor: %w", err)
 	}
-
 	if requestor.Role != models.RoleAdmin {
-		return ErrInsufficientRole
-	}
-
-	// Revoke session
-	if err := s.sessionRepo.Delete(ctx, sessionID); err != nil {
-		return fmt.Errorf("failed to revoke session: %w", err)
-	}
-
-	// Log the action
-	s.logUserAction(ctx, requestorID, "session_revoked", fmt.Sprintf("Admin revoked session %s", sessionID))
-
-	return nil
-}
-
-// RevokeAllUserSessions revokes all sessions for a user
-func (s *UserService) RevokeAllUserSessions(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
-	// Users can revoke their own sessions, admins can revoke any user's sessions
-	if requestorID != targetUserID {
-		requestor, err := s.userRepo.GetByID(ctx, requestorID)
-		if err != nil {
-			return fmt.Errorf("failed to get requestor: %w", err)
-		}
-
-		if requestor.Role != models.RoleAdmin {
-			return ErrInsufficientRole
-		}
-	}
-
-	// Revoke all sessions
-	if err := s.sessionRepo.RevokeAllUserSessions(ctx, targetUserID); err != nil {
-		return fmt.Errorf("failed to revoke all user sessions: %w", err)
-	}
-
-	// Log the action
-	s.logUserAction(ctx, requestorID, "all_sessions_revoked", fmt.Sprintf("All sessions revoked for user %s", targetUserID))
-
-	return nil
-}
-
-// GetUserAccounts retrieves OAuth accounts for a user
-func (s *UserService) GetUserAccounts(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) ([]models.Account, error) {
-	// Users can view their own accounts, admins can view any user's accounts
-	if requestorID != targetUserID {
-		requestor, err := s.userRepo.GetByID(ctx, requestorID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get requestor: %w", err)
-		}
-
-		if requestor.Role != models.RoleAdmin {
-			return nil, ErrInsufficientRole
-		}
+		return nil, ErrInsufficientRole
 	}
 
-	accounts, err := s.accountRepo.GetByUserID(ctx, targetUserID)
+	items, err := planBulk(op, s.maxBulkItems, bulkActions{
+		create: func(payload json.RawMessage) bulkItem {
+			var req models.CreateUserRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{err: err}
+			}
+			if req.Role != nil && !models.ValidateRole(string(*req.Role)) {
+				return bulkItem{id: req.Email, err: fmt.Errorf("%w: invalid role", ErrInvalidBulkItem)}
+			}
+			return bulkItem{id: req.Email, apply: func(ctx context.Context) error {
+				_, err := s.CreateUser(ctx, requestorID, &req)
+				return err
+			}}
+		},
+		update: func(id uuid.UUID, payload json.RawMessage) bulkItem {
+			var req models.UpdateUserRequest
+			if err := decodeBulkPayload(payload, &req); err != nil {
+				return bulkItem{id: id.String(), err: err}
+			}
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				_, err := s.UpdateUser(ctx, requestorID, id, &req)
+				return err
+			}}
+		},
+		delete: func(id uuid.UUID) bulkItem {
+			return bulkItem{id: id.String(), apply: func(ctx context.Context) error {
+				return s.DeleteUser(ctx, requestorID, id)
+			}}
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user accounts: %w", err)
+		return nil, err
 	}
 
-	return accounts, nil
+	return runBulk(ctx, s.db, op, items)
 }
 
 // GetUserStatistics retrieves user activity statistics (admin only)
@@ -572,21 +412,26 @@ func (s *UserService) GetUserStatistics(ctx context.Context, requestorID uuid.UU
 	}
 
 	// Log the action
-	s.logUserAction(ctx, requestorID, "statistics_viewed", fmt.Sprintf("Admin %s viewed user statistics", requestor.Email))
+	if err := s.logUserAction(ctx, requestorID, "statistics_viewed", "", fmt.Sprintf("Admin %s viewed user statistics", requestor.Email)); err != nil {
+		return nil, err
+	}
 
 	return stats, nil
 }
 
-// logUserAction logs user management actions for audit trail
-func (s *UserService) logUserAction(ctx context.Context, userID uuid.UUID, actionType, description string) {
+// logUserAction records a user management action in the audit trail. Inside
+// a transaction the entry is written with the change and any error returned,
+// so the change cannot commit without it; otherwise it is written in the
+// background.
+func (s *UserService) logUserAction(ctx context.Context, userID uuid.UUID, actionType, resourceID, description string) error {
 	if s.auditRepo == nil {
-		return // Audit logging is optional
+		return nil // Audit logging is optional
 	}
 
 	// Get user info for audit log
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return // Don't fail if we can't get user info
+		return fmt.Errorf("failed to get user for audit log: %w", err)
 	}
 
 	// Map string actions to AuditLogAction constants
@@ -606,6 +451,8 @@ func (s *UserService) logUserAction(ctx context.Context, userID uuid.UUID, actio
 		action = models.AuditActionUpdateUser
 	case "user_activated":
 		action = models.AuditActionUpdateUser
+	case "user_unlocked":
+		action = models.AuditActionUpdateUser
 	case "user_deleted":
 		action = models.AuditActionDeleteUser
 	case "session_revoked":
@@ -618,22 +465,22 @@ func (s *UserService) logUserAction(ctx context.Context, userID uuid.UUID, actio
 		action = models.AuditActionSystemConfig
 	}
 
-	auditLog := &models.AuditLog{
-		ID:        uuid.New(),
-		UserID:    userID,
-		UserName:  user.Name,
-		UserRole:  user.Role,
-		Action:    action,
-		Resource:  "user_management",
-		IPAddress: "", // Will be set by middleware
-		UserAgent: "", // Will be set by middleware
-		Timestamp: time.Now(),
+	auditLog := newAuditLog(ctx, user, action, "user_management", resourceID, nil, map[string]interface{}{
+		"description": description,
+	})
+
+	if _, ok := repository.TxFromContext(ctx); ok {
+		if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+			return fmt.Errorf("failed to log audit action: %w", err)
+		}
+		return nil
 	}
 
 	// Log in background, don't fail the main operation if logging fails
 	go func() {
 		if err := s.auditRepo.Create(context.Background(), auditLog); err != nil {
-			fmt.Printf("Failed to log audit action: %v\n", err)
+			log.Printf("Failed to log audit action %s for user %s: %v", actionType, userID, err)
 		}
 	}()
+	return nil
 }
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/pkg/auth"
+)
+
+type userFixture struct {
+	service  *UserService
+	sessions *fakeSessionRepo
+	revoked  *fakeRevokedTokenRepo
+	jwt      *auth.JWTManager
+	admin    *models.User
+	cashier  *models.User
+}
+
+func newUserFixture(audit *fakeAuditRepo) *userFixture {
+	f := &userFixture{
+		sessions: &fakeSessionRepo{},
+		revoked:  &fakeRevokedTokenRepo{},
+		jwt:      auth.NewJWTManager("test-secret-key", 1, 7),
+		admin:    &models.User{ID: uuid.New(), Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin, IsActive: true},
+		cashier:  &models.User{ID: uuid.New(), Name: "Cashier", Email: "cashier@example.com", Role: models.RoleCashier, IsActive: true},
+	}
+	revoker := NewTokenRevoker(f.revoked, f.jwt)
+	f.service = NewUserService(newFakeUserRepo(f.admin, f.cashier), nil, f.sessions, nil, audit, revoker, nil, nil, 100)
+	return f
+}
+
+func TestUserActionAuditFailureFailsTransaction(t *testing.T) {
+	audit := &fakeAuditRepo{err: errFakeFailure}
+	f := newUserFixture(audit)
+
+	name := "Renamed"
+	_, err := f.service.UpdateUser(txContext(), f.admin.ID, f.cashier.ID, &models.UpdateUserRequest{Name: &name})
+	if !errors.Is(err, errFakeFailure) {
+		t.Fatalf("Expected the audit failure to fail the update, got %v", err)
+	}
+}
+
+func TestUserActionAuditRecordsClient(t *testing.T) {
+	audit := &fakeAuditRepo{}
+	f := newUserFixture(audit)
+	ctx := ContextWithClientInfo(txContext(), ClientInfo{IPAddress: "203.0.113.7", UserAgent: "till/1.0"})
+
+	name := "Renamed"
+	if _, err := f.service.UpdateUser(ctx, f.admin.ID, f.cashier.ID, &models.UpdateUserRequest{Name: &name}); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+
+	if len(audit.logs) != 1 {
+		t.Fatalf("Expected 1 audit log, got %d", len(audit.logs))
+	}
+	entry := audit.logs[0]
+	if entry.IPAddress != "203.0.113.7" || entry.UserAgent != "till/1.0" {
+		t.Errorf("Expected the request's client in the audit log, got %q and %q", entry.IPAddress, entry.UserAgent)
+	}
+	if entry.UserID != f.admin.ID || entry.Action != models.AuditActionUpdateUser {
+		t.Errorf("Expected an update by the admin, got %s by %s", entry.Action, entry.UserID)
+	}
+	if entry.ResourceID == nil || *entry.ResourceID != f.cashier.ID.String() {
+		t.Errorf("Expected resource %s, got %v", f.cashier.ID, entry.ResourceID)
+	}
+}
+
+func TestDeactivationFailsClosed(t *testing.T) {
+	inactive := false
+	deactivations := []struct {
+		name       string
+		deactivate func(f *userFixture) error
+	}{
+		{
+			name: "DeactivateUser",
+			deactivate: func(f *userFixture) error {
+				return f.service.DeactivateUser(txContext(), f.admin.ID, f.cashier.ID)
+			},
+		},
+		{
+			name: "UpdateUser",
+			deactivate: func(f *userFixture) error {
+				_, err := f.service.UpdateUser(txContext(), f.admin.ID, f.cashier.ID, &models.UpdateUserRequest{IsActive: &inactive})
+				return err
+			},
+		},
+	}
+	failures := []struct {
+		name string
+		fail func(f *userFixture, audit *fakeAuditRepo)
+	}{
+		{name: "sessions not revoked", fail: func(f *userFixture, audit *fakeAuditRepo) { f.sessions.err = errFakeFailure }},
+		{name: "tokens not revoked", fail: func(f *userFixture, audit *fakeAuditRepo) { f.revoked.err = errFakeFailure }},
+		{name: "audit not written", fail: func(f *userFixture, audit *fakeAuditRepo) { audit.err = errFakeFailure }},
+	}
+
+	for _, d := range deactivations {
+		t.Run(d.name, func(t *testing.T) {
+			f := newUserFixture(&fakeAuditRepo{})
+			issued := &auth.Claims{UserID: f.cashier.ID.String()}
+			issued.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+			if err := d.deactivate(f); err != nil {
+				t.Fatalf("Deactivation failed: %v", err)
+			}
+			if f.cashier.IsActive {
+				t.Error("Expected the user to be deactivated")
+			}
+			if len(f.sessions.revoked) != 1 || f.sessions.revoked[0] != f.cashier.ID {
+				t.Errorf("Expected the user's sessions revoked, got %v", f.sessions.revoked)
+			}
+			if len(f.revoked.revoked) != 1 || !f.jwt.Revocations().IsRevoked(issued) {
+				t.Error("Expected the user's access tokens revoked")
+			}
+
+			for _, tt := range failures {
+				t.Run(tt.name, func(t *testing.T) {
+					audit := &fakeAuditRepo{}
+					f := newUserFixture(audit)
+					tt.fail(f, audit)
+
+					if err := d.deactivate(f); !errors.Is(err, errFakeFailure) {
+						t.Errorf("Expected the failure to be returned, got %v", err)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+// GetUserSessions retrieves active sessions for a user
+func (s *UserService) GetUserSessions(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) ([]models.Session, error) {
+	// Users can view their own sessions, admins can view any user's sessions
+	if requestorID != targetUserID {
+		requestor, err := s.userRepo.GetByID(ctx, requestorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get requestor: %w", err)
+		}
+
+		if requestor.Role != models.RoleAdmin {
+			return nil, ErrInsufficientRole
+		}
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes a specific user session
+func (s *UserService) RevokeUserSession(ctx context.Context, requestorID uuid.UUID, sessionID uuid.UUID) error {
+	// Only admins can revoke specific sessions by ID
+	// Regular users should use RevokeAllUserSessions for their own sessions
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	// Revoke session
+	if err := s.sessionRepo.Delete(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	// Log the action
+	if err := s.logUserAction(ctx, requestorID, "session_revoked", "", fmt.Sprintf("Admin revoked session %s", sessionID)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeAllUserSessions revokes all sessions for a user
+func (s *UserService) RevokeAllUserSessions(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	// Users can revoke their own sessions, admins can revoke any user's sessions
+	if requestorID != targetUserID {
+		requestor, err := s.userRepo.GetByID(ctx, requestorID)
+		if err != nil {
+			return fmt.Errorf("failed to get requestor: %w", err)
+		}
+
+		if requestor.Role != models.RoleAdmin {
+			return ErrInsufficientRole
+		}
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		// Revoke all sessions and the access tokens issued with them
+		if err := s.revokeUserAccess(ctx, targetUserID); err != nil {
+			return err
+		}
+
+		// Log the action
+		return s.logUserAction(ctx, requestorID, "all_sessions_revoked", targetUserID.String(), fmt.Sprintf("All sessions revoked for user %s", targetUserID))
+	})
+}
+
+// GetUserAccounts retrieves OAuth accounts for a user
+func (s *UserService) GetUserAccounts(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) ([]models.Account, error) {
+	// Users can view their own accounts, admins can view any user's accounts
+	if requestorID != targetUserID {
+		requestor, err := s.userRepo.GetByID(ctx, requestorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get requestor: %w", err)
+		}
+
+		if requestor.Role != models.RoleAdmin {
+			return nil, ErrInsufficientRole
+		}
+	}
+
+	accounts, err := s.accountRepo.GetByUserID(ctx, targetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// revokeUserAccess signs a user out everywhere: their sessions are revoked so
+// they cannot refresh, and their access tokens are rejected from now on
+func (s *UserService) revokeUserAccess(ctx context.Context, userID uuid.UUID) error {
+	if err := s.sessionRepo.RevokeAllUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke user sessions: %w", err)
+	}
+	return s.revoker.RevokeUser(ctx, userID)
+}
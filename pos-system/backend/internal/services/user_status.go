@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/pos-system/backend/internal/models"
+	"github.com/pos-system/backend/internal/repository"
+)
+
+// DeactivateUser deactivates a user account (admin only)
+func (s *UserService) DeactivateUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	// Verify requestor has admin permissions
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	// Get target user
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserProfileNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Prevent deactivating admin user
+	if user.Role == models.RoleAdmin {
+		return ErrCannotDeactivateAdmin
+	}
+
+	return repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		// Deactivate user
+		if err := s.userRepo.SetActiveStatus(ctx, targetUserID, false); err != nil {
+			return fmt.Errorf("failed to deactivate user: %w", err)
+		}
+
+		// Revoke all user sessions and tokens
+		if err := s.revokeUserAccess(ctx, targetUserID); err != nil {
+			return err
+		}
+
+		// Log the action
+		return s.logUserAction(ctx, requestorID, "user_deactivated", user.ID.String(), fmt.Sprintf("Admin %s deactivated user %s", requestor.Email, user.Email))
+	})
+}
+
+// ActivateUser activates a user account (admin only)
+func (s *UserService) ActivateUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	// Verify requestor has admin permissions
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	// Get target user
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserProfileNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Activate user
+	if err := s.userRepo.SetActiveStatus(ctx, targetUserID, true); err != nil {
+		return fmt.Errorf("failed to activate user: %w", err)
+	}
+
+	// Log the action
+	if err := s.logUserAction(ctx, requestorID, "user_activated", user.ID.String(), fmt.Sprintf("Admin %s activated user %s", requestor.Email, user.Email)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnlockUser clears a user's failed sign-ins and any lockout (admin only)
+func (s *UserService) UnlockUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	// Verify requestor has admin permissions
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	// Get target user
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserProfileNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.loginGuard.Unlock(ctx, user.Email); err != nil {
+		return err
+	}
+
+	// Log the action
+	if err := s.logUserAction(ctx, requestorID, "user_unlocked", user.ID.String(), fmt.Sprintf("Admin %s unlocked sign-in for user %s", requestor.Email, user.Email)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUser soft deletes a user account (admin only)
+func (s *UserService) DeleteUser(ctx context.Context, requestorID uuid.UUID, targetUserID uuid.UUID) error {
+	// Verify requestor has admin permissions
+	requestor, err := s.userRepo.GetByID(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("failed to get requestor: %w", err)
+	}
+
+	if requestor.Role != models.RoleAdmin {
+		return ErrInsufficientRole
+	}
+
+	// Prevent deleting own account
+	if requestorID == targetUserID {
+		return ErrCannotDeleteOwnAccount
+	}
+
+	// Get target user
+	user, err := s.userRepo.GetByID(ctx, targetUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserProfileNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Prevent deleting admin user
+	if user.Role == models.RoleAdmin {
+		return ErrCannotDeactivateAdmin
+	}
+
+	err = repository.RunInTx(ctx, s.db, func(ctx context.Context) error {
+		// Revoke all user sessions and tokens
+		if err := s.revokeUserAccess(ctx, targetUserID); err != nil {
+			return err
+		}
+
+		// Delete user
+		if err := s.userRepo.Delete(ctx, targetUserID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Log the action
+	if err := s.logUserAction(ctx, requestorID, "user_deleted", user.ID.String(), fmt.Sprintf("Admin %s deleted user %s", requestor.Email, user.Email)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package auth
 import (
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestJWTManager(t *testing.T) {
@@ -237,3 +239,83 @@ func TestAuthManager(t *testing.T) {
 		t.Error("New refresh token should be different from old one")
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	passwordManager := NewPasswordManager(12)
+
+	token, err := passwordManager.GenerateResetToken()
+	if err != nil {
+		t.Fatalf("Failed to generate reset token: %v", err)
+	}
+
+	hash := HashToken(token)
+	if hash == token || len(hash) != 64 {
+		t.Errorf("Expected a 64 character digest, got %q", hash)
+	}
+	if HashToken(token) != hash {
+		t.Error("Hashing the same token twice should give the same digest")
+	}
+	if HashToken(token+"x") == hash {
+		t.Error("Different tokens should give different digests")
+	}
+}
+
+func TestTokenRevocation(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret-key", 1, 7)
+
+	first, _ := jwtManager.GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+	second, _ := jwtManager.GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+	other, _ := jwtManager.GenerateAccessToken("user456", "other@example.com", "CASHIER", "Other User")
+
+	claims, err := jwtManager.ValidateAccessToken(first)
+	if err != nil {
+		t.Fatalf("Failed to validate access token: %v", err)
+	}
+	jwtManager.Revocations().RevokeToken(claims.ID, claims.ExpiresAt.Time)
+
+	if _, err := jwtManager.ValidateAccessToken(first); err != ErrTokenRevoked {
+		t.Errorf("Expected ErrTokenRevoked for a revoked JTI, got %v", err)
+	}
+	if _, err := jwtManager.ValidateAccessToken(second); err != nil {
+		t.Errorf("Expected other tokens to stay valid, got %v", err)
+	}
+
+	jwtManager.Revocations().RevokeUser("user123", time.Now().Add(time.Second))
+	if _, err := jwtManager.ValidateAccessToken(second); err != ErrTokenRevoked {
+		t.Errorf("Expected ErrTokenRevoked for a token issued before the cut-off, got %v", err)
+	}
+	if _, err := jwtManager.ValidateAccessToken(other); err != nil {
+		t.Errorf("Expected other users' tokens to stay valid, got %v", err)
+	}
+
+	// Issue times are whole seconds, so a login in the same second as the
+	// cut-off gets a working token
+	cutoff := time.Date(2024, 1, 1, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+	revocations := NewRevocationList(time.Hour)
+	revocations.RevokeUser("user123", cutoff)
+
+	tests := []struct {
+		name     string
+		issuedAt *jwt.NumericDate
+		want     bool
+	}{
+		{name: "the second before the cut-off", issuedAt: jwt.NewNumericDate(cutoff.Add(-time.Second)), want: true},
+		{name: "the same second as the cut-off", issuedAt: jwt.NewNumericDate(cutoff)},
+		{name: "after the cut-off", issuedAt: jwt.NewNumericDate(cutoff.Add(2 * time.Second))},
+		{name: "no issue time", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{UserID: "user123"}
+			claims.IssuedAt = tt.issuedAt
+			if got := revocations.IsRevoked(claims); got != tt.want {
+				t.Errorf("Expected revoked %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	jwtManager.Revocations().Prune(time.Now().Add(2 * time.Hour))
+	if _, err := jwtManager.ValidateAccessToken(second); err == ErrTokenRevoked {
+		t.Error("Expected pruned entries to be forgotten")
+	}
+}
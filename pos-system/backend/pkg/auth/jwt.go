@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -21,18 +22,74 @@ type Claims struct {
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secretKey       string
+	signingKey      *Key
+	keys            map[string]*Key // verification keys by ID
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
+	revocations     *RevocationList
 }
 
-// NewJWTManager creates a new JWT manager instance
+// NewJWTManager creates a new JWT manager signing with an HS256 secret
 func NewJWTManager(secretKey string, accessTTLHours, refreshTTLDays int) *JWTManager {
+	key := NewHMACKey([]byte(secretKey))
+	return newJWTManager(key, map[string]*Key{key.ID: key}, accessTTLHours, refreshTTLDays)
+}
+
+// NewJWTManagerWithKeys creates a JWT manager signing with signingKey.
+// Tokens signed by any of verificationKeys are accepted too, so a retired
+// key stays in that list until the tokens it signed have expired. At most
+// one HMAC key can be given, for tokens issued without a kid header.
+func NewJWTManagerWithKeys(signingKey *Key, verificationKeys []*Key, accessTTLHours, refreshTTLDays int) (*JWTManager, error) {
+	if !signingKey.CanSign() {
+		return nil, errors.New("signing key has no private key")
+	}
+	keys := map[string]*Key{signingKey.ID: signingKey}
+	for _, key := range verificationKeys {
+		if _, ok := keys[key.ID]; ok {
+			if key.ID == "" {
+				return nil, errors.New("only one HMAC key can be used")
+			}
+			continue
+		}
+		keys[key.ID] = key
+	}
+	return newJWTManager(signingKey, keys, accessTTLHours, refreshTTLDays), nil
+}
+
+func newJWTManager(signingKey *Key, keys map[string]*Key, accessTTLHours, refreshTTLDays int) *JWTManager {
+	accessTokenTTL := time.Duration(accessTTLHours) * time.Hour
 	return &JWTManager{
-		secretKey:       secretKey,
-		accessTokenTTL:  time.Duration(accessTTLHours) * time.Hour,
+		signingKey:      signingKey,
+		keys:            keys,
+		accessTokenTTL:  accessTokenTTL,
 		refreshTokenTTL: time.Duration(refreshTTLDays) * 24 * time.Hour,
+		revocations:     NewRevocationList(accessTokenTTL),
+	}
+}
+
+// JWKS returns the public verification keys, signing key first. HMAC
+// secrets are never published.
+func (j *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if j.signingKey.ID != "" {
+		set.Keys = append(set.Keys, j.signingKey.JWK())
+	}
+	ids := make([]string, 0, len(j.keys))
+	for id := range j.keys {
+		if id != "" && id != j.signingKey.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		set.Keys = append(set.Keys, j.keys[id].JWK())
 	}
+	return set
+}
+
+// Revocations returns the list of access tokens rejected before expiry
+func (j *JWTManager) Revocations() *RevocationList {
+	return j.revocations
 }
 
 // GenerateAccessToken generates a new access token
@@ -59,8 +116,7 @@ func (j *JWTManager) GenerateAccessToken(userID, email, role, name string) (stri
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
 }
 
 // GenerateRefreshToken generates a new refresh token
@@ -85,18 +141,40 @@ func (j *JWTManager) GenerateRefreshToken(userID, email string) (string, error)
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secretKey))
+	return j.sign(claims)
+}
+
+// sign signs claims with the current signing key, naming it in the kid
+// header
+func (j *JWTManager) sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(j.signingKey.Method, claims)
+	if j.signingKey.ID != "" {
+		token.Header["kid"] = j.signingKey.ID
+	}
+	return token.SignedString(j.signingKey.private)
+}
+
+// verificationKey picks the key named by the token's kid header. The
+// token's algorithm must match the key so a public key can never be used
+// as an HMAC secret.
+func (j *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("invalid token signing method")
+	}
+	if !key.NotAfter.IsZero() && time.Now().After(key.NotAfter) {
+		return nil, errors.New("signing key no longer accepted")
+	}
+	return key.public, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid token signing method")
-		}
-		return []byte(j.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey)
 
 	if err != nil {
 		return nil, err
@@ -124,6 +202,10 @@ func (j *JWTManager) ValidateAccessToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("invalid token type, expected access token")
 	}
 
+	if j.revocations.IsRevoked(claims) {
+		return nil, ErrTokenRevoked
+	}
+
 	return claims, nil
 }
 
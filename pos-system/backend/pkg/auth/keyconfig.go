@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// MaxHS256Window caps how far ahead KeyConfig.AcceptHS256Until may be, so
+// the HS256 migration window cannot be left open indefinitely
+const MaxHS256Window = 90 * 24 * time.Hour
+
+var (
+	ErrDefaultSecret      = errors.New("set JWT_SECRET or JWT_SIGNING_KEY_FILE in production")
+	ErrHS256NeedsSecret   = errors.New("JWT_ACCEPT_HS256_UNTIL needs the JWT_SECRET the HS256 tokens were signed with")
+	ErrHS256WindowTooLong = fmt.Errorf("JWT_ACCEPT_HS256_UNTIL must be within %d days", int(MaxHS256Window.Hours()/24))
+	ErrInvalidHS256Until  = errors.New("JWT_ACCEPT_HS256_UNTIL must be a YYYY-MM-DD date")
+)
+
+// KeyConfig selects the keys a JWTManager signs and verifies tokens with
+type KeyConfig struct {
+	// Secret signs HS256 tokens when there is no SigningKeyFile.
+	// DefaultSecret reports that it is the development fallback, which is
+	// refused in production.
+	Secret        string
+	DefaultSecret bool
+	Production    bool
+
+	// SigningKeyFile is a PEM RSA or Ed25519 private key to sign with.
+	// VerificationKeyFiles lists retired keys whose tokens are still
+	// accepted. Tokens signed with Secret are refused unless
+	// AcceptHS256Until, a YYYY-MM-DD date, has not yet passed.
+	SigningKeyFile       string
+	VerificationKeyFiles []string
+	AcceptHS256Until     string
+
+	AccessTTLHours int
+	RefreshTTLDays int
+}
+
+// NewJWTManagerFromConfig loads the keys cfg names and creates a JWT manager
+// with them. now decides whether the HS256 migration window is still open.
+func NewJWTManagerFromConfig(cfg KeyConfig, now time.Time) (*JWTManager, error) {
+	if cfg.SigningKeyFile == "" {
+		if cfg.Production && cfg.DefaultSecret {
+			return nil, ErrDefaultSecret
+		}
+		return NewJWTManager(cfg.Secret, cfg.AccessTTLHours, cfg.RefreshTTLDays), nil
+	}
+
+	signingKey, err := LoadKeyFile(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var verificationKeys []*Key
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	hmacKey, err := hs256MigrationKey(cfg, now)
+	if err != nil {
+		return nil, err
+	}
+	if hmacKey != nil {
+		verificationKeys = append(verificationKeys, hmacKey)
+	}
+
+	log.Printf("Signing tokens with %s key %s", signingKey.Method.Alg(), signingKey.ID)
+	return NewJWTManagerWithKeys(signingKey, verificationKeys, cfg.AccessTTLHours, cfg.RefreshTTLDays)
+}
+
+// hs256MigrationKey returns the HMAC key that keeps HS256 tokens issued
+// before the switch to a signing key valid, or nil when they are refused
+func hs256MigrationKey(cfg KeyConfig, now time.Time) (*Key, error) {
+	if cfg.AcceptHS256Until == "" {
+		return nil, nil
+	}
+
+	until, err := time.Parse("2006-01-02", cfg.AcceptHS256Until)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHS256Until, err)
+	}
+	until = until.AddDate(0, 0, 1) // through the end of the day, UTC
+
+	switch {
+	case cfg.DefaultSecret:
+		return nil, ErrHS256NeedsSecret
+	case until.Sub(now) > MaxHS256Window:
+		return nil, ErrHS256WindowTooLong
+	case !now.Before(until):
+		log.Printf("JWT_ACCEPT_HS256_UNTIL %s has passed; HS256 tokens are refused and the setting can be removed", cfg.AcceptHS256Until)
+		return nil, nil
+	}
+
+	key := NewHMACKey([]byte(cfg.Secret))
+	key.NotAfter = until
+	log.Printf("WARNING: accepting HS256 tokens signed with JWT_SECRET until %s. Anyone holding the secret can mint tokens until then; unset JWT_ACCEPT_HS256_UNTIL once existing sessions have moved over", cfg.AcceptHS256Until)
+	return key, nil
+}
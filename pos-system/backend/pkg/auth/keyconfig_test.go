@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewJWTManagerFromConfig(t *testing.T) {
+	privatePEM, _ := rsaKeyPEM(t)
+	keyFile := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(keyFile, privatePEM, 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	const secret = "old-hs256-secret"
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	hs256Token, _ := NewJWTManager(secret, 1, 7).GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+
+	tests := []struct {
+		name       string
+		cfg        KeyConfig
+		wantErr    error
+		wantHS256  bool      // whether tokens signed with the secret are accepted
+		wantUntil  time.Time // when a migration window stops accepting them
+		wantFailed bool      // whether loading fails with an error not listed above
+	}{
+		{
+			name:      "secret outside production",
+			cfg:       KeyConfig{Secret: secret},
+			wantHS256: true,
+		},
+		{
+			name:      "default secret outside production",
+			cfg:       KeyConfig{Secret: secret, DefaultSecret: true},
+			wantHS256: true,
+		},
+		{
+			name:    "default secret in production",
+			cfg:     KeyConfig{Secret: secret, DefaultSecret: true, Production: true},
+			wantErr: ErrDefaultSecret,
+		},
+		{
+			name: "default secret in production with a signing key",
+			cfg:  KeyConfig{Secret: secret, DefaultSecret: true, Production: true, SigningKeyFile: keyFile},
+		},
+		{
+			name: "signing key refuses HS256 tokens",
+			cfg:  KeyConfig{Secret: secret, SigningKeyFile: keyFile},
+		},
+		{
+			name:      "HS256 window still open",
+			cfg:       KeyConfig{Secret: secret, SigningKeyFile: keyFile, AcceptHS256Until: "2024-06-30"},
+			wantHS256: true,
+			wantUntil: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "HS256 window open through the last day",
+			cfg:       KeyConfig{Secret: secret, SigningKeyFile: keyFile, AcceptHS256Until: "2024-06-01"},
+			wantHS256: true,
+			wantUntil: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "HS256 window expired",
+			cfg:  KeyConfig{Secret: secret, SigningKeyFile: keyFile, AcceptHS256Until: "2024-05-31"},
+		},
+		{
+			name:    "HS256 window too far out",
+			cfg:     KeyConfig{Secret: secret, SigningKeyFile: keyFile, AcceptHS256Until: "2024-09-01"},
+			wantErr: ErrHS256WindowTooLong,
+		},
+		{
+			name:    "HS256 window with the default secret",
+			cfg:     KeyConfig{Secret: secret, DefaultSecret: true, SigningKeyFile: keyFile, AcceptHS256Until: "2024-06-30"},
+			wantErr: ErrHS256NeedsSecret,
+		},
+		{
+			name:    "HS256 window that is not a date",
+			cfg:     KeyConfig{Secret: secret, SigningKeyFile: keyFile, AcceptHS256Until: "30/06/2024"},
+			wantErr: ErrInvalidHS256Until,
+		},
+		{
+			name:       "missing signing key file",
+			cfg:        KeyConfig{Secret: secret, SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantFailed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.AccessTTLHours = 1
+			tt.cfg.RefreshTTLDays = 7
+			jwtManager, err := NewJWTManagerFromConfig(tt.cfg, now)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.wantFailed:
+				if err == nil {
+					t.Fatal("Expected loading to fail")
+				}
+				return
+			case err != nil:
+				t.Fatalf("Failed to create JWT manager: %v", err)
+			}
+
+			// A migration window is checked against the real clock when a
+			// token is validated, so for those the HMAC key is inspected
+			// instead
+			if tt.cfg.AcceptHS256Until == "" {
+				_, err := jwtManager.ValidateAccessToken(hs256Token)
+				if accepted := err == nil; accepted != tt.wantHS256 {
+					t.Errorf("Expected HS256 tokens accepted %v, got %v (%v)", tt.wantHS256, accepted, err)
+				}
+				return
+			}
+			key, ok := jwtManager.keys[""]
+			if ok != tt.wantHS256 {
+				t.Fatalf("Expected an HS256 verification key %v, got %v", tt.wantHS256, ok)
+			}
+			if ok && !key.NotAfter.Equal(tt.wantUntil) {
+				t.Errorf("Expected the window to close at %v, got %v", tt.wantUntil, key.NotAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing keys
+const minRSABits = 2048
+
+var ErrUnsupportedKey = errors.New("unsupported key type, expected RSA or Ed25519")
+
+// Key is a key that signs or verifies tokens. Asymmetric keys are identified
+// by their RFC 7638 thumbprint, which is sent in the kid header so verifiers
+// can pick the right key during rotation. An HMAC secret has no ID.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	// NotAfter, when set, is when the key stops verifying tokens. It bounds
+	// how long a key kept only for a migration stays trusted.
+	NotAfter time.Time
+
+	private interface{} // nil for verification-only keys
+	public  interface{}
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(secret []byte) *Key {
+	return &Key{
+		Method:  jwt.SigningMethodHS256,
+		private: secret,
+		public:  secret,
+	}
+}
+
+// LoadKeyFile reads a PEM encoded key from path. See ParseKeyPEM.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParseKeyPEM parses an RSA or Ed25519 key. Private keys (PKCS#8, or PKCS#1
+// for RSA) can sign; public keys (PKIX, or PKCS#1 for RSA) only verify.
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return newAsymmetricKey(k, &k.PublicKey)
+	case ed25519.PrivateKey:
+		return newAsymmetricKey(k, k.Public())
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return newAsymmetricKey(nil, k)
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// NewSigningKey wraps an *rsa.PrivateKey or ed25519.PrivateKey
+func NewSigningKey(private crypto.Signer) (*Key, error) {
+	return newAsymmetricKey(private, private.Public())
+}
+
+func newAsymmetricKey(private, public interface{}) (*Key, error) {
+	key := &Key{private: private, public: public}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+	key.ID = thumbprint(key.JWK())
+	return key, nil
+}
+
+// CanSign reports whether the key holds private material
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// JWK is the public part of a key in JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key in JSON Web Key form. HMAC keys are secret and
+// have no public form; an empty JWK is returned for them.
+func (k *Key) JWK() JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}
+	}
+	return jwk
+}
+
+// thumbprint computes the RFC 7638 SHA-256 thumbprint of a public key. The
+// required members are written in lexicographic order with no whitespace.
+func thumbprint(jwk JWK) string {
+	var canonical string
+	switch jwk.KeyType {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Curve, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
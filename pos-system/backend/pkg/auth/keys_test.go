@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKeyPEM(t *testing.T) (private, public []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func ed25519Key(t *testing.T) *Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	key, err := NewSigningKey(private)
+	if err != nil {
+		t.Fatalf("Failed to wrap Ed25519 key: %v", err)
+	}
+	return key
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	privatePEM, publicPEM := rsaKeyPEM(t)
+
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	os.WriteFile(path, privatePEM, 0o600)
+	private, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load private key: %v", err)
+	}
+	public, err := ParseKeyPEM(publicPEM)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	if private.Method != jwt.SigningMethodRS256 || !private.CanSign() {
+		t.Errorf("Expected an RS256 signing key, got %s", private.Method.Alg())
+	}
+	if public.CanSign() {
+		t.Error("Public key should not be able to sign")
+	}
+	if private.ID == "" || private.ID != public.ID {
+		t.Errorf("Expected matching key IDs, got %q and %q", private.ID, public.ID)
+	}
+
+	if _, err := ParseKeyPEM([]byte("not a key")); err == nil {
+		t.Error("Expected an error for data without a PEM block")
+	}
+}
+
+func TestAsymmetricSigningAndRotation(t *testing.T) {
+	privatePEM, publicPEM := rsaKeyPEM(t)
+	oldKey, _ := ParseKeyPEM(privatePEM)
+	oldPublic, _ := ParseKeyPEM(publicPEM)
+
+	oldManager, err := NewJWTManagerWithKeys(oldKey, nil, 1, 7)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	oldToken, _ := oldManager.GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+
+	parsed, _, _ := new(jwt.Parser).ParseUnverified(oldToken, &Claims{})
+	if parsed.Header["kid"] != oldKey.ID || parsed.Method.Alg() != "RS256" {
+		t.Errorf("Expected RS256 with kid %s, got %v", oldKey.ID, parsed.Header)
+	}
+
+	// Rotate to a new Ed25519 key, keeping the old public key for verification
+	newKey := ed25519Key(t)
+	manager, err := NewJWTManagerWithKeys(newKey, []*Key{oldPublic}, 1, 7)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if _, err := manager.ValidateAccessToken(oldToken); err != nil {
+		t.Errorf("Expected tokens signed by the retired key to validate, got %v", err)
+	}
+	newToken, _ := manager.GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+	if _, err := manager.ValidateAccessToken(newToken); err != nil {
+		t.Errorf("Expected EdDSA token to validate, got %v", err)
+	}
+
+	// Once the old key is dropped its tokens are rejected
+	retired, _ := NewJWTManagerWithKeys(newKey, nil, 1, 7)
+	if _, err := retired.ValidateAccessToken(oldToken); err == nil {
+		t.Error("Expected tokens signed by a dropped key to be rejected")
+	}
+
+	// HS256 tokens are rejected unless an HMAC key is configured
+	hmacToken, _ := NewJWTManager("secret", 1, 7).GenerateAccessToken("user123", "test@example.com", "CASHIER", "Test User")
+	if _, err := manager.ValidateAccessToken(hmacToken); err == nil {
+		t.Error("Expected HS256 tokens to be rejected")
+	}
+	migrating, _ := NewJWTManagerWithKeys(newKey, []*Key{NewHMACKey([]byte("secret"))}, 1, 7)
+	if _, err := migrating.ValidateAccessToken(hmacToken); err != nil {
+		t.Errorf("Expected HS256 tokens to validate with the HMAC key configured, got %v", err)
+	}
+	lapsed := NewHMACKey([]byte("secret"))
+	lapsed.NotAfter = time.Now().Add(-time.Minute)
+	ended, _ := NewJWTManagerWithKeys(newKey, []*Key{lapsed}, 1, 7)
+	if _, err := ended.ValidateAccessToken(hmacToken); err == nil {
+		t.Error("Expected HS256 tokens to be rejected once the HMAC key's NotAfter has passed")
+	}
+
+	if _, err := NewJWTManagerWithKeys(oldPublic, nil, 1, 7); err == nil {
+		t.Error("Expected an error when signing with a public key")
+	}
+}
+
+func TestAlgorithmMismatchRejected(t *testing.T) {
+	privatePEM, publicPEM := rsaKeyPEM(t)
+	key, _ := ParseKeyPEM(privatePEM)
+	manager, _ := NewJWTManagerWithKeys(key, nil, 1, 7)
+
+	// An HS256 token using the public key as the secret, naming the RSA key
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: "user123", TokenType: "access"})
+	token.Header["kid"] = key.ID
+	forged, _ := token.SignedString(publicPEM)
+	if _, err := manager.ValidateAccessToken(forged); err == nil {
+		t.Error("Expected a token with a mismatched algorithm to be rejected")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	privatePEM, publicPEM := rsaKeyPEM(t)
+	oldPublic, _ := ParseKeyPEM(publicPEM)
+	oldKey, _ := ParseKeyPEM(privatePEM)
+	newKey := ed25519Key(t)
+
+	manager, _ := NewJWTManagerWithKeys(newKey, []*Key{oldPublic, NewHMACKey([]byte("secret"))}, 1, 7)
+	set := manager.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("Expected 2 published keys, got %d", len(set.Keys))
+	}
+	if set.Keys[0].KeyID != newKey.ID || set.Keys[0].KeyType != "OKP" || set.Keys[0].Algorithm != "EdDSA" {
+		t.Errorf("Expected the signing key first, got %+v", set.Keys[0])
+	}
+	if set.Keys[1].KeyID != oldKey.ID || set.Keys[1].KeyType != "RSA" || set.Keys[1].E != "AQAB" {
+		t.Errorf("Unexpected RSA key %+v", set.Keys[1])
+	}
+
+	if keys := NewJWTManager("secret", 1, 7).JWKS().Keys; len(keys) != 0 {
+		t.Errorf("HMAC secrets must not be published, got %+v", keys)
+	}
+}
+
+func TestThumbprint(t *testing.T) {
+	// Example from RFC 8037 appendix A.3
+	jwk := JWK{KeyType: "OKP", Curve: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	if id := thumbprint(jwk); id != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Errorf("Unexpected thumbprint %s", id)
+	}
+}
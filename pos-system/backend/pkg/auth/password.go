@@ -2,6 +2,7 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"regexp"
@@ -133,6 +134,13 @@ func (pm *PasswordManager) GenerateEmailVerificationToken() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// HashToken returns the SHA-256 digest of a one-time token in hex. Only the
+// digest is stored so a leaked database does not expose usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // Helper function to generate cryptographically secure random integers
 func randInt(max int) int {
 	if max <= 0 {
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238, "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC vectors are eight digits; six-digit codes are their last six
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Failed to compute code: %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("At %d expected %s, got %s", tt.unix, tt.code, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("Expected a 32 character base32 secret, got %q", secret)
+	}
+
+	now := time.Unix(1700000000, 0)
+	previous, _ := TOTPCode(secret, now.Add(-TOTPPeriod))
+
+	step, ok := ValidateTOTP(secret, previous, now, 1)
+	if !ok || step != TOTPStep(now)-1 {
+		t.Errorf("Expected the previous step's code to match within skew, got step %d ok %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, previous, now, 0); ok {
+		t.Error("Expected the previous step's code to be rejected without skew")
+	}
+
+	old, _ := TOTPCode(secret, now.Add(-5*TOTPPeriod))
+	if _, ok := ValidateTOTP(secret, old, now, 1); ok {
+		t.Error("Expected an old code to be rejected")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 1); ok {
+		t.Error("Expected a short code to be rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", "123456", now, 1); ok {
+		t.Error("Expected an invalid secret to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("JBSWY3DPEHPK3PXP", "My Store", "jane@example.com")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/My%20Store:jane@example.com?") {
+		t.Errorf("Unexpected label in %s", uri)
+	}
+	for _, param := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=My%20Store", "digits=6", "period=30"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("Expected %s in %s", param, uri)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("Failed to generate recovery codes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("Expected %d codes, got %d", RecoveryCodeCount, len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("Expected a code of the form xxxxx-xxxxx, got %q", code)
+		}
+		if seen[code] {
+			t.Errorf("Duplicate recovery code %q", code)
+		}
+		seen[code] = true
+	}
+
+	if got := NormalizeRecoveryCode(" ABCDE-fghij "); got != "abcdefghij" {
+		t.Errorf("Expected normalised code abcdefghij, got %q", got)
+	}
+}
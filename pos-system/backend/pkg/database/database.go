@@ -32,6 +32,7 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 		Logger:                 gormLogger,
 		SkipDefaultTransaction: false,
 		PrepareStmt:            true,
+		TranslateError:         true, // surface unique/FK violations as gorm.ErrDuplicatedKey etc.
 	}
 
 	// Connect to PostgreSQL
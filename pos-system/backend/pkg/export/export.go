@@ -0,0 +1,182 @@
+// Package export writes tabular records as CSV, XLSX or JSON files and reads
+// CSV and XLSX files back for imports. Rows are written as they arrive so
+// large exports never sit in memory.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatJSON Format = "json"
+)
+
+// ErrUnknownFormat is returned for formats other than csv, xlsx and json
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// IsValid reports whether f is a supported format
+func (f Format) IsValid() bool {
+	switch f {
+	case FormatCSV, FormatXLSX, FormatJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentType returns the MIME type of files in format f
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Writer writes one record per call with values in column order. Close must
+// be called to finish the file.
+type Writer interface {
+	Write(values []interface{}) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format that writes to w with the given
+// column names. Closing the Writer does not close w.
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatJSON:
+		return &jsonWriter{w: w, columns: columns}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w)}
+	if err := writer.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (c *csvWriter) Write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatValue(value)
+		if _, isString := value.(string); isString {
+			record[i] = escapeFormula(record[i])
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula stops spreadsheet applications from evaluating text that
+// looks like a formula when a CSV file is opened
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// jsonWriter writes an array of objects whose keys follow column order
+type jsonWriter struct {
+	w       io.Writer
+	columns []string
+	rows    int
+}
+
+func (j *jsonWriter) Write(values []interface{}) error {
+	var b strings.Builder
+	if j.rows == 0 {
+		b.WriteString("[\n")
+	} else {
+		b.WriteString(",\n")
+	}
+	b.WriteByte('{')
+	for i, column := range j.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		b.Write(key)
+		b.WriteByte(':')
+
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", column, err)
+		}
+		b.Write(encoded)
+	}
+	b.WriteByte('}')
+
+	j.rows++
+	_, err := io.WriteString(j.w, b.String())
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if j.rows == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// formatValue renders a value as text for CSV and spreadsheet cells
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,190 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAll(t *testing.T, format Format, columns []string, rows ...[]interface{}) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf, columns)
+	if err != nil {
+		t.Fatalf("Failed to create %s writer: %v", format, err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("Failed to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	data := writeAll(t, FormatCSV, []string{"name", "price", "created"},
+		[]interface{}{"Coffee, large", 3.5, created},
+		[]interface{}{"=SUM(A1)", -2.0, nil},
+	)
+
+	expected := "name,price,created\n" +
+		"\"Coffee, large\",3.5,2024-03-01T09:30:00Z\n" +
+		"'=SUM(A1),-2,\n"
+	if string(data) != expected {
+		t.Errorf("Expected CSV %q, got %q", expected, data)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	data := writeAll(t, FormatJSON, []string{"sku", "stock"},
+		[]interface{}{"A-1", 5},
+		[]interface{}{"B-2", nil},
+	)
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, data)
+	}
+	if len(rows) != 2 || rows[0]["sku"] != "A-1" || rows[0]["stock"] != float64(5) || rows[1]["stock"] != nil {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+	if !strings.HasPrefix(string(data), `[`+"\n"+`{"sku":"A-1","stock":5}`) {
+		t.Errorf("Expected keys in column order, got %s", data)
+	}
+
+	// Test empty export
+	empty := writeAll(t, FormatJSON, []string{"sku"})
+	if strings.TrimSpace(string(empty)) != "[]" {
+		t.Errorf("Expected empty array, got %q", empty)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	data := writeAll(t, FormatXLSX, []string{"name", "qty", "active"},
+		[]interface{}{"Tea & <Biscuits>", 12, true},
+	)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		parts[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("Expected part %s in workbook", name)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, fragment := range []string{
+		`<row r="1">`,
+		`<t xml:space="preserve">Tea &amp; &lt;Biscuits&gt;</t>`,
+		`<c><v>12</v></c>`,
+		`<c t="b"><v>1</v></c>`,
+	} {
+		if !strings.Contains(sheet, fragment) {
+			t.Errorf("Expected sheet to contain %s, got %s", fragment, sheet)
+		}
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, nil); err != ErrUnknownFormat {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestReadAllCSV(t *testing.T) {
+	data := []byte("\ufeffsku,name,price\nA-1,\"Coffee, large\",3.5\nB-2,Tea\n")
+
+	rows, err := ReadAll(FormatCSV, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "sku" {
+		t.Errorf("Expected byte order mark to be stripped, got %q", rows[0][0])
+	}
+	if rows[1][1] != "Coffee, large" || len(rows[2]) != 2 {
+		t.Errorf("Unexpected rows: %q", rows)
+	}
+}
+
+func TestReadAllXLSXRoundTrip(t *testing.T) {
+	data := writeAll(t, FormatXLSX, []string{"sku", "qty", "active"},
+		[]interface{}{"Tea & <Biscuits>", 12, true},
+	)
+
+	rows, err := ReadAll(FormatXLSX, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to read XLSX: %v", err)
+	}
+	expected := [][]string{{"sku", "qty", "active"}, {"Tea & <Biscuits>", "12", "true"}}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %q", len(expected), rows)
+	}
+	for i := range expected {
+		if strings.Join(rows[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Row %d: expected %q, got %q", i, expected[i], rows[i])
+		}
+	}
+}
+
+func TestReadAllXLSXSharedStrings(t *testing.T) {
+	// Spreadsheet applications store text in a shared table and leave empty
+	// cells and rows out of the sheet
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>sku</t></si><si><t>name</t></si><si><r><t>Green </t></r><r><t>Tea</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="A3"><v>42</v></c><c r="C3" t="s"><v>2</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		w, _ := archive.Create(name)
+		io.WriteString(w, content)
+	}
+	archive.Close()
+
+	rows, err := ReadAll(FormatXLSX, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read XLSX: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows including the empty one, got %q", rows)
+	}
+	if strings.Join(rows[0], "|") != "sku||name" {
+		t.Errorf("Expected header with a gap, got %q", rows[0])
+	}
+	if len(rows[1]) != 0 {
+		t.Errorf("Expected empty second row, got %q", rows[1])
+	}
+	if strings.Join(rows[2], "|") != "42||Green Tea" {
+		t.Errorf("Expected rich text to be flattened, got %q", rows[2])
+	}
+}
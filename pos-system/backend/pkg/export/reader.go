@@ -0,0 +1,261 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoWorksheet is returned for XLSX files without a worksheet
+var ErrNoWorksheet = errors.New("workbook has no worksheet")
+
+// ReadAll reads every row of a CSV or XLSX file as text. Rows may have
+// different lengths; missing trailing cells are simply absent. Only the
+// first worksheet of a workbook is read.
+func ReadAll(format Format, r io.ReaderAt, size int64) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(io.NewSectionReader(r, 0, size))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff") // Excel's UTF-8 byte order mark
+		}
+		return rows, nil
+	case FormatXLSX:
+		return readXLSX(r, size)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func readXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	var sheets []string
+	for _, file := range archive.File {
+		files[file.Name] = file
+		if strings.HasPrefix(file.Name, "xl/worksheets/") && path.Ext(file.Name) == ".xml" {
+			sheets = append(sheets, file.Name)
+		}
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if sheet == nil {
+		if len(sheets) == 0 {
+			return nil, ErrNoWorksheet
+		}
+		sort.Strings(sheets)
+		sheet = files[sheets[0]]
+	}
+
+	var shared []string
+	if file := files["xl/sharedStrings.xml"]; file != nil {
+		if shared, err = readSharedStrings(file); err != nil {
+			return nil, fmt.Errorf("failed to read shared strings: %w", err)
+		}
+	}
+
+	rows, err := readSheet(sheet, shared)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worksheet: %w", err)
+	}
+	return rows, nil
+}
+
+// readSharedStrings returns the workbook's shared string table. Rich text
+// entries are flattened to their plain text.
+func readSharedStrings(file *zip.File) ([]string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var strs []string
+	var current strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return strs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// Phonetic hints are not part of the cell text
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+// xlsxCell is a worksheet cell as stored in the sheet XML
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:",innerxml"`
+	} `xml:"is"`
+}
+
+func readSheet(file *zip.File, shared []string) ([][]string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	var row []string
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				// Empty rows are left out of the XML, so pad to the row number
+				if number, err := strconv.Atoi(attr(t, "r")); err == nil {
+					for len(rows) < number-1 {
+						rows = append(rows, nil)
+					}
+				}
+				row = nil
+			case "c":
+				var cell xlsxCell
+				if err := decoder.DecodeElement(&cell, &t); err != nil {
+					return nil, err
+				}
+				value, err := cellValue(&cell, shared)
+				if err != nil {
+					return nil, err
+				}
+				// Empty cells are left out too, so place by column letter
+				column := len(row)
+				if index, ok := columnIndex(cell.Ref); ok {
+					column = index
+				}
+				for len(row) < column {
+					row = append(row, "")
+				}
+				row = append(row, value)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "row" {
+				rows = append(rows, row)
+			}
+		}
+	}
+}
+
+func cellValue(cell *xlsxCell, shared []string) (string, error) {
+	switch cell.Type {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || index < 0 || index >= len(shared) {
+			return "", fmt.Errorf("invalid shared string reference %q", cell.Value)
+		}
+		return shared[index], nil
+	case "inlineStr":
+		return innerText(cell.Inline.Text)
+	case "b":
+		if cell.Value == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		return cell.Value, nil
+	}
+}
+
+// innerText returns the text content of an XML fragment
+func innerText(fragment string) (string, error) {
+	var b strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader(fragment))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if data, ok := token.(xml.CharData); ok {
+			b.Write(data)
+		}
+	}
+}
+
+// columnIndex converts the letters of a cell reference such as "C7" to a
+// zero-based column index
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		index = index*26 + int(ch-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return 0, false
+	}
+	return index - 1, true
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
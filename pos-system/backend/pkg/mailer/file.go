@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer stands in for a mail server during development. Each message
+// is written to its own .eml file, which most mail clients can open, and
+// logged.
+type FileMailer struct {
+	dir  string
+	from mail.Address
+}
+
+// NewFileMailer creates a mailer writing into dir. The directory is created
+// on first send.
+func NewFileMailer(dir, fromName, fromEmail string) *FileMailer {
+	return &FileMailer{
+		dir:  dir,
+		from: mail.Address{Name: fromName, Address: fromEmail},
+	}
+}
+
+// Send writes msg to a new file in the mailer's directory
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := build(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	path := filepath.Join(m.dir, fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405"), randomHex(4)))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	log.Printf("mail to %s (%q) written to %s", strings.Join(msg.recipients(), ", "), msg.Subject, path)
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package mailer sends email through SMTP, or for development writes each
+// message to a directory instead.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ErrNoRecipients is returned for messages without any recipient
+var ErrNoRecipients = errors.New("message has no recipients")
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is an email with an HTML body, a plain text body or both. When
+// both are set they are sent as alternatives.
+type Message struct {
+	To      []string
+	CC      []string
+	BCC     []string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// recipients returns every address the message is delivered to
+func (m *Message) recipients() []string {
+	var all []string
+	all = append(all, m.To...)
+	all = append(all, m.CC...)
+	all = append(all, m.BCC...)
+	return all
+}
+
+// PermanentError marks a failure that will not succeed on retry, such as a
+// rejected recipient
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err is a failure that should not be retried
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// build composes msg as an RFC 5322 message from the given sender. Bcc
+// recipients are left out of the headers.
+func build(from mail.Address, msg *Message, now time.Time) ([]byte, error) {
+	if len(msg.To)+len(msg.CC)+len(msg.BCC) == 0 {
+		return nil, ErrNoRecipients
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	if len(msg.To) > 0 {
+		header("To", strings.Join(msg.To, ", "))
+	}
+	if len(msg.CC) > 0 {
+		header("Cc", strings.Join(msg.CC, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+
+	switch {
+	case msg.HTML != "" && msg.Text != "":
+		boundary := randomHex(16)
+		header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+		buf.WriteString("\r\n")
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			fmt.Fprintf(&buf, "--%s\r\n", boundary)
+			if err := writePart(&buf, part.contentType, part.body); err != nil {
+				return nil, err
+			}
+		}
+		fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	case msg.HTML != "":
+		if err := writePart(&buf, "text/html; charset=utf-8", msg.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		if err := writePart(&buf, "text/plain; charset=utf-8", msg.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writePart writes the content headers and quoted-printable body of a part.
+// The encoder turns line breaks into CRLF.
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	fmt.Fprintf(buf, "Content-Type: %s\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", contentType)
+
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", randomHex(12), domain)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
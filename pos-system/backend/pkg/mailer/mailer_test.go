@@ -0,0 +1,197 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFrom = mail.Address{Name: "Cashly Store", Address: "noreply@example.com"}
+
+func TestBuildAlternative(t *testing.T) {
+	msg := &Message{
+		To:      []string{"customer@example.com"},
+		BCC:     []string{"audit@example.com"},
+		Subject: "Your receipt — RCP-1",
+		HTML:    "<p>Thanks!</p>",
+		Text:    "Thanks!\nSee you soon",
+	}
+	data, err := build(testFrom, msg, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to build message: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	if parsed.Header.Get("Bcc") != "" || bytes.Contains(data, []byte("audit@example.com")) {
+		t.Error("Bcc recipients must not appear in the message")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != msg.Subject {
+		t.Errorf("Expected subject %q, got %q", msg.Subject, subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q", parsed.Header.Get("Content-Type"))
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		// multipart decodes quoted-printable parts itself
+		body, _ := io.ReadAll(part)
+		bodies = append(bodies, string(body))
+	}
+	expected := []string{"Thanks!\r\nSee you soon", "<p>Thanks!</p>"}
+	if len(bodies) != 2 || strings.TrimSpace(bodies[0]) != expected[0] || strings.TrimSpace(bodies[1]) != expected[1] {
+		t.Errorf("Expected parts %q, got %q", expected, bodies)
+	}
+}
+
+func TestBuildWithoutRecipients(t *testing.T) {
+	if _, err := build(testFrom, &Message{Subject: "x", Text: "x"}, time.Now()); err != ErrNoRecipients {
+		t.Errorf("Expected ErrNoRecipients, got %v", err)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(filepath.Join(dir, "outbox"), testFrom.Name, testFrom.Address)
+
+	if err := m.Send(context.Background(), &Message{To: []string{"a@example.com"}, Subject: "Hello", Text: "Hi"}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "outbox", "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Expected one message file, got %d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	if !bytes.Contains(data, []byte("To: a@example.com")) {
+		t.Errorf("Unexpected message:\n%s", data)
+	}
+}
+
+// fakeSMTP accepts one session, rejecting RCPT for addresses in reject, and
+// returns the DATA it received
+func fakeSMTP(t *testing.T, reject string) (int, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-fake")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(command, "RCPT") && reject != "" && strings.Contains(command, strings.ToUpper(reject)):
+				reply("550 no such user")
+			case strings.HasPrefix(command, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(command, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	port, received := fakeSMTP(t, "")
+	m := NewSMTPMailer("127.0.0.1", port, "", "", testFrom.Name, testFrom.Address)
+
+	err := m.Send(context.Background(), &Message{To: []string{"customer@example.com"}, Subject: "Receipt", Text: "Total 10.00"})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	data := <-received
+	parsed, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse delivered message: %v", err)
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if parsed.Header.Get("To") != "customer@example.com" || strings.TrimSpace(string(body)) != "Total 10.00" {
+		t.Errorf("Unexpected message delivered:\n%s", data)
+	}
+}
+
+func TestSMTPMailerPermanentFailure(t *testing.T) {
+	port, _ := fakeSMTP(t, "gone@example.com")
+	m := NewSMTPMailer("127.0.0.1", port, "", "", testFrom.Name, testFrom.Address)
+
+	err := m.Send(context.Background(), &Message{To: []string{"gone@example.com"}, Subject: "Receipt", Text: "x"})
+	if err == nil || !IsPermanent(err) {
+		t.Errorf("Expected a permanent error, got %v", err)
+	}
+}
+
+func TestSMTPMailerUnreachable(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	m := NewSMTPMailer("127.0.0.1", port, "", "", testFrom.Name, testFrom.Address)
+	err := m.Send(context.Background(), &Message{To: []string{"a@example.com"}, Subject: "x", Text: "x"})
+	if err == nil || IsPermanent(err) {
+		t.Errorf("Expected a retryable error, got %v", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Errorf("Expected the connection error to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many takes pass between sweeps for idle buckets
+const sweepEvery = 1024
+
+type memoryBucket struct {
+	state State
+	limit Limit
+}
+
+// MemoryStore keeps buckets in process memory. Buckets that have refilled
+// completely are dropped, since a missing bucket is full.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket for key
+func (m *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.takes++
+	if m.takes%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{}
+		m.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	return Take(&bucket.state, limit, now), nil
+}
+
+// sweep drops buckets that would be full by now
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, bucket := range m.buckets {
+		if now.Sub(bucket.state.Updated) >= bucket.limit.Window {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets held
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.buckets)
+}
@@ -0,0 +1,74 @@
+// Package ratelimit implements token bucket rate limiting with pluggable
+// storage for the buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit allows Requests requests per Window. Buckets hold up to Requests
+// tokens and refill evenly over Window, so short bursts are allowed while
+// the long-run rate is capped.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// rate is the refill rate in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Window.Seconds()
+}
+
+// Result describes the outcome of taking a token
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request is allowed, when denied
+}
+
+// Store keeps token buckets by key. MemoryStore suits a single instance;
+// when several instances share the load, a store backed by a shared
+// database such as Redis makes the limits apply across all of them.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// State is the stored state of one bucket. The zero State is a full bucket.
+type State struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills the bucket in s for the time passed since it was last
+// updated and takes one token if there is one. Stores only need to load and
+// save State atomically around it.
+func Take(s *State, limit Limit, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	rate := limit.rate()
+
+	if s.Updated.IsZero() {
+		s.Tokens = capacity
+	} else if elapsed := now.Sub(s.Updated).Seconds(); elapsed > 0 {
+		s.Tokens = math.Min(capacity, s.Tokens+elapsed*rate)
+	}
+	s.Updated = now
+
+	result := Result{Limit: limit.Requests}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - s.Tokens) / rate)
+	}
+	result.Remaining = int(s.Tokens)
+	result.Reset = seconds((capacity - s.Tokens) / rate)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
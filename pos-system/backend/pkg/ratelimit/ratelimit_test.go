@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTakeBurstThenRefill(t *testing.T) {
+	limit := Limit{Requests: 3, Window: 3 * time.Second}
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	var state State
+
+	for i := 2; i >= 0; i-- {
+		result := Take(&state, limit, now)
+		if !result.Allowed || result.Remaining != i {
+			t.Fatalf("Expected request allowed with %d remaining, got %+v", i, result)
+		}
+	}
+
+	result := Take(&state, limit, now)
+	if result.Allowed {
+		t.Fatal("Expected request to be denied once the bucket is empty")
+	}
+	if result.RetryAfter != time.Second || result.Reset != 3*time.Second {
+		t.Errorf("Expected retry after 1s and reset after 3s, got %s and %s", result.RetryAfter, result.Reset)
+	}
+
+	// One token refills every second
+	if result := Take(&state, limit, now.Add(time.Second)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Expected one request allowed after a second, got %+v", result)
+	}
+
+	// Refills never exceed the capacity
+	if result := Take(&state, limit, now.Add(time.Hour)); result.Remaining != 2 {
+		t.Errorf("Expected a full bucket after a long pause, got %+v", result)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := store.Take(ctx, "ip:10.0.0.1", limit); !result.Allowed {
+		t.Fatal("Expected first request to be allowed")
+	}
+	if result, _ := store.Take(ctx, "ip:10.0.0.1", limit); result.Allowed {
+		t.Error("Expected second request from the same key to be denied")
+	}
+	if result, _ := store.Take(ctx, "ip:10.0.0.2", limit); !result.Allowed {
+		t.Error("Expected another key to have its own bucket")
+	}
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Window: time.Minute}
+
+	for i := 0; i < sweepEvery-1; i++ {
+		store.Take(context.Background(), fmt.Sprintf("user:%d", i), limit)
+	}
+	now = now.Add(time.Minute)
+	store.Take(context.Background(), "user:new", limit)
+
+	if n := store.Len(); n != 1 {
+		t.Errorf("Expected idle buckets to be swept, %d left", n)
+	}
+}
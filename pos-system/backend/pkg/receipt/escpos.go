@@ -0,0 +1,102 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ESC/POS commands
+var (
+	escInit        = []byte{0x1B, 0x40}             // ESC @
+	escCodePage437 = []byte{0x1B, 0x74, 0x00}       // ESC t 0
+	escFeedAndCut  = []byte{0x1D, 0x56, 0x42, 0x03} // GS V 66 n: feed n lines, partial cut
+)
+
+// printableWidths are the print widths in dots at 203 dpi
+var printableWidths = map[Paper]int{
+	Paper58mm: 384,
+	Paper80mm: 576,
+}
+
+// renderESCPOS produces a byte stream for ESC/POS thermal printers. Text is
+// sent in code page 437 with characters outside ASCII replaced by '?', and
+// QR codes are sent as raster images so printers without native QR support
+// can print them.
+func renderESCPOS(lines []line, paper Paper) []byte {
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	buf.Write(escCodePage437)
+
+	for _, l := range lines {
+		align := byte(0)
+		if l.style&styleCenter != 0 {
+			align = 1
+		}
+		buf.Write([]byte{0x1B, 0x61, align}) // ESC a n
+
+		if l.qr != nil {
+			writeRaster(&buf, l.qr, paper)
+			continue
+		}
+
+		bold := byte(0)
+		if l.style&styleBold != 0 {
+			bold = 1
+		}
+		size := byte(0)
+		if l.style&styleTall != 0 {
+			size = 0x01
+		}
+		buf.Write([]byte{0x1B, 0x45, bold}) // ESC E n
+		buf.Write([]byte{0x1D, 0x21, size}) // GS ! n
+		buf.WriteString(asciiOnly(l.text))
+		buf.WriteByte('\n')
+	}
+
+	buf.Write([]byte{0x1B, 0x45, 0x00, 0x1D, 0x21, 0x00, 0x1B, 0x61, 0x00})
+	buf.Write(escFeedAndCut)
+	return buf.Bytes()
+}
+
+// writeRaster prints a QR code with GS v 0, scaling each module to a square
+// of dots so the code, with its quiet zone, fills about half the paper
+func writeRaster(buf *bytes.Buffer, code *QRCode, paper Paper) {
+	const quiet = 4
+	modules := code.Size + 2*quiet
+	scale := printableWidths[paper] / 2 / modules
+	if scale < 1 {
+		scale = 1
+	}
+	if scale > 8 {
+		scale = 8
+	}
+
+	dots := modules * scale
+	rowBytes := (dots + 7) / 8
+	buf.Write([]byte{0x1D, 0x76, 0x30, 0x00, byte(rowBytes), byte(rowBytes >> 8), byte(dots), byte(dots >> 8)})
+
+	row := make([]byte, rowBytes)
+	for y := 0; y < dots; y++ {
+		for i := range row {
+			row[i] = 0
+		}
+		my := y/scale - quiet
+		for x := 0; x < dots; x++ {
+			mx := x/scale - quiet
+			if mx >= 0 && mx < code.Size && my >= 0 && my < code.Size && code.Modules[my][mx] {
+				row[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		buf.Write(row)
+	}
+	buf.WriteByte('\n')
+}
+
+func asciiOnly(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return '?'
+		}
+		return r
+	}, text)
+}
@@ -0,0 +1,65 @@
+package receipt
+
+import (
+	"bytes"
+	"html"
+)
+
+// htmlPre opens the monospace block holding the receipt text
+const htmlPre = `<pre style="margin:0;font-family:'Courier New',Courier,monospace;font-size:13px;line-height:1.4;white-space:pre">`
+
+// renderHTML produces a self-contained HTML page suitable for email. The
+// layout is kept in a monospace block so columns line up as on paper. The QR
+// code is drawn as a table of cells because most email clients block images
+// and strip SVG.
+func renderHTML(lines []line, columns int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"></head>` +
+		`<body style="margin:0;padding:16px;background:#f4f4f4">` +
+		`<div style="max-width:480px;margin:0 auto;padding:16px;background:#ffffff">` + htmlPre)
+
+	for _, l := range lines {
+		if l.qr != nil {
+			buf.WriteString("</pre>")
+			writeHTMLQR(&buf, l.qr)
+			buf.WriteString(htmlPre)
+			continue
+		}
+
+		text := l.text
+		if l.style&styleCenter != 0 {
+			text = centerText(text, columns)
+		}
+		text = html.EscapeString(text)
+		switch {
+		case l.style&styleTall != 0:
+			buf.WriteString(`<span style="font-weight:bold;font-size:16px">` + text + "</span>")
+		case l.style&styleBold != 0:
+			buf.WriteString("<b>" + text + "</b>")
+		default:
+			buf.WriteString(text)
+		}
+		buf.WriteByte('\n')
+	}
+
+	buf.WriteString("</pre></div></body></html>\n")
+	return buf.Bytes()
+}
+
+// writeHTMLQR writes code as a centred table of 4px cells with a quiet zone
+func writeHTMLQR(buf *bytes.Buffer, code *QRCode) {
+	const quiet = 4
+	buf.WriteString(`<table cellpadding="0" cellspacing="0" border="0" style="margin:8px auto;border-collapse:collapse;background:#ffffff">`)
+	for row := -quiet; row < code.Size+quiet; row++ {
+		buf.WriteString("<tr>")
+		for col := -quiet; col < code.Size+quiet; col++ {
+			color := "#ffffff"
+			if row >= 0 && row < code.Size && col >= 0 && col < code.Size && code.Modules[row][col] {
+				color = "#000000"
+			}
+			buf.WriteString(`<td width="4" height="4" style="width:4px;height:4px;padding:0;background:` + color + `"></td>`)
+		}
+		buf.WriteString("</tr>")
+	}
+	buf.WriteString("</table>")
+}
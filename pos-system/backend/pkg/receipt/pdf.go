@@ -0,0 +1,137 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pointsPerMM = 72 / 25.4
+	pdfMargin   = 8.0 // points on every side
+	pdfLeading  = 1.3 // line height as a multiple of the font size
+)
+
+// renderPDF produces a single-page PDF as wide as the paper roll and as tall
+// as the receipt. Text is set in the standard Courier fonts so the fixed-width
+// layout carries over unchanged and no fonts need embedding.
+func renderPDF(lines []line, paper Paper) []byte {
+	pageWidth := float64(paper) * pointsPerMM
+	columns := paper.Columns()
+	fontSize := (pageWidth - 2*pdfMargin) / (0.6 * float64(columns)) // Courier glyphs are 0.6em wide
+	lineHeight := fontSize * pdfLeading
+
+	height := 2 * pdfMargin
+	for _, l := range lines {
+		height += pdfLineHeight(l, pageWidth, lineHeight)
+	}
+
+	var content bytes.Buffer
+	y := height - pdfMargin
+	for _, l := range lines {
+		h := pdfLineHeight(l, pageWidth, lineHeight)
+		y -= h
+
+		if l.qr != nil {
+			module := pdfModuleSize(l.qr, pageWidth)
+			left := (pageWidth - module*float64(l.qr.Size)) / 2
+			top := y + h - lineHeight/2
+			content.WriteString("0 g\n")
+			for row := 0; row < l.qr.Size; row++ {
+				for col := 0; col < l.qr.Size; col++ {
+					if l.qr.Modules[row][col] {
+						fmt.Fprintf(&content, "%.2f %.2f %.2f %.2f re\n",
+							left+float64(col)*module, top-float64(row+1)*module, module, module)
+					}
+				}
+			}
+			content.WriteString("f\n")
+			continue
+		}
+		if l.text == "" {
+			continue
+		}
+
+		text := l.text
+		if l.style&styleCenter != 0 {
+			text = centerText(text, columns)
+		}
+		font, size, scale := "F1", fontSize, 100
+		if l.style&styleBold != 0 {
+			font = "F2"
+		}
+		if l.style&styleTall != 0 {
+			// Twice the size at half the width keeps the line's columns
+			size, scale = fontSize*2, 50
+		}
+		fmt.Fprintf(&content, "BT /%s %.2f Tf %d Tz %.2f %.2f Td (%s) Tj ET\n",
+			font, size, scale, pdfMargin, y+h*0.25, pdfString(text))
+	}
+
+	return pdfDocument(pageWidth, height, content.Bytes())
+}
+
+func pdfLineHeight(l line, pageWidth, lineHeight float64) float64 {
+	switch {
+	case l.qr != nil:
+		return pdfModuleSize(l.qr, pageWidth)*float64(l.qr.Size) + lineHeight
+	case l.style&styleTall != 0:
+		return 2 * lineHeight
+	default:
+		return lineHeight
+	}
+}
+
+// pdfModuleSize makes a QR code about half the page wide
+func pdfModuleSize(code *QRCode, pageWidth float64) float64 {
+	return (pageWidth - 2*pdfMargin) / 2 / float64(code.Size)
+}
+
+// pdfString escapes text for a PDF string literal. Latin-1 characters are
+// written as octal escapes for WinAnsiEncoding; anything else becomes '?'.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfDocument wraps a page content stream in a minimal PDF file
+func pdfDocument(width, height float64, content []byte) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", width, height),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,459 @@
+package receipt
+
+import "errors"
+
+// ErrQRDataTooLong is returned when data does not fit the largest supported
+// QR code version
+var ErrQRDataTooLong = errors.New("data too long for QR code")
+
+// qrVersion describes the error correction layout of a QR code version at
+// level M
+type qrVersion struct {
+	totalCodewords int
+	blocks         int
+	eccPerBlock    int
+	alignment      []int
+}
+
+// qrVersions lists versions 1 to 10, enough for 213 bytes of data
+var qrVersions = []qrVersion{
+	{26, 1, 10, nil},
+	{44, 1, 16, []int{6, 18}},
+	{70, 1, 26, []int{6, 22}},
+	{100, 2, 18, []int{6, 26}},
+	{134, 2, 24, []int{6, 30}},
+	{172, 4, 16, []int{6, 34}},
+	{196, 4, 18, []int{6, 22, 38}},
+	{242, 4, 22, []int{6, 24, 42}},
+	{292, 5, 22, []int{6, 26, 46}},
+	{346, 5, 26, []int{6, 28, 50}},
+}
+
+func (v qrVersion) dataCodewords() int {
+	return v.totalCodewords - v.blocks*v.eccPerBlock
+}
+
+// QRCode is an encoded QR code. Modules are indexed [row][column] and true
+// means dark.
+type QRCode struct {
+	Size    int
+	Modules [][]bool
+}
+
+// EncodeQR encodes data in byte mode at error correction level M, using the
+// smallest version that fits
+func EncodeQR(data string) (*QRCode, error) {
+	for i, version := range qrVersions {
+		number := i + 1
+		countBits := 8
+		if number >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+len(data)*8 > version.dataCodewords()*8 {
+			continue
+		}
+
+		codewords := qrDataCodewords([]byte(data), countBits, version.dataCodewords())
+		return newQRCode(number, version, qrAddECC(codewords, version)), nil
+	}
+	return nil, ErrQRDataTooLong
+}
+
+// qrDataCodewords builds the data bit stream: mode, length, data,
+// terminator and padding
+func qrDataCodewords(data []byte, countBits, capacity int) []byte {
+	var bits qrBits
+	bits.append(0x4, 4) // byte mode
+	bits.append(len(data), countBits)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacityBits := capacity * 8
+	terminator := capacityBits - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		codewords = append(codewords, b)
+	}
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+type qrBits []bool
+
+func (b *qrBits) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+// qrAddECC splits data into blocks, appends Reed-Solomon error correction
+// to each and interleaves the result. Later blocks are one codeword longer
+// when the data does not divide evenly.
+func qrAddECC(data []byte, version qrVersion) []byte {
+	shortBlocks := version.blocks - version.totalCodewords%version.blocks
+	shortLen := version.totalCodewords / version.blocks
+	divisor := rsDivisor(version.eccPerBlock)
+
+	blocks := make([][]byte, version.blocks)
+	offset := 0
+	for i := range blocks {
+		dataLen := shortLen - version.eccPerBlock
+		if i >= shortBlocks {
+			dataLen++
+		}
+		block := append([]byte(nil), data[offset:offset+dataLen]...)
+		offset += dataLen
+		ecc := rsRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0) // placeholder so blocks line up
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, version.totalCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			// Skip the placeholders of the short blocks
+			if i != shortLen-version.eccPerBlock || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given
+// degree, highest power first with the leading 1 omitted
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// qrBuilder lays out the modules of a code, tracking which belong to
+// function patterns and must not carry data or be masked
+type qrBuilder struct {
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newQRCode(number int, version qrVersion, codewords []byte) *QRCode {
+	size := number*4 + 17
+	b := &qrBuilder{size: size, modules: grid(size), isFunction: grid(size)}
+	b.drawFunctionPatterns(number, version)
+	b.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		b.applyMask(mask)
+		b.drawFormatBits(mask)
+		if penalty := b.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		b.applyMask(mask) // masking is its own inverse
+	}
+	b.applyMask(bestMask)
+	b.drawFormatBits(bestMask)
+
+	return &QRCode{Size: size, Modules: b.modules}
+}
+
+func grid(size int) [][]bool {
+	rows := make([][]bool, size)
+	for i := range rows {
+		rows[i] = make([]bool, size)
+	}
+	return rows
+}
+
+func (b *qrBuilder) setFunction(x, y int, dark bool) {
+	b.modules[y][x] = dark
+	b.isFunction[y][x] = true
+}
+
+func (b *qrBuilder) drawFunctionPatterns(number int, version qrVersion) {
+	for i := 0; i < b.size; i++ {
+		b.setFunction(6, i, i%2 == 0)
+		b.setFunction(i, 6, i%2 == 0)
+	}
+
+	b.drawFinder(3, 3)
+	b.drawFinder(b.size-4, 3)
+	b.drawFinder(3, b.size-4)
+
+	positions := version.alignment
+	last := len(positions) - 1
+	for i := range positions {
+		for j := range positions {
+			// The corners already hold finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			b.drawAlignment(positions[i], positions[j])
+		}
+	}
+
+	b.drawFormatBits(0) // reserves the format areas until the mask is chosen
+	if number >= 7 {
+		b.drawVersion(number)
+	}
+}
+
+func (b *qrBuilder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= b.size || yy < 0 || yy >= b.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			b.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (b *qrBuilder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			b.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// qrFormatBits returns the 15-bit format information for level M and mask
+func qrFormatBits(mask int) int {
+	data := mask // level M is 00
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+func (b *qrBuilder) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	// Around the top left finder
+	for i := 0; i <= 5; i++ {
+		b.setFunction(8, i, bit(i))
+	}
+	b.setFunction(8, 7, bit(6))
+	b.setFunction(8, 8, bit(7))
+	b.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		b.setFunction(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finders
+	for i := 0; i < 8; i++ {
+		b.setFunction(b.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		b.setFunction(8, b.size-15+i, bit(i))
+	}
+	b.setFunction(8, b.size-8, true) // always dark
+}
+
+func (b *qrBuilder) drawVersion(number int) {
+	remainder := number
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	bits := number<<12 | remainder
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, c := b.size-11+i%3, i/3
+		b.setFunction(a, c, dark)
+		b.setFunction(c, a, dark)
+	}
+}
+
+// drawCodewords fills the data area in the standard zigzag, two columns at
+// a time from the bottom right, skipping the vertical timing pattern
+func (b *qrBuilder) drawCodewords(codewords []byte) {
+	i := 0
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < b.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = b.size - 1 - vert // upward column pair
+				}
+				if b.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				b.modules[y][x] = (codewords[i>>3]>>(7-(i&7)))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (b *qrBuilder) applyMask(mask int) {
+	for y := 0; y < b.size; y++ {
+		for x := 0; x < b.size; x++ {
+			if b.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				b.modules[y][x] = !b.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the current modules with the four mask evaluation rules;
+// lower is better
+func (b *qrBuilder) penalty() int {
+	size := b.size
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return b.modules[x][y]
+		}
+		return b.modules[y][x]
+	}
+
+	penalty := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < size; y++ {
+			// Runs of five or more modules of one colour
+			run := 1
+			for x := 1; x <= size; x++ {
+				if x < size && at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// Patterns that look like a finder next to light space
+			for x := 0; x+11 <= size; x++ {
+				for _, pattern := range finderLike {
+					matches := true
+					for k, dark := range pattern {
+						if at(x+k, y, vertical) != dark {
+							matches = false
+							break
+						}
+					}
+					if matches {
+						penalty += 40
+					}
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of one colour
+	for y := 0; y+1 < size; y++ {
+		for x := 0; x+1 < size; x++ {
+			c := b.modules[y][x]
+			if c == b.modules[y][x+1] && c == b.modules[y+1][x] && c == b.modules[y+1][x+1] {
+				penalty += 3
+			}
+		}
+	}
+
+	// Balance of dark and light modules
+	dark := 0
+	for _, row := range b.modules {
+		for _, module := range row {
+			if module {
+				dark++
+			}
+		}
+	}
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	penalty += k * 10
+
+	return penalty
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
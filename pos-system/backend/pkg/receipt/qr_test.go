@@ -0,0 +1,142 @@
+package receipt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// The worked "HELLO WORLD" 1-M example from the QR code specification
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	ecc := rsRemainder(data, rsDivisor(len(expected)))
+	if !bytes.Equal(ecc, expected) {
+		t.Errorf("Expected %v, got %v", expected, ecc)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	if bits := qrFormatBits(0); bits != 0b101010000010010 {
+		t.Errorf("Expected M-0 format bits 101010000010010, got %015b", bits)
+	}
+	if bits := qrFormatBits(5); bits != 0b100000011001110 {
+		t.Errorf("Expected M-5 format bits 100000011001110, got %015b", bits)
+	}
+}
+
+func TestEncodeQRRoundTrip(t *testing.T) {
+	for _, data := range []string{"RCP-20261016-0A1B2C3D", string(bytes.Repeat([]byte("x"), 120))} {
+		code, err := EncodeQR(data)
+		if err != nil {
+			t.Fatalf("Failed to encode %q: %v", data, err)
+		}
+		if decoded := decodeQR(t, code); decoded != data {
+			t.Errorf("Expected %q, decoded %q", data, decoded)
+		}
+	}
+}
+
+func TestEncodeQRTooLong(t *testing.T) {
+	if _, err := EncodeQR(string(make([]byte, 214))); err != ErrQRDataTooLong {
+		t.Errorf("Expected ErrQRDataTooLong, got %v", err)
+	}
+}
+
+// decodeQR reads a code back: format bits, unmasking, codeword order, block
+// de-interleaving and error correction check
+func decodeQR(t *testing.T, code *QRCode) string {
+	t.Helper()
+	number := (code.Size - 17) / 4
+	version := qrVersions[number-1]
+
+	// Format bits around the top left finder, most significant first
+	var format int
+	for _, p := range [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}} {
+		format <<= 1
+		if code.Modules[p[0]][p[1]] {
+			format |= 1
+		}
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("Format bits %015b match no mask at level M", format)
+	}
+
+	// Rebuild the function pattern map and read the data area
+	reference := &qrBuilder{size: code.Size, modules: grid(code.Size), isFunction: grid(code.Size)}
+	reference.drawFunctionPatterns(number, version)
+	reference.modules = code.Modules
+	reference.applyMask(mask)
+	defer reference.applyMask(mask)
+
+	var codewords []byte
+	var current byte
+	count := 0
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < code.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = code.Size - 1 - vert
+				}
+				if reference.isFunction[y][x] {
+					continue
+				}
+				current <<= 1
+				if code.Modules[y][x] {
+					current |= 1
+				}
+				if count++; count%8 == 0 {
+					codewords = append(codewords, current)
+				}
+			}
+		}
+	}
+	codewords = codewords[:version.totalCodewords]
+
+	// De-interleave into blocks and check each block's error correction
+	shortBlocks := version.blocks - version.totalCodewords%version.blocks
+	shortData := version.totalCodewords/version.blocks - version.eccPerBlock
+	blocks := make([][]byte, version.blocks)
+	next := 0
+	for i := 0; i < shortData+1; i++ {
+		for j := range blocks {
+			if i == shortData && j < shortBlocks {
+				continue
+			}
+			blocks[j] = append(blocks[j], codewords[next])
+			next++
+		}
+	}
+	var data []byte
+	for j, block := range blocks {
+		data = append(data, block...)
+		ecc := make([]byte, version.eccPerBlock)
+		for i := range ecc {
+			ecc[i] = codewords[next+i*version.blocks+j]
+		}
+		if !bytes.Equal(rsRemainder(block, rsDivisor(version.eccPerBlock)), ecc) {
+			t.Errorf("Block %d fails its error correction check", j)
+		}
+	}
+
+	// Byte mode header
+	if data[0]>>4 != 0x4 {
+		t.Fatalf("Expected byte mode, got %x", data[0]>>4)
+	}
+	length := int(data[0]&0x0F)<<4 | int(data[1]>>4)
+	decoded := make([]byte, length)
+	for i := range decoded {
+		decoded[i] = data[1+i]<<4 | data[2+i]>>4
+	}
+	return string(decoded)
+}
@@ -0,0 +1,378 @@
+// Package receipt renders sales and refund receipts as ESC/POS byte streams
+// for thermal printers, fixed-width plain text, HTML for email and PDF. All
+// formats share one layout, so a receipt reads the same however it is
+// produced.
+package receipt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is a receipt output format
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatESCPOS Format = "escpos"
+	FormatPDF    Format = "pdf"
+	FormatHTML   Format = "html"
+)
+
+// ErrUnknownFormat is returned for formats other than text, escpos, pdf and
+// html
+var ErrUnknownFormat = errors.New("unknown receipt format")
+
+// IsValid reports whether f is a supported format
+func (f Format) IsValid() bool {
+	switch f {
+	case FormatText, FormatESCPOS, FormatPDF, FormatHTML:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContentType returns the MIME type of receipts in format f
+func (f Format) ContentType() string {
+	switch f {
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Paper is the width of a thermal paper roll in millimetres
+type Paper int
+
+const (
+	Paper58mm Paper = 58
+	Paper80mm Paper = 80
+)
+
+// IsValid reports whether p is a supported paper width
+func (p Paper) IsValid() bool {
+	return p == Paper58mm || p == Paper80mm
+}
+
+// Columns returns the characters per line in a printer's default font
+func (p Paper) Columns() int {
+	if p == Paper58mm {
+		return 32
+	}
+	return 48
+}
+
+// Receipt is everything printed on a receipt. Amounts are shown as they
+// are; a refund receipt lists the refunded items and amounts as positive
+// values under a refund heading.
+type Receipt struct {
+	Company      Company
+	Header       string // free text above the company details
+	Footer       string // free text at the bottom
+	ReceiptID    string
+	Date         string
+	Time         string
+	Cashier      string
+	Customer     string
+	Refund       bool
+	RefundReason string
+	Items        []Item
+	Subtotal     float64
+	Discount     float64
+	Tax          float64
+	Total        float64
+	Payments     []Payment
+	AmountPaid   float64
+	Change       float64
+	Currency     string
+	QRData       string // encoded in a QR code at the bottom when set
+}
+
+// Company is the seller printed at the top of a receipt
+type Company struct {
+	Name    string
+	Address string
+	Phone   string
+	Email   string
+	Website string
+	TaxID   string
+}
+
+// Item is one receipt line. Amount is the quantity at the unit price,
+// before the line discount.
+type Item struct {
+	Name      string
+	SKU       string
+	Quantity  int
+	UnitPrice float64
+	Discount  float64
+	Amount    float64
+}
+
+// Payment is one tender, or for refunds the tender refunded to
+type Payment struct {
+	Method    string
+	Reference string
+	Amount    float64
+}
+
+// Render renders r in format for paper of the given width
+func Render(r *Receipt, format Format, paper Paper) ([]byte, error) {
+	if !paper.IsValid() {
+		return nil, fmt.Errorf("unsupported paper width %dmm", paper)
+	}
+
+	lines, err := layout(r, paper.Columns())
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatText:
+		return renderText(lines, paper.Columns()), nil
+	case FormatESCPOS:
+		return renderESCPOS(lines, paper), nil
+	case FormatPDF:
+		return renderPDF(lines, paper), nil
+	case FormatHTML:
+		return renderHTML(lines, paper.Columns()), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// style flags a line's emphasis
+type style uint8
+
+const (
+	styleBold style = 1 << iota
+	styleTall       // double height, same width
+	styleCenter
+)
+
+// line is one printed line, or a QR code when qr is set
+type line struct {
+	text  string
+	style style
+	qr    *QRCode
+}
+
+// builder collects lines no wider than columns
+type builder struct {
+	columns int
+	lines   []line
+}
+
+func layout(r *Receipt, columns int) ([]line, error) {
+	b := &builder{columns: columns}
+
+	if r.Header != "" {
+		b.center(r.Header, 0)
+		b.blank()
+	}
+	b.center(r.Company.Name, styleBold|styleTall)
+	b.center(r.Company.Address, 0)
+	if r.Company.Phone != "" {
+		b.center("Tel: "+r.Company.Phone, 0)
+	}
+	b.center(r.Company.Email, 0)
+	b.center(r.Company.Website, 0)
+	if r.Company.TaxID != "" {
+		b.center("Tax ID: "+r.Company.TaxID, 0)
+	}
+	b.rule()
+
+	if r.Refund {
+		b.center("REFUND", styleBold|styleTall)
+	}
+	b.wrap("Receipt: "+r.ReceiptID, 0)
+	b.wrap(strings.TrimSpace("Date: "+r.Date+" "+r.Time), 0)
+	if r.Cashier != "" {
+		b.wrap("Cashier: "+r.Cashier, 0)
+	}
+	if r.Customer != "" {
+		b.wrap("Customer: "+r.Customer, 0)
+	}
+	b.rule()
+
+	for _, item := range r.Items {
+		b.wrap(item.Name, 0)
+		b.pair(fmt.Sprintf("  %d x %s", item.Quantity, money(item.UnitPrice)), money(item.Amount), 0)
+		if item.Discount > 0 {
+			b.pair("  Discount", money(-item.Discount), 0)
+		}
+	}
+	b.rule()
+
+	currency := ""
+	if r.Currency != "" {
+		currency = " " + r.Currency
+	}
+	if r.Refund {
+		b.pair("REFUND TOTAL"+currency, money(r.Total), styleBold|styleTall)
+		for _, payment := range r.Payments {
+			b.pair("Refunded to "+tender(payment), money(payment.Amount), 0)
+		}
+		if r.RefundReason != "" {
+			b.blank()
+			b.wrap("Reason: "+r.RefundReason, 0)
+		}
+	} else {
+		b.pair("Subtotal", money(r.Subtotal), 0)
+		if r.Discount > 0 {
+			b.pair("Discount", money(-r.Discount), 0)
+		}
+		if r.Tax > 0 {
+			b.pair("Tax", money(r.Tax), 0)
+		}
+		b.pair("TOTAL"+currency, money(r.Total), styleBold|styleTall)
+		b.rule()
+		for _, payment := range r.Payments {
+			b.pair(tender(payment), money(payment.Amount), 0)
+		}
+		if r.Change > 0 {
+			b.pair("Amount paid", money(r.AmountPaid), 0)
+			b.pair("Change", money(r.Change), 0)
+		}
+	}
+
+	if r.QRData != "" {
+		code, err := EncodeQR(r.QRData)
+		if err != nil {
+			return nil, err
+		}
+		b.blank()
+		b.lines = append(b.lines, line{qr: code, style: styleCenter})
+		b.center(r.QRData, 0)
+	}
+
+	if r.Footer != "" {
+		b.blank()
+		b.center(r.Footer, 0)
+	}
+
+	return b.lines, nil
+}
+
+func (b *builder) blank() {
+	b.lines = append(b.lines, line{})
+}
+
+func (b *builder) rule() {
+	b.lines = append(b.lines, line{text: strings.Repeat("-", b.columns)})
+}
+
+// wrap adds text word-wrapped to the line width. Embedded newlines start new
+// lines.
+func (b *builder) wrap(text string, s style) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, wrapped := range wrapText(strings.TrimSpace(paragraph), b.columns) {
+			b.lines = append(b.lines, line{text: wrapped, style: s})
+		}
+	}
+}
+
+// center adds centred, word-wrapped text; empty text adds nothing
+func (b *builder) center(text string, s style) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	b.wrap(text, s|styleCenter)
+}
+
+// pair adds a label on the left and a value on the right. Labels too long
+// to share a line with the value wrap above it.
+func (b *builder) pair(label, value string, s style) {
+	labelLines := wrapText(label, b.columns)
+	last := labelLines[len(labelLines)-1]
+	for _, text := range labelLines[:len(labelLines)-1] {
+		b.lines = append(b.lines, line{text: text, style: s})
+	}
+
+	gap := b.columns - width(last) - width(value)
+	if gap < 1 {
+		b.lines = append(b.lines, line{text: last, style: s})
+		last, gap = "", b.columns-width(value)
+	}
+	b.lines = append(b.lines, line{text: last + strings.Repeat(" ", gap) + value, style: s})
+}
+
+// wrapText breaks text into lines of at most columns characters, splitting
+// on spaces and hard-splitting words longer than a line
+func wrapText(text string, columns int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for width(word) > columns {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			split := runeOffset(word, columns)
+			lines = append(lines, word[:split])
+			word = word[split:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case width(current)+1+width(word) <= columns:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// centerText pads text on the left to centre it within columns
+func centerText(text string, columns int) string {
+	pad := (columns - width(text)) / 2
+	if pad <= 0 {
+		return text
+	}
+	return strings.Repeat(" ", pad) + text
+}
+
+func width(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// runeOffset returns the byte offset of the n-th rune of text
+func runeOffset(text string, n int) int {
+	for offset := range text {
+		if n == 0 {
+			return offset
+		}
+		n--
+	}
+	return len(text)
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// tender names a payment, showing only the last four characters of its
+// reference since references are often card or account numbers
+func tender(payment Payment) string {
+	reference := payment.Reference
+	if reference == "" {
+		return payment.Method
+	}
+	if n := width(reference); n > 4 {
+		reference = "****" + reference[runeOffset(reference, n-4):]
+	}
+	return payment.Method + " " + reference
+}
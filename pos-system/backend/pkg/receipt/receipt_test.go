@@ -0,0 +1,171 @@
+package receipt
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func sampleReceipt() *Receipt {
+	return &Receipt{
+		Company:   Company{Name: "Cashly Coffee", Address: "12 Market Street, Springfield", Phone: "555-0100"},
+		Header:    "Welcome!",
+		Footer:    "Thank you for shopping with us",
+		ReceiptID: "RCP-20261016-0A1B2C3D",
+		Date:      "2026-10-16",
+		Time:      "09:30",
+		Cashier:   "Alex",
+		Items: []Item{
+			{Name: "Single origin espresso beans, medium roast, 1kg bag", Quantity: 2, UnitPrice: 24.5, Discount: 4, Amount: 49},
+			{Name: "Croissant", Quantity: 1, UnitPrice: 3.25, Amount: 3.25},
+		},
+		Subtotal: 52.25,
+		Discount: 4,
+		Tax:      3.38,
+		Total:    51.63,
+		Payments: []Payment{
+			{Method: "Card", Reference: "4111111111111111", Amount: 40},
+			{Method: "Cash", Amount: 11.63},
+		},
+		AmountPaid: 60,
+		Change:     8.37,
+		QRData:     "RCP-20261016-0A1B2C3D",
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	for _, paper := range []Paper{Paper58mm, Paper80mm} {
+		out, err := Render(sampleReceipt(), FormatText, paper)
+		if err != nil {
+			t.Fatalf("Failed to render: %v", err)
+		}
+		text := string(out)
+
+		for _, l := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+			if n := utf8.RuneCountInString(l); n > paper.Columns() {
+				t.Errorf("%dmm: line is %d wide: %q", paper, n, l)
+			}
+		}
+		for _, expected := range []string{"Cashly Coffee", "Discount", "-4.00", "51.63", "Card ****1111", "Change", "8.37", "RCP-20261016-0A1B2C3D", "Thank you"} {
+			if !strings.Contains(text, expected) {
+				t.Errorf("%dmm: expected %q in receipt:\n%s", paper, expected, text)
+			}
+		}
+		if strings.Contains(text, "4111111111111111") {
+			t.Errorf("%dmm: card reference should be masked", paper)
+		}
+	}
+}
+
+func TestRenderRefundText(t *testing.T) {
+	r := sampleReceipt()
+	r.Refund = true
+	r.RefundReason = "Damaged packaging"
+	r.Items = r.Items[1:]
+	r.Total = 3.46
+	r.Payments = []Payment{{Method: "Cash", Amount: 3.46}}
+
+	out, err := Render(r, FormatText, Paper80mm)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	text := string(out)
+	for _, expected := range []string{"REFUND", "REFUND TOTAL", "Refunded to Cash", "3.46", "Reason: Damaged packaging"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in refund receipt:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "Change") {
+		t.Errorf("Refund receipt should not show change:\n%s", text)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("a verylongwordthatdoesnotfit b", 10)
+	expected := []string{"a", "verylongwo", "rdthatdoes", "notfit b"}
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %q, got %q", expected, lines)
+	}
+}
+
+func TestRenderESCPOS(t *testing.T) {
+	r := sampleReceipt()
+	r.Company.Name = "Café Ünique"
+
+	out, err := Render(r, FormatESCPOS, Paper58mm)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !bytes.HasPrefix(out, escInit) {
+		t.Error("Expected stream to start by initialising the printer")
+	}
+	if !bytes.HasSuffix(out, escFeedAndCut) {
+		t.Error("Expected stream to end with a cut")
+	}
+	if !bytes.Contains(out, []byte("Caf? ?nique")) {
+		t.Error("Expected non-ASCII characters to be replaced")
+	}
+
+	// The raster image header gives bytes per row and rows
+	index := bytes.Index(out, []byte{0x1D, 0x76, 0x30, 0x00})
+	if index < 0 {
+		t.Fatal("Expected a raster QR code")
+	}
+	rowBytes := int(out[index+4]) | int(out[index+5])<<8
+	rows := int(out[index+6]) | int(out[index+7])<<8
+	if rowBytes*8 > printableWidths[Paper58mm] || rows == 0 || len(out) < index+8+rowBytes*rows {
+		t.Errorf("Unexpected raster size %d bytes x %d rows", rowBytes, rows)
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	out, err := Render(sampleReceipt(), FormatPDF, Paper80mm)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("Expected a complete PDF file")
+	}
+	if !bytes.Contains(out, []byte("(Receipt: RCP-20261016-0A1B2C3D) Tj")) {
+		t.Error("Expected the receipt ID to be drawn")
+	}
+
+	// startxref must point at the cross-reference table
+	text := string(out)
+	start := strings.LastIndex(text, "startxref\n") + len("startxref\n")
+	offset, err := strconv.Atoi(text[start : start+strings.Index(text[start:], "\n")])
+	if err != nil || !strings.HasPrefix(text[offset:], "xref\n") {
+		t.Errorf("startxref does not point at the xref table")
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r := sampleReceipt()
+	r.Company.Name = "Fish & <Chips>"
+
+	out, err := Render(r, FormatHTML, Paper80mm)
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	text := string(out)
+	if !strings.Contains(text, "Fish &amp; &lt;Chips&gt;") || strings.Contains(text, "<Chips>") {
+		t.Error("Expected text to be escaped")
+	}
+	if !strings.Contains(text, "<table") || !strings.Contains(text, "51.63") {
+		t.Errorf("Expected the totals and a QR table:\n%s", text)
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if _, err := Render(sampleReceipt(), Format("rtf"), Paper80mm); err != ErrUnknownFormat {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	if s := pdfString(`(a\b) é 日`); s != `\(a\\b\) \351 ?` {
+		t.Errorf("Unexpected escaping: %s", s)
+	}
+}
@@ -0,0 +1,20 @@
+package receipt
+
+import "bytes"
+
+// renderText produces the fixed-width plain text version of a receipt
+func renderText(lines []line, columns int) []byte {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		if l.qr != nil {
+			continue // the receipt ID printed below the code stands in for it
+		}
+		text := l.text
+		if l.style&styleCenter != 0 {
+			text = centerText(text, columns)
+		}
+		buf.WriteString(text)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
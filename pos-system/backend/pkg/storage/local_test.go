@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStore(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	// Test save and read back
+	written, err := store.Save("receipts/a.png", strings.NewReader("receipt data"))
+	if err != nil {
+		t.Fatalf("Failed to save file: %v", err)
+	}
+	if written != int64(len("receipt data")) {
+		t.Errorf("Expected %d bytes written, got %d", len("receipt data"), written)
+	}
+
+	file, err := store.Open("receipts/a.png")
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "receipt data" {
+		t.Errorf("Expected %q, got %q", "receipt data", data)
+	}
+
+	// Test remove, including a second remove of the missing file
+	if err := store.Remove("receipts/a.png"); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := store.Remove("receipts/a.png"); err != nil {
+		t.Errorf("Removing a missing file should succeed, got %v", err)
+	}
+	if _, err := store.Open("receipts/a.png"); err == nil {
+		t.Error("Expected open of removed file to fail")
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"", ".", "..", "../secret", "a/../../secret", "/etc/passwd"} {
+		if _, err := store.Save(key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Save(%q): expected ErrInvalidKey, got %v", key, err)
+		}
+	}
+}
+
+func TestLocalStoreRemoveOlderThan(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalStore(root)
+
+	for _, key := range []string{"exports/old.csv", "exports/new.csv", "receipts/old.png"} {
+		if _, err := store.Save(key, strings.NewReader("x")); err != nil {
+			t.Fatalf("Failed to save %s: %v", key, err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	for _, key := range []string{"exports/old.csv", "receipts/old.png"} {
+		if err := os.Chtimes(filepath.Join(root, key), old, old); err != nil {
+			t.Fatalf("Failed to age %s: %v", key, err)
+		}
+	}
+
+	removed, err := store.RemoveOlderThan("exports", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to remove old files: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 file removed, got %d", removed)
+	}
+	if _, err := store.Open("exports/old.csv"); err == nil {
+		t.Error("Expected old export to be removed")
+	}
+	for _, key := range []string{"exports/new.csv", "receipts/old.png"} {
+		file, err := store.Open(key)
+		if err != nil {
+			t.Errorf("Expected %s to be kept, got %v", key, err)
+			continue
+		}
+		file.Close()
+	}
+
+	// Test missing directory
+	if removed, err := store.RemoveOlderThan("missing", time.Now()); err != nil || removed != 0 {
+		t.Errorf("Expected nothing removed from a missing directory, got %d, %v", removed, err)
+	}
+
+	// Test the root directory leaves subdirectories alone
+	for _, key := range []string{"old.csv", "new.csv"} {
+		if _, err := store.Save(key, strings.NewReader("x")); err != nil {
+			t.Fatalf("Failed to save %s: %v", key, err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(root, "old.csv"), old, old); err != nil {
+		t.Fatalf("Failed to age old.csv: %v", err)
+	}
+	removed, err = store.RemoveOlderThan("", time.Now().Add(-time.Hour))
+	if err != nil || removed != 1 {
+		t.Errorf("Expected 1 file removed from the root, got %d, %v", removed, err)
+	}
+	for _, key := range []string{"new.csv", "receipts/old.png"} {
+		file, err := store.Open(key)
+		if err != nil {
+			t.Errorf("Expected %s to be kept, got %v", key, err)
+			continue
+		}
+		file.Close()
+	}
+}
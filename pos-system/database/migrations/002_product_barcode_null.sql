@@ -0,0 +1,10 @@
+-- Products without a barcode
+-- Migration: 002_product_barcode_null.sql
+
+-- Products without a barcode now store NULL rather than an empty string, and
+-- the API returns "barcode": null for them. Only NULLs may repeat under the
+-- unique constraint, so existing empty barcodes must be converted before a
+-- second product without one can be saved.
+UPDATE products
+SET barcode = NULL
+WHERE barcode IS NOT NULL AND btrim(barcode) = '';